@@ -0,0 +1,84 @@
+package main
+
+import (
+  "strings"
+)
+
+// WithStripANSI, when on, removes ANSI escape sequences, such as the SGR
+// color codes of another tool's output, from each message before it is
+// transformed and stored, so that they stay out of reads and Search.
+// LoadInMemoryLogger applies it to the lines it loads as well. Text other
+// than the sequences is left as it is.
+func WithStripANSI(on bool) Option {
+  return func(o *options) {
+    o.stripANSI = on
+  }
+}
+
+// stripEscapes applies WithStripANSI to mesg.
+func (this *options) stripEscapes(mesg string) string {
+  if !this.stripANSI {
+    return mesg
+  }
+  return stripANSI(mesg)
+}
+
+// stripANSI returns s without its ECMA-48 escape sequences: control
+// sequences such as "\x1b[1;38;5;208m", with any number of parameters;
+// control strings such as the OSC "\x1b]0;title\x07", up to their BEL or
+// ST terminator; and the short escapes such as "\x1b(B". An unterminated
+// sequence at the end is dropped.
+func stripANSI(s string) string {
+  i := strings.IndexByte(s, '\x1b')
+  if i < 0 {
+    return s
+  }
+  var b strings.Builder
+  b.Grow(len(s))
+  for i >= 0 {
+    b.WriteString(s[:i])
+    s = s[i+escapeLen(s[i:]):]
+    i = strings.IndexByte(s, '\x1b')
+  }
+  b.WriteString(s)
+  return b.String()
+}
+
+// escapeLen returns the length of the escape sequence s starts with.
+func escapeLen(s string) int {
+  if len(s) < 2 {
+    return len(s)
+  }
+  i := 2
+  switch s[1] {
+  case '[':
+    // Control sequence: parameter bytes, intermediate bytes, a final byte.
+    for i < len(s) && s[i] >= 0x20 && s[i] <= 0x3f {
+      i++
+    }
+    if i < len(s) && s[i] >= 0x40 && s[i] <= 0x7e {
+      i++
+    }
+    return i
+  case ']', 'P', 'X', '^', '_':
+    // Control string: up to BEL or ST, which is ESC \.
+    for i < len(s) {
+      switch {
+      case s[i] == '\a':
+        return i + 1
+      case s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\':
+        return i + 2
+      }
+      i++
+    }
+    return i
+  }
+  i = 1
+  for i < len(s) && s[i] >= 0x20 && s[i] <= 0x2f {
+    i++
+  }
+  if i < len(s) && s[i] >= 0x30 && s[i] <= 0x7e {
+    i++
+  }
+  return i
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+  "os"
+  "path/filepath"
+  "reflect"
+  "strings"
+  "testing"
+)
+
+func TestStripANSI(t *testing.T) {
+  tests := []struct {
+    in, want string
+  }{
+    {"plain text", "plain text"},
+    {"\x1b[31mred\x1b[0m", "red"},
+    {"\x1b[1;38;5;208mbold orange\x1b[m done", "bold orange done"},
+    {"\x1b[38;2;255;128;0mtruecolor\x1b[39;49m", "truecolor"},
+    {"\x1b]0;title\x07after OSC", "after OSC"},
+    {"\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\", "link"},
+    {"\x1b(Bcharset", "charset"},
+    {"[not an escape] 100% 5;3m", "[not an escape] 100% 5;3m"},
+    {"cut \x1b[1;3", "cut "},
+  }
+  for _, test := range(tests) {
+    if got := stripANSI(test.in); got != test.want {
+      t.Errorf("stripANSI(%q) = %q, want %q", test.in, got, test.want)
+    }
+  }
+}
+
+func TestStripANSIOnIngest(t *testing.T) {
+  colored := "\x1b[1;32mINFO\x1b[0m server \x1b[4mstarted\x1b[24m on :8080"
+  plain := "INFO server started on :8080"
+  for name, l := range(bothLoggers(t, WithStripANSI(true))) {
+    if _, err := LoadFrom(l, strings.NewReader(colored+"\n")); err != nil {
+      t.Fatal(err)
+    }
+    if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{plain}) {
+      t.Errorf("%s: Messages() = %q, want %q", name, got, plain)
+    }
+    if found, _ := l.Search("server started", false); len(found) != 1 {
+      t.Errorf("%s: Search for the plain text found %q", name, found)
+    }
+  }
+  l := NewInMemoryLogger()
+  l.Log(colored)
+  AssertLogged(t, l, "\x1b[1;32m")
+}
+
+func TestStripANSIOnLoad(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "saved.log")
+  if err := os.WriteFile(path, []byte("\x1b[31merror\x1b[0m\nok\n"), 0644); err != nil {
+    t.Fatal(err)
+  }
+  l, err := LoadInMemoryLogger(path, WithStripANSI(true))
+  if err != nil {
+    t.Fatal(err)
+  }
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"error", "ok"}) {
+    t.Errorf("Messages() = %q, want the escapes stripped", got)
+  }
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "sync"
+)
+
+// QueuePolicy is the OverflowPolicy of an AsyncLogger.
+//
+// Deprecated: use OverflowPolicy.
+type QueuePolicy = OverflowPolicy
+
+const (
+  QueueBlock = Block // Deprecated: use Block.
+  QueueDrop = DropNewest // Deprecated: use DropNewest.
+)
+
+const defaultQueueSize = 1024
+
+// WithQueueSize sets the number of messages an AsyncLogger can hold.
+func WithQueueSize(n int) Option {
+  return func(o *options) {
+    o.queueSize = n
+  }
+}
+
+// WithQueuePolicy sets what an AsyncLogger does when its queue is full.
+//
+// Deprecated: use WithOverflow.
+func WithQueuePolicy(policy QueuePolicy) Option {
+  return WithOverflow(policy)
+}
+
+// WithWorkers makes an AsyncLogger pass messages on from n goroutines at
+// once, for a wrapped logger that is slow but safe for concurrent use.
+// With more than one worker, messages may reach it out of order; the
+// default of one keeps them in order.
+func WithWorkers(n int) Option {
+  return func(o *options) {
+    o.workers = n
+  }
+}
+
+// AsyncLogger takes writes off the caller's path: Log queues the message
+// and background goroutines pass it on to the wrapped logger. Reads go
+// to the wrapped logger and do not wait for queued messages.
+type AsyncLogger struct {
+  inner LoggerInterface
+  policy OverflowPolicy
+  queue chan entry
+  workers sync.WaitGroup
+
+  mu sync.RWMutex // held for reading while sending, so Close can't race it
+  closed bool
+
+  pendMu sync.Mutex
+  idle *sync.Cond // signaled when pending drops to zero
+  pending int // messages queued but not yet written
+
+  errMu sync.Mutex
+  err error // first error returned by the wrapped logger
+  failures writeFailures
+}
+
+var _ LoggerInterface = (*AsyncLogger)(nil)
+
+func NewAsyncLogger(inner LoggerInterface, opts ...Option) *AsyncLogger {
+  o := newOptions(opts)
+  size := o.queueSize
+  if size <= 0 {
+    size = defaultQueueSize
+  }
+  this := &AsyncLogger{
+    inner: inner,
+    policy: o.overflowPolicy(Block),
+    queue: make(chan entry, size),
+  }
+  this.idle = sync.NewCond(&this.pendMu)
+  workers := o.workers
+  if workers < 1 {
+    workers = 1
+  }
+  this.workers.Add(workers)
+  for i := 0; i < workers; i++ {
+    go this.drain()
+  }
+  return this
+}
+
+func (this *AsyncLogger) drain() {
+  defer this.workers.Done()
+  for e := range(this.queue) {
+    if e.ctx != nil {
+      e.ctx = context.WithoutCancel(e.ctx) // the caller only waited for the enqueue
+    }
+    if err := logEntry(this.inner, e); err != nil {
+      this.failures.add(err)
+      this.errMu.Lock()
+      if this.err == nil {
+        this.err = err
+      }
+      this.errMu.Unlock()
+    }
+    this.settle()
+  }
+}
+
+// settle marks one pending message as done with.
+func (this *AsyncLogger) settle() {
+  this.pendMu.Lock()
+  this.pending--
+  if this.pending == 0 {
+    this.idle.Broadcast()
+  }
+  this.pendMu.Unlock()
+}
+
+func (this *AsyncLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *AsyncLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *AsyncLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *AsyncLogger) logEntry(e entry) error {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  if this.closed {
+    return ErrClosed
+  }
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  this.pendMu.Lock()
+  this.pending++
+  this.pendMu.Unlock()
+  err := this.enqueue(e)
+  if err != nil {
+    this.settle()
+  }
+  return err
+}
+
+func (this *AsyncLogger) enqueue(e entry) error {
+  switch this.policy {
+  case DropNewest:
+    select {
+    case this.queue <- e:
+      return nil
+    default:
+      return ErrQueueFull
+    }
+  case DropOldest:
+    for {
+      select {
+      case this.queue <- e:
+        return nil
+      default:
+      }
+      // The workers may empty the queue first, so the receive must not wait.
+      select {
+      case <-this.queue:
+        this.settle()
+      default:
+      }
+    }
+  }
+  if e.ctx == nil {
+    this.queue <- e
+    return nil
+  }
+  select {
+  case this.queue <- e:
+    return nil
+  case <-e.ctx.Done():
+    return e.ctx.Err()
+  }
+}
+
+// Flush waits until the wrapped logger has been given every message queued
+// so far, and then flushes it if it is Flushable. Under a steady stream of
+// new messages it may wait for those too.
+func (this *AsyncLogger) Flush() error {
+  this.pendMu.Lock()
+  for this.pending > 0 {
+    this.idle.Wait()
+  }
+  this.pendMu.Unlock()
+  if f, ok := this.inner.(Flushable); ok {
+    return f.Flush()
+  }
+  return nil
+}
+
+// LastError returns the most recent error the wrapped logger returned for
+// a queued message, or nil if none has failed since ResetErrors.
+func (this *AsyncLogger) LastError() error {
+  return this.failures.lastError()
+}
+
+// FailedWrites returns how many queued messages the wrapped logger has
+// failed to write since ResetErrors.
+func (this *AsyncLogger) FailedWrites() uint64 {
+  return this.failures.n.Load()
+}
+
+// ResetErrors clears LastError and FailedWrites. Close still reports the
+// first error.
+func (this *AsyncLogger) ResetErrors() {
+  this.failures.reset()
+}
+
+// QueueLen returns the number of messages waiting to be written.
+func (this *AsyncLogger) QueueLen() int {
+  return len(this.queue)
+}
+
+func (this *AsyncLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *AsyncLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *AsyncLogger) Messages() ([]string, error) {
+  return this.inner.Messages()
+}
+
+func (this *AsyncLogger) Count() (int, error) {
+  return this.inner.Count()
+}
+
+func (this *AsyncLogger) Tail(n int) ([]string, error) {
+  return this.inner.Tail(n)
+}
+
+func (this *AsyncLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.inner.Search(pattern, useRegexp)
+}
+
+func (this *AsyncLogger) ForEach(fn func(line string) error) error {
+  return this.inner.ForEach(fn)
+}
+
+func (this *AsyncLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.inner.MessagesPage(offset, limit)
+}
+
+func (this *AsyncLogger) Clear() error {
+  return this.inner.Clear()
+}
+
+// Sync syncs the wrapped logger. It does not wait for queued messages.
+func (this *AsyncLogger) Sync() error {
+  return this.inner.Sync()
+}
+
+// Close writes out everything still queued, stops the background goroutines
+// and closes the wrapped logger. It reports the first error the wrapped
+// logger returned while draining.
+func (this *AsyncLogger) Close() error {
+  this.mu.Lock()
+  if this.closed {
+    this.mu.Unlock()
+    return nil
+  }
+  this.closed = true
+  close(this.queue)
+  this.mu.Unlock()
+
+  this.workers.Wait()
+  this.errMu.Lock()
+  err := this.err
+  this.errMu.Unlock()
+  return errors.Join(err, this.inner.Close())
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "reflect"
+  "sort"
+  "sync"
+  "testing"
+  "time"
+)
+
+func TestAsyncWorkersDeliverAll(t *testing.T) {
+  inner := NewInMemoryLogger()
+  l := NewAsyncLogger(inner, WithWorkers(4))
+  var want []string
+  for i := 0; i < 1000; i++ {
+    mesg := fmt.Sprintf("message %04d", i)
+    want = append(want, mesg)
+    if err := l.Log(mesg); err != nil {
+      t.Fatal(err)
+    }
+  }
+  if err := l.Close(); err != nil {
+    t.Fatal(err)
+  }
+  got, _ := inner.Messages()
+  sort.Strings(got)
+  if !reflect.DeepEqual(got, want) {
+    t.Errorf("got %d messages, want all %d", len(got), len(want))
+  }
+}
+
+func TestAsyncSingleWorkerKeepsOrder(t *testing.T) {
+  inner := NewInMemoryLogger()
+  l := NewAsyncLogger(inner)
+  var want []string
+  for i := 0; i < 200; i++ {
+    mesg := fmt.Sprintf("message %d", i)
+    want = append(want, mesg)
+    l.Log(mesg)
+  }
+  l.Close()
+  if got, _ := inner.Messages(); !reflect.DeepEqual(got, want) {
+    t.Error("messages arrived out of order with one worker")
+  }
+}
+
+// slowLogger is an InMemoryLogger whose Log takes a while, recording how
+// many calls overlapped at most.
+type slowLogger struct {
+  *InMemoryLogger
+  mu sync.Mutex
+  active, peak int
+}
+
+func (this *slowLogger) Log(mesg string) error {
+  this.mu.Lock()
+  this.active++
+  this.peak = max(this.peak, this.active)
+  this.mu.Unlock()
+  time.Sleep(5 * time.Millisecond)
+  this.mu.Lock()
+  this.active--
+  this.mu.Unlock()
+  return this.InMemoryLogger.Log(mesg)
+}
+
+func (this *slowLogger) logEntry(e entry) error {
+  return this.Log(e.mesg)
+}
+
+func TestAsyncWorkersRunConcurrently(t *testing.T) {
+  inner := &slowLogger{InMemoryLogger: NewInMemoryLogger()}
+  l := NewAsyncLogger(inner, WithWorkers(4))
+  for i := 0; i < 40; i++ {
+    l.Log("x")
+  }
+  l.Close()
+  AssertLoggedCount(t, inner, 40)
+  if inner.peak < 2 || inner.peak > 4 {
+    t.Errorf("at most %d writes overlapped, want between 2 and 4", inner.peak)
+  }
+}
+
+func TestAsyncKeepsContextForExtractor(t *testing.T) {
+  inner := NewInMemoryLogger(WithContextExtractor(requestFields))
+  l := NewAsyncLogger(inner)
+  ctx, cancel := context.WithCancel(withRequest("r-42"))
+  if err := l.LogCtx(ctx, "handled"); err != nil {
+    t.Fatal(err)
+  }
+  cancel() // after the enqueue, which is all the caller waited for
+  if err := l.Close(); err != nil {
+    t.Fatal(err)
+  }
+  want := []string{"handled request_id=r-42"}
+  if got, _ := inner.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages() = %q, want %q", got, want)
+  }
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+  "errors"
+)
+
+// Batcher is implemented by the loggers that can log several messages as
+// one unit, so that no other message lands between them.
+type Batcher interface {
+  LogBatch(msgs []string) error
+}
+
+var (
+  _ Batcher = (*InMemoryLogger)(nil)
+  _ Batcher = (*LocalLogger)(nil)
+)
+
+// LogBatch logs msgs at LevelInfo through li's LogBatch if it is a Batcher,
+// keeping them together. Otherwise it logs them one at a time, stopping at
+// the first error, and other messages may land in between.
+func LogBatch(li LoggerInterface, msgs []string) error {
+  if b, ok := li.(Batcher); ok {
+    return b.LogBatch(msgs)
+  }
+  for _, mesg := range(msgs) {
+    if err := li.Log(mesg); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+// LogBatch stores msgs at LevelInfo under a single lock, so that they
+// appear together in Messages, as for a multi-line stack trace. Under
+// DropNewest, a batch that does not fit is refused whole, as is one with
+// a message UTF8Reject refuses.
+func (this *InMemoryLogger) LogBatch(msgs []string) error {
+  entries, err := this.opts.batchEntries(msgs)
+  if err != nil {
+    return err
+  }
+  this.mu.Lock()
+  if this.closed {
+    this.mu.Unlock()
+    return ErrClosed
+  }
+  if LevelInfo < this.minLevel {
+    this.mu.Unlock()
+    return nil
+  }
+  now := this.opts.clock()
+  lines := make([]string, len(entries))
+  size := 0
+  for i, e := range(entries) {
+    lines[i] = this.opts.renderAt(e, now)
+    size += len(lines[i])
+  }
+  if this.refuses(len(lines), size) {
+    this.mu.Unlock()
+    return this.opts.handle(ErrQueueFull)
+  }
+  for _, line := range(lines) {
+    if !this.opts.discardHistory {
+      this.store(line, now)
+    }
+    this.counts.add(LevelInfo)
+  }
+  this.countRate(now, len(lines))
+  this.mu.Unlock()
+  for _, line := range(lines) {
+    this.opts.notify(LevelInfo, line)
+  }
+  return nil
+}
+
+// LogBatch writes msgs at LevelInfo to the file in a single write, so that
+// they stay together even with other processes appending to it.
+func (this *LocalLogger) LogBatch(msgs []string) error {
+  admitted, err := this.admits(LevelInfo)
+  if err != nil {
+    return err
+  }
+  if !admitted || len(msgs) == 0 {
+    return nil
+  }
+  entries, err := this.opts.batchEntries(msgs)
+  if err != nil {
+    return err
+  }
+  lines := make([]string, len(entries))
+  for i, e := range(entries) {
+    lines[i] = this.opts.render(e)
+  }
+  if err := this.writeSync(this.opts.syncsAt(LevelInfo), lines...); errors.Is(err, ErrClosed) {
+    return err
+  } else if err != nil {
+    return this.opts.handle(writeFailed(err))
+  }
+  for _, line := range(lines) {
+    this.opts.notify(LevelInfo, line)
+  }
+  return nil
+}
+
+// batchEntries makes the entries for a LogBatch, checked WithValidateUTF8
+// and under the MultilinePolicy.
+func (this *options) batchEntries(msgs []string) ([]entry, error) {
+  entries := make([]entry, 0, len(msgs))
+  for _, mesg := range(msgs) {
+    e := newEntry(nil, LevelInfo, mesg)
+    if err := this.validateUTF8(&e); err != nil {
+      return nil, err
+    }
+    split, err := this.splitLines(e)
+    if err != nil {
+      return nil, err
+    }
+    entries = append(entries, split...)
+  }
+  return entries, nil
+}
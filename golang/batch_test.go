@@ -0,0 +1,56 @@
+package main
+
+import (
+  "fmt"
+  "strings"
+  "sync"
+  "testing"
+)
+
+// TestLogBatchContiguous has goroutines log multi-line batches while
+// others log single lines; every batch must come out unbroken.
+func TestLogBatchContiguous(t *testing.T) {
+  for name, l := range(bothLoggers(t)) {
+    var wg sync.WaitGroup
+    for g := 0; g < 4; g++ {
+      wg.Add(2)
+      go func() {
+        defer wg.Done()
+        for i := 0; i < 50; i++ {
+          batch := make([]string, 5)
+          for j := range(batch) {
+            batch[j] = fmt.Sprintf("batch %d.%d line %d", g, i, j)
+          }
+          if err := LogBatch(l, batch); err != nil {
+            t.Error(err)
+            return
+          }
+        }
+      }()
+      go func() {
+        defer wg.Done()
+        for i := 0; i < 100; i++ {
+          l.Log("single")
+        }
+      }()
+    }
+    wg.Wait()
+    messages, _ := l.Messages()
+    batches := 0
+    for i := 0; i < len(messages); i++ {
+      if !strings.HasSuffix(messages[i], " line 0") {
+        continue
+      }
+      batches++
+      id := strings.TrimSuffix(messages[i], " line 0")
+      for j := 1; j < 5; j++ {
+        if want := fmt.Sprintf("%s line %d", id, j); i+j >= len(messages) || messages[i+j] != want {
+          t.Fatalf("%s: %s broken up: line %d is not %q", name, id, i+j, want)
+        }
+      }
+    }
+    if batches != 4*50 || len(messages) != 4*50*5+4*100 {
+      t.Errorf("%s: %d batches in %d messages", name, batches, len(messages))
+    }
+  }
+}
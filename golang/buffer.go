@@ -0,0 +1,79 @@
+package main
+
+import (
+  "time"
+)
+
+const defaultFlushInterval = time.Second
+
+// WithBufferSize makes a LocalLogger collect records in a buffer of n bytes
+// and write them out together, instead of with a syscall per record. The
+// buffer is flushed when full, every flush interval, on reads, on Sync and
+// on Close; records still buffered are lost if the process dies.
+func WithBufferSize(n int) Option {
+  return func(o *options) {
+    o.bufferSize = n
+  }
+}
+
+// WithFlushInterval sets how often a buffered LocalLogger flushes in the
+// background. The default is a second.
+func WithFlushInterval(d time.Duration) Option {
+  return func(o *options) {
+    o.flushInterval = d
+  }
+}
+
+// WithFlushOnLevel makes a LocalLogger flush and sync the file before a
+// Log at min or above returns, as WithSyncOnWrite does for every Log, so
+// that an error reaches the disk even if the process is about to die.
+// Lower levels stay in the buffer until it is flushed as usual.
+func WithFlushOnLevel(min Level) Option {
+  return func(o *options) {
+    o.flushOnLevel = min
+    o.flushOnLevelSet = true
+  }
+}
+
+// syncsAt reports whether a message at level is synced as soon as it is
+// written, WithFlushOnLevel.
+func (this *options) syncsAt(level Level) bool {
+  return this.flushOnLevelSet && level >= this.flushOnLevel
+}
+
+// startFlusher flushes the buffer every flush interval until Close.
+func (this *LocalLogger) startFlusher() {
+  interval := this.opts.flushInterval
+  if interval <= 0 {
+    interval = defaultFlushInterval
+  }
+  stop, stopped := make(chan struct{}), make(chan struct{})
+  this.stop, this.stopped = stop, stopped
+  go func() {
+    defer close(stopped)
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+      select {
+      case <-ticker.C:
+        this.opts.handle(writeFailed(this.flush()))
+      case <-stop:
+        return
+      }
+    }
+  }()
+}
+
+// stopFlusher ends the goroutine startFlusher began, if any. It must not
+// hold mu while it waits, as the goroutine may be flushing.
+func (this *LocalLogger) stopFlusher() {
+  this.mu.Lock()
+  stop, stopped := this.stop, this.stopped
+  this.stop = nil
+  this.mu.Unlock()
+  if stop == nil {
+    return
+  }
+  close(stop)
+  <-stopped
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+  "os"
+  "path/filepath"
+  "reflect"
+  "testing"
+  "time"
+)
+
+func benchmarkLocalLog(b *testing.B, opts ...Option) {
+  l, err := NewLocalLogger(filepath.Join(b.TempDir(), "bench.log"), true, opts...)
+  if err != nil {
+    b.Fatal(err)
+  }
+  defer l.Close()
+  b.ReportAllocs()
+  b.ResetTimer()
+  for i := 0; i < b.N; i++ {
+    l.Log("GET /index.html 200 1532 bytes in 3ms")
+  }
+  b.StopTimer()
+}
+
+func BenchmarkLocalLogUnbuffered(b *testing.B) {
+  benchmarkLocalLog(b)
+}
+
+func BenchmarkLocalLogBuffered(b *testing.B) {
+  benchmarkLocalLog(b, WithBufferSize(64<<10))
+}
+
+func TestBufferedMessagesSeesPendingLines(t *testing.T) {
+  l := newTestLocal(t, WithBufferSize(64<<10), WithFlushInterval(time.Hour))
+  l.Log("one")
+  l.Log("two")
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"one", "two"}) {
+    t.Errorf("Messages = %q, want the buffered lines", got)
+  }
+}
+
+func BenchmarkLocalLogSyncOnWrite(b *testing.B) {
+  benchmarkLocalLog(b, WithSyncOnWrite(true))
+}
+
+func BenchmarkLocalLogBufferedSyncOnWrite(b *testing.B) {
+  benchmarkLocalLog(b, WithBufferSize(64<<10), WithSyncOnWrite(true))
+}
+
+// TestSyncOnWriteSurvivesKilledHandle drops the file handle without the
+// flush Close would do, as a crash would, and reads what reached the file.
+func TestSyncOnWriteSurvivesKilledHandle(t *testing.T) {
+  for _, synced := range([]bool{true, false}) {
+    filename := filepath.Join(t.TempDir(), "audit.log")
+    l, err := NewLocalLogger(filename, true, WithBufferSize(64<<10), WithFlushInterval(time.Hour), WithSyncOnWrite(synced))
+    if err != nil {
+      t.Fatal(err)
+    }
+    l.Log("audited")
+    l.file.Close()
+    data, err := os.ReadFile(filename)
+    if err != nil {
+      t.Fatal(err)
+    }
+    if got := string(data) == "audited\n"; got != synced {
+      t.Errorf("WithSyncOnWrite(%v): file after the handle died = %q", synced, data)
+    }
+    l.Close()
+  }
+}
+
+// syncingFS is a MemFS that counts the Syncs of its files.
+type syncingFS struct {
+  *MemFS
+  syncs int
+}
+
+func (this *syncingFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+  file, err := this.MemFS.OpenFile(name, flag, perm)
+  if err != nil {
+    return nil, err
+  }
+  return &syncingFile{File: file, fs: this}, nil
+}
+
+type syncingFile struct {
+  File
+  fs *syncingFS
+}
+
+func (this *syncingFile) Sync() error {
+  this.fs.syncs++
+  return this.File.Sync()
+}
+
+func TestFlushOnLevel(t *testing.T) {
+  fsys := &syncingFS{MemFS: NewMemFS()}
+  l, err := NewLocalLogger("app.log", true, WithFS(fsys), WithBufferSize(4096),
+    WithFlushInterval(time.Hour), WithFlushOnLevel(LevelError))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Logf(LevelInfo, "routine")
+  l.Logf(LevelWarn, "odd")
+  if data, _ := fsys.ReadFile("app.log"); len(data) != 0 || fsys.syncs != 0 {
+    t.Fatalf("after Info and Warn the file holds %q with %d syncs, want both buffered", data, fsys.syncs)
+  }
+  l.Logf(LevelError, "failed")
+  if data, _ := fsys.ReadFile("app.log"); string(data) != "routine\nodd\nfailed\n" || fsys.syncs != 1 {
+    t.Errorf("after Error the file holds %q with %d syncs, want everything synced once", data, fsys.syncs)
+  }
+  l.Logf(LevelDebug, "after")
+  if data, _ := fsys.ReadFile("app.log"); string(data) != "routine\nodd\nfailed\n" {
+    t.Errorf("a Debug after the Error reached the file: %q", data)
+  }
+}
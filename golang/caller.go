@@ -0,0 +1,51 @@
+package main
+
+import (
+  "context"
+  "path/filepath"
+  "runtime"
+  "strconv"
+  "sync/atomic"
+)
+
+// callerDepth is how many frames above the Log call an entry remembers,
+// which bounds the skip WithCaller accepts.
+const callerDepth = 4
+
+// callerWanted is set once any logger is built WithCaller, so that until
+// then no Log call pays for capturing its caller.
+var callerWanted atomic.Bool
+
+// WithCaller starts each message with the "file.go:NN" it was logged from.
+// A skip of 0 names the caller of Log or Logf; a larger skip names a caller
+// further up, for messages logged through helper functions, up to
+// callerDepth-1. The caller is captured where the message enters the first
+// logger, so wrappers such as MultiLogger and FilterLogger do not count.
+func WithCaller(skip int) Option {
+  return func(o *options) {
+    callerWanted.Store(true)
+    o.caller = true
+    o.callerSkip = skip
+  }
+}
+
+// newEntry starts an entry at a Log, Logf or LogCtx method, or a function
+// like them, capturing the caller of that method if need be.
+func newEntry(ctx context.Context, level Level, mesg string) entry {
+  e := entry{ctx: ctx, level: level, mesg: mesg}
+  if callerWanted.Load() {
+    // Skip runtime.Callers, newEntry and the method that called it.
+    runtime.Callers(3, e.callers[:])
+  }
+  return e
+}
+
+// callerAt returns "file.go:NN" for the frame skip above the Log call, or
+// "???:0" if the entry does not go back that far.
+func (this entry) callerAt(skip int) string {
+  if skip < 0 || skip >= callerDepth || this.callers[skip] == 0 {
+    return "???:0"
+  }
+  frame, _ := runtime.CallersFrames(this.callers[skip:skip+1]).Next()
+  return filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+}
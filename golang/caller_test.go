@@ -0,0 +1,48 @@
+package main
+
+import (
+  "fmt"
+  "runtime"
+  "strings"
+  "testing"
+)
+
+// here returns "caller_test.go:NN" for the line after the one calling it.
+func here() string {
+  _, _, line, _ := runtime.Caller(1)
+  return fmt.Sprintf("caller_test.go:%d", line+1)
+}
+
+// logVia is a helper whose caller WithCaller(1) should name.
+func logVia(l LoggerInterface, mesg string) {
+  l.Log(mesg)
+}
+
+func TestWithCallerNamesCallSite(t *testing.T) {
+  l := NewInMemoryLogger(WithCaller(0))
+  site := here()
+  l.Log("direct")
+  logfSite := here()
+  l.Logf(LevelWarn, "formatted %d", 1)
+  AssertLogged(t, l, site+" direct")
+  AssertLogged(t, l, logfSite+" formatted 1")
+}
+
+func TestWithCallerThroughWrappers(t *testing.T) {
+  inner := NewInMemoryLogger(WithCaller(0))
+  l := NewFilterLogger(NewMultiLogger(inner), func(mesg string) bool {
+    return true
+  })
+  site := here()
+  l.Log("wrapped")
+  if got, _ := inner.Messages(); len(got) != 1 || !strings.HasPrefix(got[0], site+" ") {
+    t.Errorf("Messages = %q, want the call site %s", got, site)
+  }
+}
+
+func TestWithCallerSkip(t *testing.T) {
+  l := NewInMemoryLogger(WithCaller(1))
+  site := here()
+  logVia(l, "helped")
+  AssertLogged(t, l, site+" helped")
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+  "errors"
+  "io"
+  "io/fs"
+  "sync"
+  "time"
+)
+
+// WithCheckpoint makes an InMemoryLogger write its messages to path every
+// interval, and once more on Close, so that a crash loses at most an
+// interval's worth; RestoreCheckpoint reads them back on startup. The
+// file is replaced atomically, by renaming a new one over it, and holds
+// the messages as MarshalJSON encodes them. The interval is told on the
+// logger's clock when messages are logged, and on a ticker in between; an
+// interval <= 0 checkpoints on Close only.
+func WithCheckpoint(path string, interval time.Duration) Option {
+  return func(o *options) {
+    o.checkpointPath = path
+    o.checkpointInterval = interval
+  }
+}
+
+// checkpointer is the state behind WithCheckpoint. The fields up to stop
+// are guarded by the logger's mu.
+type checkpointer struct {
+  last time.Time // by the logger's clock
+  dirty bool // messages changed since the last checkpoint
+  closed bool
+  kick chan struct{} // asks the goroutine for a checkpoint now
+  stop, stopped chan struct{} // end and await the goroutine, if it runs
+
+  writeMu sync.Mutex // one checkpoint at a time
+}
+
+// checkpointDue notes that the messages changed at now, starting the
+// checkpoint goroutine on the first change and waking it once an interval
+// has passed since the last checkpoint. The caller must hold the write
+// lock.
+func (this *InMemoryLogger) checkpointDue(now time.Time) {
+  if this.opts.checkpointPath == "" {
+    return
+  }
+  c := this.checkpoint
+  if c == nil {
+    c = &checkpointer{last: now, kick: make(chan struct{}, 1)}
+    this.checkpoint = c
+    if this.opts.checkpointInterval > 0 {
+      c.stop, c.stopped = make(chan struct{}), make(chan struct{})
+      go this.checkpoints(c)
+    }
+  }
+  c.dirty = true
+  if c.closed || c.stop == nil || now.Sub(c.last) < this.opts.checkpointInterval {
+    return
+  }
+  c.last = now
+  select {
+  case c.kick <- struct{}{}:
+  default:
+  }
+}
+
+// checkpoints writes a checkpoint when asked, and on each tick of the
+// interval if the messages have changed, until Close.
+func (this *InMemoryLogger) checkpoints(c *checkpointer) {
+  defer close(c.stopped)
+  ticker := time.NewTicker(this.opts.checkpointInterval)
+  defer ticker.Stop()
+  for {
+    select {
+    case <-c.kick:
+      this.opts.handle(writeFailed(this.writeCheckpoint(c)))
+    case <-ticker.C:
+      this.mu.RLock()
+      dirty := c.dirty
+      this.mu.RUnlock()
+      if dirty {
+        this.opts.handle(writeFailed(this.writeCheckpoint(c)))
+      }
+    case <-c.stop:
+      return
+    }
+  }
+}
+
+// writeCheckpoint writes the messages as they stand to a new file beside
+// the checkpoint and renames it over the old one.
+func (this *InMemoryLogger) writeCheckpoint(c *checkpointer) error {
+  c.writeMu.Lock()
+  defer c.writeMu.Unlock()
+  this.mu.Lock()
+  messages := make([]string, len(this.messages))
+  copy(messages, this.messages)
+  c.dirty = false
+  c.last = this.opts.clock()
+  this.mu.Unlock()
+
+  data, err := marshalMessages(messages)
+  if err != nil {
+    return err
+  }
+  fsys := this.opts.fileSystem()
+  path := this.opts.checkpointPath
+  file, name, err := createTemp(fsys, path+".tmp")
+  if err != nil {
+    return err
+  }
+  _, err = file.Write(data)
+  if err == nil {
+    err = file.Sync()
+  }
+  if closeErr := file.Close(); err == nil {
+    err = closeErr
+  }
+  if err == nil {
+    err = fsys.Rename(name, path)
+  }
+  if err != nil {
+    fsys.Remove(name)
+  }
+  return err
+}
+
+// closeCheckpoint stops the checkpoint goroutine and writes the last
+// checkpoint. Only the first call does anything.
+func (this *InMemoryLogger) closeCheckpoint() error {
+  if this.opts.checkpointPath == "" {
+    return nil
+  }
+  this.mu.Lock()
+  c := this.checkpoint
+  if c == nil {
+    c = &checkpointer{}
+    this.checkpoint = c
+  }
+  if c.closed {
+    this.mu.Unlock()
+    return nil
+  }
+  c.closed = true
+  stop := c.stop
+  this.mu.Unlock()
+  if stop != nil {
+    close(stop)
+    <-c.stopped
+  }
+  return this.opts.handle(writeFailed(this.writeCheckpoint(c)))
+}
+
+// RestoreCheckpoint replaces the messages with those in the WithCheckpoint
+// file, as UnmarshalJSON does, for a logger starting up after a crash or
+// a Close. A missing file leaves the logger as it is.
+func (this *InMemoryLogger) RestoreCheckpoint() error {
+  if this.opts.checkpointPath == "" {
+    return errors.New("log: no checkpoint configured")
+  }
+  file, err := this.opts.fileSystem().Open(this.opts.checkpointPath)
+  if errors.Is(err, fs.ErrNotExist) {
+    return nil
+  } else if err != nil {
+    return err
+  }
+  defer file.Close()
+  data, err := io.ReadAll(file)
+  if err != nil {
+    return err
+  }
+  return this.UnmarshalJSON(data)
+}
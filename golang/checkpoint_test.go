@@ -0,0 +1,89 @@
+package main
+
+import (
+  "reflect"
+  "testing"
+  "time"
+)
+
+// checkpointHolds waits for the checkpoint at path in fsys to hold
+// messages, failing t if it does not soon.
+func checkpointHolds(t *testing.T, fsys *MemFS, path string, messages []string) {
+  t.Helper()
+  want, _ := marshalMessages(messages)
+  deadline := time.Now().Add(5 * time.Second)
+  for {
+    data, _ := fsys.ReadFile(path)
+    if string(data) == string(want) {
+      return
+    }
+    if time.Now().After(deadline) {
+      t.Fatalf("checkpoint holds %s, want %s", data, want)
+    }
+    time.Sleep(time.Millisecond)
+  }
+}
+
+func TestCheckpointOnInterval(t *testing.T) {
+  fsys := NewMemFS()
+  clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+  l := NewInMemoryLogger(WithFS(fsys), WithClock(clock), WithCheckpoint("logs.ckpt", time.Hour))
+  defer l.Close()
+  l.Log("one")
+  clock.Advance(59 * time.Minute)
+  l.Log("two")
+  if data, err := fsys.ReadFile("logs.ckpt"); err == nil {
+    t.Fatalf("checkpoint written before the interval: %s", data)
+  }
+  clock.Advance(time.Minute)
+  l.Log("three")
+  messages, _ := l.Messages()
+  checkpointHolds(t, fsys, "logs.ckpt", messages)
+  if names := fsys.Names(); !reflect.DeepEqual(names, []string{"logs.ckpt"}) {
+    t.Errorf("files = %q, want no temporary left", names)
+  }
+}
+
+func TestCheckpointOnClose(t *testing.T) {
+  fsys := NewMemFS()
+  l := NewInMemoryLogger(WithFS(fsys), WithCheckpoint("logs.ckpt", time.Hour))
+  l.Log("one")
+  l.Log("two")
+  if err := l.Close(); err != nil {
+    t.Fatal(err)
+  }
+  select {
+  case <-l.checkpoint.stopped:
+  default:
+    t.Error("the checkpoint goroutine is still running after Close")
+  }
+  checkpointHolds(t, fsys, "logs.ckpt", []string{"one", "two"})
+  if err := l.Close(); err != nil {
+    t.Errorf("second Close = %v", err)
+  }
+}
+
+func TestRestoreCheckpoint(t *testing.T) {
+  fsys := NewMemFS()
+  before := NewInMemoryLogger(WithFS(fsys), WithCheckpoint("logs.ckpt", 0))
+  before.Log("one")
+  before.Log("two")
+  before.Close()
+
+  after := NewInMemoryLogger(WithFS(fsys), WithCheckpoint("logs.ckpt", 0))
+  defer after.Close()
+  if err := after.RestoreCheckpoint(); err != nil {
+    t.Fatal(err)
+  }
+  if got, _ := after.Messages(); !reflect.DeepEqual(got, []string{"one", "two"}) {
+    t.Errorf("restored %q, want the checkpointed messages", got)
+  }
+
+  fresh := NewInMemoryLogger(WithFS(NewMemFS()), WithCheckpoint("logs.ckpt", 0))
+  if err := fresh.RestoreCheckpoint(); err != nil {
+    t.Errorf("RestoreCheckpoint with no file = %v, want nil", err)
+  }
+  if err := NewInMemoryLogger().RestoreCheckpoint(); err == nil {
+    t.Error("RestoreCheckpoint without WithCheckpoint = nil error")
+  }
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+  "sync"
+  "time"
+)
+
+// Clock is where loggers read the time: for timestamps, retention, daily
+// rotation and rate limiting alike.
+type Clock interface {
+  Now() time.Time
+}
+
+// realClock is the default Clock, reading time.Now.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+  return time.Now()
+}
+
+// clockFunc adapts a function such as the one given WithNow to a Clock.
+type clockFunc func() time.Time
+
+func (this clockFunc) Now() time.Time {
+  return this()
+}
+
+// WithClock makes a logger read the time from c instead of time.Now.
+func WithClock(c Clock) Option {
+  return func(o *options) {
+    o.timeSource = c
+  }
+}
+
+// ManualClock is a Clock for tests: it stands still until told to move.
+// It is safe for concurrent use.
+type ManualClock struct {
+  mu sync.Mutex
+  now time.Time
+}
+
+var _ Clock = (*ManualClock)(nil)
+
+// NewManualClock returns a ManualClock reading start.
+func NewManualClock(start time.Time) *ManualClock {
+  return &ManualClock{now: start}
+}
+
+func (this *ManualClock) Now() time.Time {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return this.now
+}
+
+// Advance moves the clock forward by d, or back if d is negative.
+func (this *ManualClock) Advance(d time.Duration) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.now = this.now.Add(d)
+}
+
+// Set moves the clock to t.
+func (this *ManualClock) Set(t time.Time) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.now = t
+}
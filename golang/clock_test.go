@@ -0,0 +1,49 @@
+package main
+
+import (
+  "os"
+  "path/filepath"
+  "reflect"
+  "testing"
+  "time"
+)
+
+func TestManualClock(t *testing.T) {
+  start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+  clock := NewManualClock(start)
+  if !clock.Now().Equal(start) {
+    t.Fatalf("Now = %v, want %v", clock.Now(), start)
+  }
+  clock.Advance(90 * time.Second)
+  if want := start.Add(90 * time.Second); !clock.Now().Equal(want) {
+    t.Errorf("after Advance, Now = %v, want %v", clock.Now(), want)
+  }
+  clock.Set(start)
+  if !clock.Now().Equal(start) {
+    t.Errorf("after Set, Now = %v, want %v", clock.Now(), start)
+  }
+}
+
+func TestManualClockAcrossRotation(t *testing.T) {
+  dir := t.TempDir()
+  clock := NewManualClock(time.Date(2024, 3, 9, 23, 59, 0, 0, time.UTC))
+  l, err := NewTimeRotatingLocalLogger(filepath.Join(dir, "app.log"), WithClock(clock))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Log("late")
+  clock.Advance(2 * time.Minute)
+  if got, _ := l.Messages(); got != nil {
+    t.Errorf("Messages just after midnight = %q, want none yet", got)
+  }
+  l.Log("early")
+  for name, want := range(map[string]string{"app-2024-03-09.log": "late\n", "app-2024-03-10.log": "early\n"}) {
+    if data, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(data) != want {
+      t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+    }
+  }
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"early"}) {
+    t.Errorf("Messages = %q, want the new day's", got)
+  }
+}
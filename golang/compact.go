@@ -0,0 +1,99 @@
+package main
+
+import (
+  "bufio"
+  "compress/gzip"
+  "errors"
+  "io"
+  "os"
+)
+
+// Compact rewrites the file from the records it holds, through a temporary
+// file renamed over it, so that space left behind by Clear, a reset gzip
+// stream or a half-written record is reclaimed. Logging waits until it is
+// done, so no message is lost to the swap.
+func (this *LocalLogger) Compact() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.file == nil {
+    return ErrClosed
+  }
+  if err := this.flushLocked(); err != nil {
+    return err
+  }
+  info, err := this.file.Stat()
+  if err != nil {
+    return err
+  }
+  fsys := this.opts.fileSystem()
+  tmp, tmpName, err := createTemp(fsys, this.filename+".compact-")
+  if err != nil {
+    return err
+  }
+  if err := this.copyRecords(tmp); err != nil {
+    tmp.Close()
+    fsys.Remove(tmpName)
+    return err
+  }
+  var chmodErr error
+  if chmod, ok := tmp.(interface{ Chmod(os.FileMode) error }); ok {
+    chmodErr = chmod.Chmod(info.Mode().Perm())
+  }
+  err = errors.Join(chmodErr, tmp.Sync(), tmp.Close())
+  if err == nil {
+    err = fsys.Rename(tmpName, this.filename)
+  }
+  if err != nil {
+    fsys.Remove(tmpName)
+    return err
+  }
+
+  file, size, err := this.openFile(false)
+  if err != nil {
+    return err
+  }
+  err = this.closeLocked()
+  this.install(file, size)
+  return err
+}
+
+// copyRecords writes the file's records to dst in the same encoding.
+func (this *LocalLogger) copyRecords(dst File) error {
+  src, err := this.openRead(this.filename)
+  if err != nil {
+    return err
+  }
+  defer src.Close()
+
+  var w io.Writer = dst
+  var gz *gzip.Writer
+  if this.opts.gzip {
+    gz = gzip.NewWriter(dst)
+    w = gz
+  }
+  bw := bufio.NewWriter(w)
+  sep := this.opts.recordSeparator()
+  scanner := bufio.NewScanner(src)
+  scanner.Split(scanRecords(sep))
+  for scanner.Scan() {
+    record := append(scanner.Bytes(), sep)
+    if this.aead != nil {
+      if record, err = this.seal(record); err != nil {
+        return err
+      }
+    }
+    if _, err := bw.Write(record); err != nil {
+      return err
+    }
+  }
+  if err := scanner.Err(); err != nil {
+    return err
+  }
+  if err := bw.Flush(); err != nil {
+    return err
+  }
+  if gz != nil {
+    return gz.Close()
+  }
+  return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+  "os"
+  "path/filepath"
+  "reflect"
+  "testing"
+)
+
+// TestCompactShrinksGzip syncs a gzip file after every record, leaving a
+// flushed deflate block for each, which Compact rewrites as one stream.
+func TestCompactShrinksGzip(t *testing.T) {
+  dir := t.TempDir()
+  filename := filepath.Join(dir, "app.log.gz")
+  l, err := NewLocalLogger(filename, true, WithSyncOnWrite(true))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  for i := 0; i < 500; i++ {
+    l.Logf(LevelInfo, "line %d", i)
+  }
+  want, _ := l.Messages()
+  before, _ := os.Stat(filename)
+  if err := l.Compact(); err != nil {
+    t.Fatalf("Compact: %v", err)
+  }
+  after, _ := os.Stat(filename)
+  if after.Size() >= before.Size() {
+    t.Errorf("Compact grew the file from %d to %d bytes", before.Size(), after.Size())
+  }
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Compact changed the messages")
+  }
+  l.Log("after")
+  if got, _ := l.Tail(2); !reflect.DeepEqual(got, []string{"line 499", "after"}) {
+    t.Errorf("Tail after logging again = %q", got)
+  }
+  if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+    t.Errorf("the directory holds %d files, want the log alone", len(entries))
+  }
+}
+
+func TestCompactKeepsMode(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "app.log")
+  l, err := NewLocalLogger(filename, true, WithFileMode(0600))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Log("x")
+  if err := l.Compact(); err != nil {
+    t.Fatal(err)
+  }
+  AssertLogged(t, l, "x")
+  if info, _ := os.Stat(filename); info.Mode().Perm() != 0600 {
+    t.Errorf("mode after Compact = %v, want 0600", info.Mode().Perm())
+  }
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "io"
+  "os"
+  "sync"
+)
+
+// ConsoleLogger writes the log messages to a stream, stdout by default.
+// It keeps no history: Messages always returns a nil slice.
+type ConsoleLogger struct {
+  mu sync.Mutex
+  w io.Writer
+  opts options
+  minLevel Level
+  color bool
+  closed bool
+}
+
+var _ LoggerInterface = (*ConsoleLogger)(nil)
+
+// NewConsoleLogger writes to w, or to os.Stdout if w is nil.
+func NewConsoleLogger(w io.Writer, opts ...Option) *ConsoleLogger {
+  if w == nil {
+    w = os.Stdout
+  }
+  this := &ConsoleLogger{w: w, opts: newOptions(opts)}
+  this.color = this.opts.color && (this.opts.colorForced || isTerminal(w))
+  return this
+}
+
+// WithColor makes a ConsoleLogger color Error lines red and Warn lines
+// yellow, when it writes to a terminal.
+func WithColor(enabled bool) Option {
+  return func(o *options) {
+    o.color = enabled
+    o.colorForced = false
+  }
+}
+
+// WithForceColor turns color on or off whether or not a ConsoleLogger
+// writes to a terminal.
+func WithForceColor(enabled bool) Option {
+  return func(o *options) {
+    o.color = enabled
+    o.colorForced = true
+  }
+}
+
+// levelColors holds the ANSI escape that starts each colored level.
+var levelColors = map[Level]string{
+  LevelWarn: "\x1b[33m",
+  LevelError: "\x1b[31m",
+}
+
+const colorReset = "\x1b[0m"
+
+// isTerminal reports whether w is a character device, such as a terminal.
+func isTerminal(w io.Writer) bool {
+  file, ok := w.(*os.File)
+  if !ok {
+    return false
+  }
+  info, err := file.Stat()
+  return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (this *ConsoleLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *ConsoleLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *ConsoleLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *ConsoleLogger) logEntry(e entry) error {
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  if err := this.opts.validateUTF8(&e); err != nil {
+    return err
+  }
+  this.mu.Lock()
+  if this.closed {
+    this.mu.Unlock()
+    return ErrClosed
+  }
+  if e.level < this.minLevel {
+    this.mu.Unlock()
+    return nil
+  }
+  line := this.opts.render(e)
+  out := line
+  if code, ok := levelColors[e.level]; ok && this.color {
+    out = code + line + colorReset
+  }
+  _, err := fmt.Fprintln(this.w, out)
+  this.mu.Unlock()
+  if err != nil {
+    return this.opts.handle(writeFailed(err))
+  }
+  this.opts.notify(e.level, line)
+  return nil
+}
+
+func (this *ConsoleLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *ConsoleLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+// SetMinLevel drops subsequent messages below level.
+func (this *ConsoleLogger) SetMinLevel(level Level) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.minLevel = level
+}
+
+func (this *ConsoleLogger) Messages() ([]string, error) {
+  return nil, nil
+}
+
+func (this *ConsoleLogger) Count() (int, error) {
+  return 0, nil
+}
+
+func (this *ConsoleLogger) Tail(n int) ([]string, error) {
+  return nil, nil
+}
+
+func (this *ConsoleLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  _, err := newMatcher(pattern, useRegexp)
+  return nil, err
+}
+
+func (this *ConsoleLogger) ForEach(fn func(line string) error) error {
+  return nil
+}
+
+func (this *ConsoleLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  if err := checkPage(offset, limit); err != nil {
+    return nil, false, err
+  }
+  return nil, false, nil
+}
+
+func (this *ConsoleLogger) Clear() error {
+  return nil
+}
+
+func (this *ConsoleLogger) Sync() error {
+  return nil
+}
+
+// Close makes later Logs fail with ErrClosed. It does not close the
+// underlying stream.
+func (this *ConsoleLogger) Close() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.closed = true
+  return nil
+}
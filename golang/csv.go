@@ -0,0 +1,94 @@
+package main
+
+import (
+  "bytes"
+  "encoding/csv"
+  "errors"
+  "io"
+  "strings"
+  "time"
+)
+
+// csvHeader is the first row of a file a CSVFormatter writes to.
+var csvHeader = []string{"timestamp", "level", "message"}
+
+// CSVFormatter renders a "timestamp,level,message" row, quoted as
+// encoding/csv does, with an empty timestamp when the time is zero. A
+// LocalLogger using it starts the file with a header row, and its reads
+// parse the rows back into the message column.
+type CSVFormatter struct {
+  Layout string // defaults to time.RFC3339Nano
+}
+
+func (this CSVFormatter) Format(level Level, msg string, t time.Time) string {
+  var ts string
+  if !t.IsZero() {
+    ts = t.Format(layoutOrDefault(this.Layout))
+  }
+  return csvRow([]string{ts, level.String(), msg})
+}
+
+// csvRow encodes one record, without its line ending.
+func csvRow(record []string) string {
+  var b bytes.Buffer
+  w := csv.NewWriter(&b)
+  w.Write(record)
+  w.Flush()
+  return strings.TrimSuffix(b.String(), "\n")
+}
+
+// isCSV reports whether the logger writes CSV rows.
+func (this *LocalLogger) isCSV() bool {
+  _, ok := this.opts.formatter.(CSVFormatter)
+  return ok
+}
+
+// csvMessage returns the message column of a row a CSVFormatter rendered.
+func csvMessage(row string) string {
+  record, err := csv.NewReader(strings.NewReader(row)).Read()
+  if err != nil || len(record) == 0 {
+    return row
+  }
+  return record[len(record)-1]
+}
+
+// scanCSV calls fn with the message column of each row of r, skipping the
+// header, and stopping at the first error fn returns.
+func scanCSV(r io.Reader, fn func(line string) error) error {
+  return scanCSVRows(r, func(record []string) error {
+    return fn(record[len(record)-1])
+  })
+}
+
+// record returns the Record a row this formatter rendered holds. A row
+// with other than the three columns is all message.
+func (this CSVFormatter) record(row []string) Record {
+  if len(row) != len(csvHeader) {
+    return Record{Text: row[len(row)-1]}
+  }
+  record := Record{Text: row[2]}
+  record.Time, _ = time.Parse(layoutOrDefault(this.Layout), row[0])
+  record.Level, _ = ParseLevel(row[1])
+  return record
+}
+
+// scanCSVRows is scanCSV handing fn each row whole; none is empty.
+func scanCSVRows(r io.Reader, fn func(record []string) error) error {
+  reader := csv.NewReader(r)
+  reader.FieldsPerRecord = -1
+  for first := true; ; first = false {
+    record, err := reader.Read()
+    if errors.Is(err, io.EOF) {
+      return nil
+    }
+    if err != nil {
+      return err
+    }
+    if len(record) == 0 || first && strings.Join(record, ",") == strings.Join(csvHeader, ",") {
+      continue
+    }
+    if err := fn(record); err != nil {
+      return err
+    }
+  }
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+  "os"
+  "path/filepath"
+  "reflect"
+  "strings"
+  "testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "log.csv")
+  l, err := NewLocalLogger(filename, true, WithFormatter(CSVFormatter{}))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  messages := []string{`a,"b",c`, "plain", `"quoted"`}
+  for _, mesg := range(messages) {
+    l.Log(mesg)
+  }
+  if got, err := l.Messages(); err != nil || !reflect.DeepEqual(got, messages) {
+    t.Errorf("Messages = %q, %v; want %q", got, err, messages)
+  }
+  data, err := os.ReadFile(filename)
+  if err != nil {
+    t.Fatal(err)
+  }
+  lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+  if lines[0] != "timestamp,level,message" || len(lines) != 1+len(messages) {
+    t.Errorf("file = %q, want a header and a row for each message", data)
+  }
+  if !strings.HasSuffix(lines[1], `,INFO,"a,""b"",c"`) {
+    t.Errorf("row = %q, want the message quoted", lines[1])
+  }
+}
+
+func TestCSVHeaderOnlyOnce(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "log.csv")
+  for i := 0; i < 2; i++ {
+    l, err := NewLocalLogger(filename, false, WithFormatter(CSVFormatter{}))
+    if err != nil {
+      t.Fatal(err)
+    }
+    l.Log("row")
+    l.Close()
+  }
+  data, _ := os.ReadFile(filename)
+  if n := strings.Count(string(data), "timestamp,level,message"); n != 1 {
+    t.Errorf("file has %d header rows, want 1:\n%s", n, data)
+  }
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "sync"
+)
+
+// DedupLogger collapses runs of identical messages, the way syslog does:
+// the first is logged, the repeats are counted, and a "previous message
+// repeated N times" line is logged when a different message arrives or on
+// Flush and Close.
+type DedupLogger struct {
+  mu sync.Mutex
+  inner LoggerInterface
+  last string
+  logged bool // whether last holds a message
+  repeats int
+}
+
+var _ LoggerInterface = (*DedupLogger)(nil)
+
+func NewDedupLogger(inner LoggerInterface) *DedupLogger {
+  return &DedupLogger{inner: inner}
+}
+
+func (this *DedupLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *DedupLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *DedupLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *DedupLogger) logEntry(e entry) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.logged && e.mesg == this.last {
+    this.repeats++
+    return nil
+  }
+  if err := this.flushRepeats(); err != nil {
+    return err
+  }
+  this.last = e.mesg
+  this.logged = true
+  return logEntry(this.inner, e)
+}
+
+func (this *DedupLogger) flushRepeats() error {
+  if this.repeats == 0 {
+    return nil
+  }
+  summary := fmt.Sprintf("previous message repeated %d times", this.repeats)
+  this.repeats = 0
+  return this.inner.Log(summary)
+}
+
+// Flush logs the count of any pending repeats.
+func (this *DedupLogger) Flush() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return this.flushRepeats()
+}
+
+func (this *DedupLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *DedupLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+// Messages returns the collapsed view. Repeats still pending are not in it
+// until Flush.
+func (this *DedupLogger) Messages() ([]string, error) {
+  return this.inner.Messages()
+}
+
+func (this *DedupLogger) Count() (int, error) {
+  return this.inner.Count()
+}
+
+func (this *DedupLogger) Tail(n int) ([]string, error) {
+  return this.inner.Tail(n)
+}
+
+func (this *DedupLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.inner.Search(pattern, useRegexp)
+}
+
+func (this *DedupLogger) ForEach(fn func(line string) error) error {
+  return this.inner.ForEach(fn)
+}
+
+func (this *DedupLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.inner.MessagesPage(offset, limit)
+}
+
+// Clear forgets pending repeats along with the wrapped logger's messages.
+func (this *DedupLogger) Clear() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.logged = false
+  this.repeats = 0
+  return this.inner.Clear()
+}
+
+func (this *DedupLogger) Sync() error {
+  if err := this.Flush(); err != nil {
+    return err
+  }
+  return this.inner.Sync()
+}
+
+func (this *DedupLogger) Close() error {
+  return errors.Join(this.Flush(), this.inner.Close())
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+  "reflect"
+  "testing"
+)
+
+func TestDedupCollapsesRepeats(t *testing.T) {
+  l := NewDedupLogger(NewInMemoryLogger())
+  l.Log("same")
+  l.Log("same")
+  l.Log("same")
+  l.Log("different")
+  want := []string{"same", "previous message repeated 2 times", "different"}
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages = %q, want %q", got, want)
+  }
+}
+
+func TestDedupCloseFlushesRepeats(t *testing.T) {
+  inner := NewInMemoryLogger()
+  l := NewDedupLogger(inner)
+  l.Log("same")
+  l.Log("same")
+  if err := l.Close(); err != nil {
+    t.Fatalf("Close: %v", err)
+  }
+  want := []string{"same", "previous message repeated 1 times"}
+  if got, _ := inner.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages after Close = %q, want %q", got, want)
+  }
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+  "fmt"
+  "sync/atomic"
+)
+
+// defaultLogger is what Default returns. It holds a pointer to the
+// interface, as atomic.Pointer needs a concrete type.
+var defaultLogger atomic.Pointer[LoggerInterface]
+
+func init() {
+  SetDefault(nil)
+}
+
+// SetDefault makes li the logger the top-level Debug, Info, Warn and Error
+// write to. It may be called while other goroutines log. A nil li restores
+// the initial default, a ConsoleLogger on stdout.
+func SetDefault(li LoggerInterface) {
+  if li == nil {
+    li = NewConsoleLogger(nil)
+  }
+  defaultLogger.Store(&li)
+}
+
+// Default returns the logger set by SetDefault.
+func Default() LoggerInterface {
+  return *defaultLogger.Load()
+}
+
+// Debug logs to the default logger at LevelDebug.
+func Debug(format string, args ...interface{}) error {
+  return logEntry(Default(), newEntry(nil, LevelDebug, fmt.Sprintf(format, args...)))
+}
+
+// Info logs to the default logger at LevelInfo.
+func Info(format string, args ...interface{}) error {
+  return logEntry(Default(), newEntry(nil, LevelInfo, fmt.Sprintf(format, args...)))
+}
+
+// Warn logs to the default logger at LevelWarn.
+func Warn(format string, args ...interface{}) error {
+  return logEntry(Default(), newEntry(nil, LevelWarn, fmt.Sprintf(format, args...)))
+}
+
+// Error logs to the default logger at LevelError.
+func Error(format string, args ...interface{}) error {
+  return logEntry(Default(), newEntry(nil, LevelError, fmt.Sprintf(format, args...)))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+  "strings"
+  "sync"
+  "testing"
+)
+
+func TestDefaultLevels(t *testing.T) {
+  capture, restore := CaptureLogger()
+  defer restore()
+  Debug("d %d", 1)
+  Info("i %d", 2)
+  Warn("w %d", 3)
+  Error("e %d", 4)
+  for _, want := range([]string{"d 1", "i 2", "w 3", "e 4"}) {
+    AssertLogged(t, capture, want)
+  }
+}
+
+func TestSetDefaultNilRestoresConsole(t *testing.T) {
+  previous := Default()
+  defer SetDefault(previous)
+  SetDefault(nil)
+  if _, ok := Default().(*ConsoleLogger); !ok {
+    t.Errorf("Default after SetDefault(nil) is %T, want *ConsoleLogger", Default())
+  }
+}
+
+// TestSetDefaultMidFlight swaps the default while other goroutines log to
+// it; run with -race. Every message must land in one of the loggers.
+func TestSetDefaultMidFlight(t *testing.T) {
+  previous := Default()
+  defer SetDefault(previous)
+  loggers := []*InMemoryLogger{NewInMemoryLogger(), NewInMemoryLogger(), NewInMemoryLogger()}
+  SetDefault(loggers[0])
+  var wg sync.WaitGroup
+  for g := 0; g < 8; g++ {
+    wg.Add(1)
+    go func() {
+      defer wg.Done()
+      for i := 0; i < 500; i++ {
+        Info("message %d", i)
+      }
+    }()
+  }
+  for i := 1; i < len(loggers); i++ {
+    SetDefault(loggers[i])
+  }
+  wg.Wait()
+  total := 0
+  for _, l := range(loggers) {
+    n, _ := l.Count()
+    total += n
+  }
+  if total != 8*500 {
+    t.Errorf("%d messages logged across the defaults, want %d", total, 8*500)
+  }
+  if got, ok := Default().(*InMemoryLogger); !ok || got != loggers[len(loggers)-1] {
+    t.Error("Default is not the last logger set")
+  }
+  Info("after")
+  if got, _ := loggers[len(loggers)-1].Tail(1); len(got) != 1 || !strings.Contains(got[0], "after") {
+    t.Errorf("last message = %q, want it in the final default", got)
+  }
+}
@@ -0,0 +1,102 @@
+package main
+
+// Diff compares the messages of two loggers as multisets, ignoring order:
+// missing holds those expected has more often than actual, and extra those
+// actual has more often than expected, each in the order they appear.
+func Diff(expected, actual LoggerInterface) (missing, extra []string, err error) {
+  want, got, err := bothMessages(expected, actual)
+  if err != nil {
+    return nil, nil, err
+  }
+  missing, extra = diffMessages(want, got)
+  return missing, extra, nil
+}
+
+// Union returns the messages of a and b as a multiset union: each message
+// as many times as the logger with more of it has it. They are a's
+// messages in order, followed by b's beyond what a has, in order.
+func Union(a, b LoggerInterface) ([]string, error) {
+  first, second, err := bothMessages(a, b)
+  if err != nil {
+    return nil, err
+  }
+  counts := countMessages(first)
+  union := append([]string(nil), first...)
+  for _, mesg := range(second) {
+    if counts[mesg] > 0 {
+      counts[mesg]--
+    } else {
+      union = append(union, mesg)
+    }
+  }
+  return union, nil
+}
+
+// Intersection returns the messages a and b have in common, each as many
+// times as the logger with fewer of it has it, in the order of a.
+func Intersection(a, b LoggerInterface) ([]string, error) {
+  first, second, err := bothMessages(a, b)
+  if err != nil {
+    return nil, err
+  }
+  counts := countMessages(second)
+  var common []string
+  for _, mesg := range(first) {
+    if counts[mesg] > 0 {
+      counts[mesg]--
+      common = append(common, mesg)
+    }
+  }
+  return common, nil
+}
+
+// Difference returns a's messages less b's, taking each message of b off
+// once, in the order of a. It is what Diff reports missing from b.
+func Difference(a, b LoggerInterface) ([]string, error) {
+  first, second, err := bothMessages(a, b)
+  if err != nil {
+    return nil, err
+  }
+  missing, _ := diffMessages(first, second)
+  return missing, nil
+}
+
+// bothMessages returns the messages of a and then of b.
+func bothMessages(a, b LoggerInterface) ([]string, []string, error) {
+  first, err := a.Messages()
+  if err != nil {
+    return nil, nil, err
+  }
+  second, err := b.Messages()
+  if err != nil {
+    return nil, nil, err
+  }
+  return first, second, nil
+}
+
+// countMessages returns how many times each message occurs.
+func countMessages(messages []string) map[string]int {
+  counts := make(map[string]int, len(messages))
+  for _, mesg := range(messages) {
+    counts[mesg]++
+  }
+  return counts
+}
+
+func diffMessages(want, got []string) (missing, extra []string) {
+  counts := countMessages(got)
+  for _, mesg := range(want) {
+    if counts[mesg] > 0 {
+      counts[mesg]--
+    } else {
+      missing = append(missing, mesg)
+    }
+  }
+  for _, mesg := range(got) {
+    if counts[mesg] > 0 {
+      counts[mesg]--
+      extra = append(extra, mesg)
+    }
+  }
+  return missing, extra
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+  "reflect"
+  "testing"
+)
+
+func TestDiff(t *testing.T) {
+  expected := NewInMemoryLogger()
+  actual := NewInMemoryLogger()
+  for _, mesg := range([]string{"start", "step", "step", "done"}) {
+    expected.Log(mesg)
+  }
+  for _, mesg := range([]string{"step", "start", "retry", "done"}) {
+    actual.Log(mesg)
+  }
+  missing, extra, err := Diff(expected, actual)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if !reflect.DeepEqual(missing, []string{"step"}) {
+    t.Errorf("missing = %q, want the second step", missing)
+  }
+  if !reflect.DeepEqual(extra, []string{"retry"}) {
+    t.Errorf("extra = %q, want retry", extra)
+  }
+}
+
+func TestDiffSameInAnyOrder(t *testing.T) {
+  expected := NewInMemoryLogger()
+  actual := NewInMemoryLogger()
+  expected.Log("a")
+  expected.Log("b")
+  actual.Log("b")
+  actual.Log("a")
+  if missing, extra, err := Diff(expected, actual); missing != nil || extra != nil || err != nil {
+    t.Errorf("Diff = %q, %q, %v; want nothing", missing, extra, err)
+  }
+}
+
+// loggerOf returns an InMemoryLogger holding messages.
+func loggerOf(messages ...string) *InMemoryLogger {
+  l := NewInMemoryLogger()
+  for _, mesg := range(messages) {
+    l.Log(mesg)
+  }
+  return l
+}
+
+func TestSetOperations(t *testing.T) {
+  tests := []struct {
+    name string
+    a, b []string
+    union, intersection, difference []string
+  }{
+    {"overlapping", []string{"x", "y", "z"}, []string{"y", "z", "w"},
+      []string{"x", "y", "z", "w"}, []string{"y", "z"}, []string{"x"}},
+    {"disjoint", []string{"x", "y"}, []string{"v", "w"},
+      []string{"x", "y", "v", "w"}, nil, []string{"x", "y"}},
+    {"duplicates", []string{"x", "x", "y", "x"}, []string{"x", "y", "y"},
+      []string{"x", "x", "y", "x", "y"}, []string{"x", "y"}, []string{"x", "x"}},
+    {"reordered", []string{"a", "b"}, []string{"b", "a"},
+      []string{"a", "b"}, []string{"a", "b"}, nil},
+    {"empty", nil, []string{"x"},
+      []string{"x"}, nil, nil},
+  }
+  ops := map[string]func(a, b LoggerInterface) ([]string, error){
+    "Union": Union,
+    "Intersection": Intersection,
+    "Difference": Difference,
+  }
+  for _, test := range(tests) {
+    want := map[string][]string{
+      "Union": test.union,
+      "Intersection": test.intersection,
+      "Difference": test.difference,
+    }
+    for name, op := range(ops) {
+      got, err := op(loggerOf(test.a...), loggerOf(test.b...))
+      if err != nil || !reflect.DeepEqual(got, want[name]) {
+        t.Errorf("%s: %s = %q, %v; want %q", test.name, name, got, err, want[name])
+      }
+    }
+  }
+}
+
+func TestSetOperationsReadError(t *testing.T) {
+  broken := failingMessages{NewInMemoryLogger()}
+  for name, op := range(map[string]func(a, b LoggerInterface) ([]string, error){
+    "Union": Union,
+    "Intersection": Intersection,
+    "Difference": Difference,
+  }) {
+    if _, err := op(loggerOf("x"), broken); err == nil {
+      t.Errorf("%s with b failing to read = nil error", name)
+    }
+    if _, err := op(broken, loggerOf("x")); err == nil {
+      t.Errorf("%s with a failing to read = nil error", name)
+    }
+  }
+}
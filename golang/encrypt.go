@@ -0,0 +1,101 @@
+package main
+
+import (
+  "crypto/aes"
+  "crypto/cipher"
+  "crypto/rand"
+  "encoding/binary"
+  "errors"
+  "io"
+)
+
+// maxFrameSize bounds the length a frame may claim, so that a corrupt
+// length is refused rather than allocated.
+const maxFrameSize = 64 << 20
+
+// WithEncryption makes a LocalLogger encrypt each record with AES-GCM under
+// key, which must be 16, 24 or 32 bytes long. Records are written as a
+// 4-byte big-endian length followed by a random nonce and the sealed text,
+// and reads decrypt them, refusing a frame over 64 MiB. Encrypted files
+// cannot be followed.
+func WithEncryption(key []byte) Option {
+  return func(o *options) {
+    o.encryptionKey = append([]byte(nil), key...)
+  }
+}
+
+// newAEAD returns the cipher for key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+  block, err := aes.NewCipher(key)
+  if err != nil {
+    return nil, err
+  }
+  return cipher.NewGCM(block)
+}
+
+// seal encrypts a record into its length-prefixed frame.
+func (this *LocalLogger) seal(record []byte) ([]byte, error) {
+  nonceSize := this.aead.NonceSize()
+  frame := make([]byte, 4+nonceSize, 4+nonceSize+len(record)+this.aead.Overhead())
+  if _, err := rand.Read(frame[4:]); err != nil {
+    return nil, err
+  }
+  frame = this.aead.Seal(frame, frame[4:], record, nil)
+  binary.BigEndian.PutUint32(frame, uint32(len(frame)-4))
+  return frame, nil
+}
+
+// decryptReader turns a stream of frames back into the records' text.
+type decryptReader struct {
+  r io.ReadCloser
+  aead cipher.AEAD
+  plain []byte // decrypted but not yet read
+}
+
+func (this *decryptReader) Read(p []byte) (int, error) {
+  for len(this.plain) == 0 {
+    if err := this.next(); err != nil {
+      return 0, err
+    }
+  }
+  n := copy(p, this.plain)
+  this.plain = this.plain[n:]
+  return n, nil
+}
+
+// next decrypts the following frame. A frame cut short, as by a write in
+// progress, ends the stream.
+func (this *decryptReader) next() error {
+  var size [4]byte
+  if _, err := io.ReadFull(this.r, size[:]); err != nil {
+    if errors.Is(err, io.ErrUnexpectedEOF) {
+      return io.EOF
+    }
+    return err
+  }
+  length := binary.BigEndian.Uint32(size[:])
+  if length > maxFrameSize {
+    return ErrAuthFailed
+  }
+  frame := make([]byte, length)
+  if _, err := io.ReadFull(this.r, frame); err != nil {
+    if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+      return io.EOF
+    }
+    return err
+  }
+  nonceSize := this.aead.NonceSize()
+  if len(frame) < nonceSize {
+    return ErrAuthFailed
+  }
+  plain, err := this.aead.Open(nil, frame[:nonceSize], frame[nonceSize:], nil)
+  if err != nil {
+    return ErrAuthFailed
+  }
+  this.plain = plain
+  return nil
+}
+
+func (this *decryptReader) Close() error {
+  return this.r.Close()
+}
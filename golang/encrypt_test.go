@@ -0,0 +1,70 @@
+package main
+
+import (
+  "bytes"
+  "errors"
+  "os"
+  "path/filepath"
+  "reflect"
+  "testing"
+)
+
+var testKey = bytes.Repeat([]byte{7}, 32)
+
+func TestEncryptionRoundTrip(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "secret.log")
+  l, err := NewLocalLogger(filename, true, WithEncryption(testKey))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  messages := []string{"card 4111-1111-1111-1111", "ssn 078-05-1120", ""}
+  for _, mesg := range(messages) {
+    l.Log(mesg)
+  }
+  if got, err := l.Messages(); err != nil || !reflect.DeepEqual(got, messages) {
+    t.Errorf("Messages = %q, %v; want %q", got, err, messages)
+  }
+  data, err := os.ReadFile(filename)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if bytes.Contains(data, []byte("4111")) || bytes.Contains(data, []byte("078-05")) {
+    t.Error("the file holds a message in the clear")
+  }
+}
+
+func TestEncryptionWrongKey(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "secret.log")
+  l, err := NewLocalLogger(filename, true, WithEncryption(testKey))
+  if err != nil {
+    t.Fatal(err)
+  }
+  l.Log("private")
+  l.Close()
+
+  wrong := bytes.Repeat([]byte{8}, 32)
+  l, err = NewLocalLogger(filename, false, WithEncryption(wrong))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  if got, err := l.Messages(); !errors.Is(err, ErrAuthFailed) {
+    t.Errorf("Messages with the wrong key = %q, %v; want %v", got, err, ErrAuthFailed)
+  }
+}
+
+func TestEncryptionOversizedFrame(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "secret.log")
+  if err := os.WriteFile(filename, []byte{0xff, 0xff, 0xff, 0xff, 1, 2, 3}, 0644); err != nil {
+    t.Fatal(err)
+  }
+  l, err := NewLocalLogger(filename, false, WithEncryption(testKey))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  if got, err := l.Messages(); !errors.Is(err, ErrAuthFailed) {
+    t.Errorf("Messages of a frame claiming 4 GiB = %q, %v; want %v", got, err, ErrAuthFailed)
+  }
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+  "fmt"
+  "os"
+)
+
+// WithErrorHandler makes a logger call fn with each error from writing to
+// its destination, as well as returning it, so that fire-and-forget call
+// sites that drop the error still have it routed somewhere. It also gets
+// the errors of writes made in the background, such as a periodic flush
+// or a batch sent on a timer, which no call returns, and ErrPanicked for
+// a callback that panicked. Errors from a closed logger or a done context
+// are only returned.
+func WithErrorHandler(fn func(error)) Option {
+  return func(o *options) {
+    o.errorHandler = fn
+  }
+}
+
+// handle passes a non-nil err to the WithErrorHandler function, if any,
+// and returns it.
+func (this *options) handle(err error) error {
+  if err != nil && this.errorHandler != nil {
+    this.callErrorHandler(err)
+  }
+  return err
+}
+
+// callErrorHandler calls the WithErrorHandler function, reporting a panic
+// in it on stderr, as there is nowhere else to send it.
+func (this *options) callErrorHandler(err error) {
+  defer func() {
+    if r := recover(); r != nil {
+      fmt.Fprintf(os.Stderr, "log: error handler panicked on %v: %v\n", err, r)
+    }
+  }()
+  this.errorHandler(err)
+}
+
+// safeCall runs fn, which calls into a user-supplied function named by
+// what, recovering a panic so that it cannot take down the logging path
+// and the program with it. The panic goes to the WithErrorHandler function
+// as ErrPanicked, or to stderr without one, and safeCall returns false.
+func (this *options) safeCall(what string, fn func()) (ok bool) {
+  defer func() {
+    if r := recover(); r != nil {
+      ok = false
+      err := fmt.Errorf("%w: %s: %v", ErrPanicked, what, r)
+      if this.errorHandler != nil {
+        this.callErrorHandler(err)
+      } else {
+        fmt.Fprintln(os.Stderr, err)
+      }
+    }
+  }()
+  fn()
+  return true
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "io"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+  "time"
+)
+
+// readOnlyFS opens every file read-only, whatever the flags ask, so that
+// writes fail even for a user the permission bits do not stop.
+type readOnlyFS struct {
+  osFS
+}
+
+func (this readOnlyFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+  return this.osFS.OpenFile(name, os.O_RDONLY, perm)
+}
+
+func TestErrorHandlerOnReadOnlyFile(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "readonly.log")
+  if err := os.WriteFile(filename, []byte("existing\n"), 0444); err != nil {
+    t.Fatal(err)
+  }
+  var handled []error
+  l, err := NewLocalLogger(filename, false, WithFS(readOnlyFS{}), WithErrorHandler(func(err error) {
+    handled = append(handled, err)
+  }))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  err = l.Log("refused")
+  if !errors.Is(err, ErrWriteFailed) {
+    t.Errorf("Log = %v, want ErrWriteFailed", err)
+  }
+  if len(handled) != 1 || handled[0] != err {
+    t.Errorf("handler got %v, want the error Log returned", handled)
+  }
+  AssertLogged(t, l, "existing")
+  AssertNotLogged(t, l, "refused")
+}
+
+func TestErrorHandlerNotCalledForClosed(t *testing.T) {
+  called := false
+  l := NewInMemoryLogger(WithErrorHandler(func(error) {
+    called = true
+  }))
+  l.Close()
+  if err := l.Log("late"); !errors.Is(err, ErrClosed) || called {
+    t.Errorf("Log after Close = %v, handler called %v; want ErrClosed only returned", err, called)
+  }
+}
+
+func TestErrorHandlerPanicIsRecovered(t *testing.T) {
+  fsys := NewMemFS()
+  l, err := NewLocalLogger("out.log", true, WithFS(fsys), WithErrorHandler(func(error) {
+    panic("handler broke")
+  }))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.file.Close()
+  if err := l.Log("lost"); !errors.Is(err, ErrWriteFailed) {
+    t.Errorf("Log = %v, want ErrWriteFailed despite the handler", err)
+  }
+}
+
+// panicFormatter is a Formatter that always panics.
+type panicFormatter struct{}
+
+func (panicFormatter) Format(level Level, msg string, t time.Time) string {
+  panic("formatter broke")
+}
+
+func TestCallbackPanicsAreRecovered(t *testing.T) {
+  callbacks := map[string]struct {
+    opt Option
+    want string // stored for the message "card 4111"
+  }{
+    "hook": {WithHook(func(Level, string) { panic("hook broke") }), "card 4111"},
+    "transform": {WithTransform(func(string) string { panic("transform broke") }), "card 4111"},
+    "redactor": {WithRedactor(func(string) string { panic("redactor broke") }), redactionFailed},
+    "formatter": {WithFormatter(panicFormatter{}), "card 4111"},
+    "context extractor": {WithContextExtractor(func(context.Context) Fields { panic("extractor broke") }), "card 4111"},
+  }
+  for what, callback := range(callbacks) {
+    var handled []error
+    handler := WithErrorHandler(func(err error) { handled = append(handled, err) })
+    for name, l := range(bothLoggers(t, callback.opt, handler)) {
+      handled = nil
+      if err := l.LogCtx(context.Background(), "card 4111"); err != nil {
+        t.Errorf("%s with a panicking %s: Log = %v, want nil", name, what, err)
+      }
+      if got, _ := l.Messages(); len(got) != 1 || got[0] != callback.want {
+        t.Errorf("%s with a panicking %s: Messages() = %q, want %q", name, what, got, callback.want)
+      }
+      if len(handled) != 1 || !errors.Is(handled[0], ErrPanicked) || !strings.Contains(handled[0].Error(), what) {
+        t.Errorf("%s with a panicking %s: error handler got %v", name, what, handled)
+      }
+    }
+  }
+}
+
+func TestCallbackPanicWithoutHandler(t *testing.T) {
+  r, w, err := os.Pipe()
+  if err != nil {
+    t.Fatal(err)
+  }
+  stderr := os.Stderr
+  os.Stderr = w
+  l := NewInMemoryLogger(WithTransform(func(string) string { panic("transform broke") }))
+  err = l.Log("kept")
+  os.Stderr = stderr
+  w.Close()
+  out, _ := io.ReadAll(r)
+  if err != nil {
+    t.Errorf("Log = %v, want nil", err)
+  }
+  AssertLogged(t, l, "kept")
+  if !strings.Contains(string(out), "transform broke") {
+    t.Errorf("stderr = %q, want the panic reported", out)
+  }
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+)
+
+// The errors loggers return for the failures callers may want to tell
+// apart, with errors.Is.
+var (
+  // ErrClosed is returned by a write to a logger after its Close.
+  ErrClosed = errors.New("log: logger is closed")
+
+  // ErrReadOnly is returned by the writing methods of a ReadOnly view.
+  ErrReadOnly = errors.New("log: logger is read-only")
+
+  // ErrQueueFull is returned for a message refused under DropNewest, by
+  // a full AsyncLogger queue or a bounded InMemoryLogger at its bound.
+  ErrQueueFull = errors.New("log: queue is full")
+
+  // ErrWriteFailed wraps the error from writing to a logger's
+  // destination, which stays reachable through errors.Is and errors.As.
+  ErrWriteFailed = errors.New("log: write failed")
+
+  // ErrInvalidUTF8 is returned for a message refused under UTF8Reject.
+  ErrInvalidUTF8 = errors.New("log: message is not valid UTF-8")
+
+  // ErrMultiline is returned for a message refused under MultilineReject.
+  ErrMultiline = errors.New("log: message has more than one line")
+
+  // ErrPanicked is what the WithErrorHandler function gets when a hook,
+  // transform, redactor, formatter, context extractor or OnRotate
+  // function panics.
+  ErrPanicked = errors.New("log: callback panicked")
+
+  // ErrAuthFailed is returned by reads of an encrypted file when a record
+  // does not decrypt, because the key is wrong or the file was tampered
+  // with.
+  ErrAuthFailed = errors.New("log: authentication failed: wrong key or corrupt record")
+)
+
+// writeFailed wraps a non-nil err in ErrWriteFailed.
+func writeFailed(err error) error {
+  if err == nil || errors.Is(err, ErrWriteFailed) {
+    return err
+  }
+  return fmt.Errorf("%w: %w", ErrWriteFailed, err)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+  "errors"
+  "io"
+  "os"
+  "path/filepath"
+  "testing"
+)
+
+func TestLogAfterCloseIsErrClosed(t *testing.T) {
+  dir := t.TempDir()
+  local := func(name string) *LocalLogger {
+    l, err := NewLocalLogger(filepath.Join(dir, name), true)
+    if err != nil {
+      t.Fatal(err)
+    }
+    return l
+  }
+  rotating, err := NewRotatingLocalLogger(filepath.Join(dir, "rotating.log"), 1<<20, 2)
+  if err != nil {
+    t.Fatal(err)
+  }
+  daily, err := NewTimeRotatingLocalLogger(filepath.Join(dir, "daily.log"))
+  if err != nil {
+    t.Fatal(err)
+  }
+  network, err := NewNetworkLogger("collector:9000", WithDialer((&fakeDialer{}).dial))
+  if err != nil {
+    t.Fatal(err)
+  }
+  loggers := map[string]LoggerInterface{
+    "InMemoryLogger": NewInMemoryLogger(),
+    "LocalLogger": local("local.log"),
+    "ConsoleLogger": NewConsoleLogger(io.Discard),
+    "AsyncLogger": NewAsyncLogger(NewInMemoryLogger()),
+    "SpillingLogger": NewSpillingLogger(local("spilling.log"), 4),
+    "RotatingLocalLogger": rotating,
+    "TimeRotatingLocalLogger": daily,
+    "NetworkLogger": network,
+    "MultiLogger": NewMultiLogger(NewInMemoryLogger(), NewInMemoryLogger()),
+    "DedupLogger": NewDedupLogger(NewInMemoryLogger()),
+    "LevelRouter": LevelRouter(nil, NewInMemoryLogger()),
+  }
+  for name, l := range(loggers) {
+    if err := l.Close(); err != nil {
+      t.Errorf("%s: Close: %v", name, err)
+    }
+    if err := l.Log("late"); !errors.Is(err, ErrClosed) {
+      t.Errorf("%s: Log after Close returned %v, want ErrClosed", name, err)
+    }
+    if err := l.Close(); err != nil {
+      t.Errorf("%s: second Close: %v", name, err)
+    }
+  }
+}
+
+func TestInMemoryReadableAfterClose(t *testing.T) {
+  l := NewInMemoryLogger()
+  l.Log("kept")
+  l.Close()
+  if err := LogBatch(l, []string{"late"}); !errors.Is(err, ErrClosed) {
+    t.Errorf("LogBatch after Close returned %v", err)
+  }
+  AssertLoggedCount(t, l, 1)
+}
+
+func TestErrReadOnly(t *testing.T) {
+  view := ReadOnly(NewInMemoryLogger())
+  if err := view.Log("x"); !errors.Is(err, ErrReadOnly) {
+    t.Errorf("Log returned %v, want ErrReadOnly", err)
+  }
+  if err := view.Clear(); !errors.Is(err, ErrReadOnly) {
+    t.Errorf("Clear returned %v, want ErrReadOnly", err)
+  }
+}
+
+func TestErrQueueFull(t *testing.T) {
+  l := NewBoundedInMemoryLogger(1, WithOverflow(DropNewest))
+  l.Log("first")
+  if err := l.Log("second"); !errors.Is(err, ErrQueueFull) {
+    t.Errorf("Log returned %v, want ErrQueueFull", err)
+  }
+}
+
+func TestErrWriteFailedWraps(t *testing.T) {
+  fsys := NewMemFS()
+  l, err := NewLocalLogger("out.log", true, WithFS(fsys))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.file.Close() // the next write fails on the closed file underneath
+  err = l.Log("lost")
+  if !errors.Is(err, ErrWriteFailed) {
+    t.Fatalf("Log returned %v, want ErrWriteFailed", err)
+  }
+  if !errors.Is(err, os.ErrClosed) {
+    t.Errorf("the cause %v is not reachable with errors.Is", err)
+  }
+}
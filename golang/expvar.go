@@ -0,0 +1,37 @@
+package main
+
+import (
+  "expvar"
+)
+
+// PublishExpvar publishes l's counts under name in the expvar package, and
+// so at /debug/vars, as {"count":N} plus, for a logger that keeps Stats,
+// "total" and "byLevel" keyed by level name. They are read from l each
+// time the variable is shown. Like expvar.Publish, it panics if name is
+// already taken.
+func PublishExpvar(name string, l LoggerInterface) {
+  expvar.Publish(name, expvar.Func(func() interface{} {
+    return expvarValue(l)
+  }))
+}
+
+// expvarValue is what PublishExpvar shows for l. A failing Count is shown
+// as "error" instead.
+func expvarValue(l LoggerInterface) map[string]interface{} {
+  value := make(map[string]interface{})
+  if n, err := l.Count(); err != nil {
+    value["error"] = err.Error()
+  } else {
+    value["count"] = n
+  }
+  if s, ok := l.(interface{ Stats() Stats }); ok {
+    stats := s.Stats()
+    byLevel := make(map[string]uint64, len(stats.ByLevel))
+    for level, n := range(stats.ByLevel) {
+      byLevel[level.String()] = n
+    }
+    value["total"] = stats.Total
+    value["byLevel"] = byLevel
+  }
+  return value
+}
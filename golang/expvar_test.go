@@ -0,0 +1,39 @@
+package main
+
+import (
+  "encoding/json"
+  "expvar"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestPublishExpvar(t *testing.T) {
+  l := NewInMemoryLogger()
+  PublishExpvar("test.logger", l)
+  l.Log("one")
+  l.Logf(LevelWarn, "two")
+  l.Logf(LevelWarn, "three")
+
+  var value struct {
+    Count int
+    Total uint64
+    ByLevel map[string]uint64
+  }
+  if err := json.Unmarshal([]byte(expvar.Get("test.logger").String()), &value); err != nil {
+    t.Fatal(err)
+  }
+  if value.Count != 3 || value.Total != 3 || value.ByLevel["WARN"] != 2 || value.ByLevel["INFO"] != 1 {
+    t.Errorf("published %+v, want a count of 3 with 2 warnings", value)
+  }
+
+  l.Log("four")
+  rec := httptest.NewRecorder()
+  expvar.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/vars", nil))
+  var vars map[string]json.RawMessage
+  if err := json.Unmarshal(rec.Body.Bytes(), &vars); err != nil {
+    t.Fatal(err)
+  }
+  if err := json.Unmarshal(vars["test.logger"], &value); err != nil || value.Count != 4 {
+    t.Errorf("/debug/vars shows %s, want a count of 4", vars["test.logger"])
+  }
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+  "context"
+)
+
+// WithContextExtractor makes LogCtx add the fields fn pulls from its
+// context, such as a trace or request id, to the message, as though they
+// had been given WithFields. Fields set on the message itself win over
+// them, and of several extractors the later one wins. A formatter that
+// renders fields, such as JSONFormatter, writes them as keys; others get
+// them appended as key=value pairs.
+func WithContextExtractor(fn func(ctx context.Context) Fields) Option {
+  return func(o *options) {
+    o.extractors = append(o.extractors, fn)
+  }
+}
+
+// extract returns e's fields merged over those the WithContextExtractor
+// functions pull from its context, if it has one.
+func (this *options) extract(e entry) Fields {
+  if e.ctx == nil || len(this.extractors) == 0 {
+    return e.fields
+  }
+  var extracted Fields
+  for _, fn := range(this.extractors) {
+    var fields Fields
+    this.safeCall("context extractor", func() {
+      fields = fn(e.ctx)
+    })
+    extracted = mergeFields(extracted, fields)
+  }
+  return mergeFields(extracted, e.fields)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+  "context"
+  "encoding/json"
+  "reflect"
+  "testing"
+)
+
+type requestKey struct{}
+
+// requestFields pulls the request id that withRequest stored, if any.
+func requestFields(ctx context.Context) Fields {
+  id, ok := ctx.Value(requestKey{}).(string)
+  if !ok {
+    return nil
+  }
+  return Fields{"request_id": id}
+}
+
+func withRequest(id string) context.Context {
+  return context.WithValue(context.Background(), requestKey{}, id)
+}
+
+func TestContextExtractorJSON(t *testing.T) {
+  l := NewInMemoryLogger(WithFormatter(JSONFormatter{}), WithContextExtractor(requestFields))
+  l.LogCtx(withRequest("r-42"), "handled")
+  messages, _ := l.Messages()
+  var got map[string]interface{}
+  if len(messages) != 1 || json.Unmarshal([]byte(messages[0]), &got) != nil {
+    t.Fatalf("Messages() = %q, want one JSON line", messages)
+  }
+  if got["request_id"] != "r-42" || got["msg"] != "handled" {
+    t.Errorf("logged %v, want request_id r-42 on the message", got)
+  }
+}
+
+func TestContextExtractorPlain(t *testing.T) {
+  for name, l := range(bothLoggers(t, WithContextExtractor(requestFields))) {
+    l.LogCtx(withRequest("r-42"), "handled")
+    l.LogCtx(context.Background(), "no request")
+    l.Log("no context")
+    want := []string{"handled request_id=r-42", "no request", "no context"}
+    if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+      t.Errorf("%s: Messages() = %q, want %q", name, got, want)
+    }
+  }
+}
+
+func TestContextExtractorPrecedence(t *testing.T) {
+  override := func(context.Context) Fields {
+    return Fields{"request_id": "later", "user": "ann"}
+  }
+  l := NewInMemoryLogger(WithContextExtractor(requestFields), WithContextExtractor(override))
+  l.WithFields(Fields{"user": "bob"}).LogCtx(withRequest("r-42"), "handled")
+  AssertLogged(t, l, "handled request_id=later user=bob")
+}
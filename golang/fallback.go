@@ -0,0 +1,173 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "sync"
+)
+
+// FallbackLogger writes to a primary logger and, whenever that fails, to a
+// secondary one instead, such as a NetworkLogger backed by a LocalLogger.
+// Drain later moves the secondary's backlog over to the primary. Reads see
+// only the primary. It is safe for concurrent use.
+type FallbackLogger struct {
+  mu sync.Mutex
+  primary LoggerInterface
+  secondary LoggerInterface
+  pending bool // the secondary holds messages not yet drained
+  failures writeFailures
+}
+
+var _ LoggerInterface = (*FallbackLogger)(nil)
+
+func NewFallbackLogger(primary, secondary LoggerInterface) *FallbackLogger {
+  return &FallbackLogger{primary: primary, secondary: secondary}
+}
+
+func (this *FallbackLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *FallbackLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *FallbackLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+// logEntry fails only if both loggers do, returning both errors joined.
+func (this *FallbackLogger) logEntry(e entry) error {
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  err := logEntry(this.primary, e)
+  if err == nil {
+    return nil
+  }
+  this.failures.add(err)
+  if secondaryErr := logEntry(this.secondary, e); secondaryErr != nil {
+    this.failures.add(secondaryErr)
+    return errors.Join(err, secondaryErr)
+  }
+  this.pending = true
+  return nil
+}
+
+// Pending reports whether messages have gone to the secondary logger
+// since the last successful Drain.
+func (this *FallbackLogger) Pending() bool {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return this.pending
+}
+
+// LastError returns the most recent error either logger returned for a
+// message, or nil if none has failed since ResetErrors. A failure of the
+// primary counts even when the secondary took the message.
+func (this *FallbackLogger) LastError() error {
+  return this.failures.lastError()
+}
+
+// FailedWrites returns how many writes to either logger have failed since
+// ResetErrors.
+func (this *FallbackLogger) FailedWrites() uint64 {
+  return this.failures.n.Load()
+}
+
+// ResetErrors clears LastError and FailedWrites.
+func (this *FallbackLogger) ResetErrors() {
+  this.failures.reset()
+}
+
+// Drain logs the secondary's messages into the primary, oldest first, and
+// then clears the secondary. If the primary fails part way, the messages
+// it did take are cleared and the rest stay in the secondary for the next
+// Drain, so nothing is lost or sent twice. Messages are replayed as the
+// secondary stored them, so the primary's formatting is applied to them
+// a second time.
+func (this *FallbackLogger) Drain() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if !this.pending {
+    return nil
+  }
+  messages, err := this.secondary.Messages()
+  if err != nil {
+    return err
+  }
+  n := 0
+  for ; n < len(messages); n++ {
+    if err = this.primary.Log(messages[n]); err != nil {
+      break
+    }
+  }
+  if err == nil {
+    this.pending = false
+    return this.secondary.Clear()
+  }
+  if n == 0 {
+    return err
+  }
+  if clearErr := this.secondary.Clear(); clearErr != nil {
+    return errors.Join(err, clearErr)
+  }
+  for _, mesg := range(messages[n:]) {
+    if logErr := this.secondary.Log(mesg); logErr != nil {
+      return errors.Join(err, logErr)
+    }
+  }
+  return err
+}
+
+func (this *FallbackLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *FallbackLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *FallbackLogger) Messages() ([]string, error) {
+  return this.primary.Messages()
+}
+
+func (this *FallbackLogger) Count() (int, error) {
+  return this.primary.Count()
+}
+
+func (this *FallbackLogger) Tail(n int) ([]string, error) {
+  return this.primary.Tail(n)
+}
+
+func (this *FallbackLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.primary.Search(pattern, useRegexp)
+}
+
+func (this *FallbackLogger) ForEach(fn func(line string) error) error {
+  return this.primary.ForEach(fn)
+}
+
+func (this *FallbackLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.primary.MessagesPage(offset, limit)
+}
+
+// Clear clears both loggers, discarding any backlog.
+func (this *FallbackLogger) Clear() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.pending = false
+  return errors.Join(this.primary.Clear(), this.secondary.Clear())
+}
+
+func (this *FallbackLogger) Sync() error {
+  return errors.Join(this.primary.Sync(), this.secondary.Sync())
+}
+
+// Close closes both loggers, leaving any backlog in the secondary.
+func (this *FallbackLogger) Close() error {
+  return errors.Join(this.primary.Close(), this.secondary.Close())
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+  "errors"
+  "reflect"
+  "testing"
+)
+
+var errDown = errors.New("primary down")
+
+// flakyLogger is an InMemoryLogger that refuses messages while down.
+type flakyLogger struct {
+  *InMemoryLogger
+  down bool
+}
+
+func (this *flakyLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *flakyLogger) logEntry(e entry) error {
+  if this.down {
+    return errDown
+  }
+  return this.InMemoryLogger.logEntry(e)
+}
+
+func TestFallbackFailoverAndDrain(t *testing.T) {
+  primary := &flakyLogger{InMemoryLogger: NewInMemoryLogger()}
+  secondary := newTestLocal(t)
+  l := NewFallbackLogger(primary, secondary)
+
+  l.Log("one")
+  primary.down = true
+  for _, mesg := range([]string{"two", "three"}) {
+    if err := l.Log(mesg); err != nil {
+      t.Fatalf("Log(%q) with the secondary up = %v", mesg, err)
+    }
+  }
+  if !l.Pending() || !errors.Is(l.LastError(), errDown) {
+    t.Errorf("Pending = %v, LastError = %v after a failover", l.Pending(), l.LastError())
+  }
+  AssertLoggedCount(t, secondary, 2)
+
+  if err := l.Drain(); !errors.Is(err, errDown) {
+    t.Errorf("Drain with the primary down = %v, want %v", err, errDown)
+  }
+  AssertLoggedCount(t, secondary, 2)
+
+  primary.down = false
+  l.Log("four")
+  if err := l.Drain(); err != nil {
+    t.Fatalf("Drain: %v", err)
+  }
+  if l.Pending() {
+    t.Error("still Pending after a Drain")
+  }
+  AssertLoggedCount(t, secondary, 0)
+  want := []string{"one", "four", "two", "three"}
+  if got, _ := primary.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("primary holds %q, want %q", got, want)
+  }
+}
+
+func TestFallbackBothFail(t *testing.T) {
+  primary := &flakyLogger{InMemoryLogger: NewInMemoryLogger(), down: true}
+  secondary := NewInMemoryLogger()
+  secondary.Close()
+  err := NewFallbackLogger(primary, secondary).Log("lost")
+  if !errors.Is(err, errDown) || !errors.Is(err, ErrClosed) {
+    t.Errorf("Log = %v, want both errors", err)
+  }
+}
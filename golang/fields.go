@@ -0,0 +1,171 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "sort"
+  "strconv"
+  "strings"
+)
+
+// Fields are key-value pairs attached to the messages of a child logger.
+type Fields map[string]interface{}
+
+// entry is a message on its way from a Log call to the logger storing it.
+type entry struct {
+  ctx context.Context // nil unless logged through LogCtx
+  level Level
+  mesg string
+  fields Fields
+  callers [callerDepth]uintptr // where it was logged from, if WithCaller is used
+}
+
+// canceled reports why the entry's context no longer wants it logged.
+func (this entry) canceled() error {
+  if this.ctx == nil {
+    return nil
+  }
+  return this.ctx.Err()
+}
+
+// entryLogger is implemented by loggers that take a whole entry, so that
+// wrappers and child loggers can hand fields down without flattening them.
+type entryLogger interface {
+  logEntry(e entry) error
+}
+
+// logEntry sends e to li. A logger that only takes text gets the fields
+// appended to the message as key=value pairs.
+func logEntry(li LoggerInterface, e entry) error {
+  if el, ok := li.(entryLogger); ok {
+    return el.logEntry(e)
+  }
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  return li.Logf(e.level, "%s", appendFields(e.mesg, e.fields))
+}
+
+// mergeFields returns the union of two field sets, preferring over's values.
+func mergeFields(base, over Fields) Fields {
+  if len(base) == 0 {
+    return over
+  }
+  if len(over) == 0 {
+    return base
+  }
+  merged := make(Fields, len(base)+len(over))
+  for k, v := range(base) {
+    merged[k] = v
+  }
+  for k, v := range(over) {
+    merged[k] = v
+  }
+  return merged
+}
+
+func (this Fields) sortedKeys() []string {
+  keys := make([]string, 0, len(this))
+  for k := range(this) {
+    keys = append(keys, k)
+  }
+  sort.Strings(keys)
+  return keys
+}
+
+// appendFields renders fields after mesg as " key=value" pairs, sorted by
+// key, quoting values that would otherwise be ambiguous.
+func appendFields(mesg string, fields Fields) string {
+  if len(fields) == 0 {
+    return mesg
+  }
+  var b strings.Builder
+  b.WriteString(mesg)
+  for _, k := range(fields.sortedKeys()) {
+    v := fmt.Sprint(fields[k])
+    if v == "" || strings.ContainsAny(v, " =\"") {
+      v = strconv.Quote(v)
+    }
+    fmt.Fprintf(&b, " %s=%s", k, v)
+  }
+  return b.String()
+}
+
+
+// fieldLogger is the child logger returned by WithFields. It writes through
+// its parent, adding its fields to every message, and reads from it.
+type fieldLogger struct {
+  parent LoggerInterface
+  fields Fields
+}
+
+var _ LoggerInterface = (*fieldLogger)(nil)
+
+func withFields(parent LoggerInterface, fields Fields) LoggerInterface {
+  return &fieldLogger{parent: parent, fields: fields}
+}
+
+func (this *fieldLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *fieldLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *fieldLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+// logEntry lets fields set closer to the call win over the child's own.
+func (this *fieldLogger) logEntry(e entry) error {
+  e.fields = mergeFields(this.fields, e.fields)
+  return logEntry(this.parent, e)
+}
+
+// WithFields returns a sibling carrying both sets of fields; on a collision
+// the newer fields win.
+func (this *fieldLogger) WithFields(fields Fields) LoggerInterface {
+  return &fieldLogger{parent: this.parent, fields: mergeFields(this.fields, fields)}
+}
+
+func (this *fieldLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *fieldLogger) Messages() ([]string, error) {
+  return this.parent.Messages()
+}
+
+func (this *fieldLogger) Count() (int, error) {
+  return this.parent.Count()
+}
+
+func (this *fieldLogger) Tail(n int) ([]string, error) {
+  return this.parent.Tail(n)
+}
+
+func (this *fieldLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.parent.Search(pattern, useRegexp)
+}
+
+func (this *fieldLogger) ForEach(fn func(line string) error) error {
+  return this.parent.ForEach(fn)
+}
+
+func (this *fieldLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.parent.MessagesPage(offset, limit)
+}
+
+func (this *fieldLogger) Clear() error {
+  return this.parent.Clear()
+}
+
+func (this *fieldLogger) Sync() error {
+  return this.parent.Sync()
+}
+
+// Close is a no-op: the parent owns the underlying resources.
+func (this *fieldLogger) Close() error {
+  return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+  "errors"
+  "io"
+  "io/fs"
+  "math/rand"
+  "os"
+  "path/filepath"
+  "strconv"
+  "strings"
+)
+
+// FS is the filesystem a LocalLogger keeps its files in. The default is
+// the operating system's; WithFS swaps in another, such as a MemFS so
+// that tests of rotation or compaction touch no disk. Errors for a
+// missing or existing file should match fs.ErrNotExist and fs.ErrExist.
+type FS interface {
+  Open(name string) (File, error)
+  OpenFile(name string, flag int, perm os.FileMode) (File, error)
+  Rename(oldpath, newpath string) error
+  Remove(name string) error
+  Truncate(name string, size int64) error
+}
+
+// File is an open file of an FS: the part of *os.File a LocalLogger uses.
+type File interface {
+  io.Reader
+  io.ReaderAt
+  io.Writer
+  io.Seeker
+  io.Closer
+  Stat() (os.FileInfo, error)
+  Sync() error
+  Truncate(size int64) error
+}
+
+// WithFS makes a LocalLogger keep its files in fsys rather than the
+// operating system's filesystem. WithMkdirAll applies only where fsys
+// has a MkdirAll method like os.MkdirAll's, and Follow and the memory
+// mapped reads need the operating system's.
+func WithFS(fsys FS) Option {
+  return func(o *options) {
+    o.fsys = fsys
+  }
+}
+
+// fileSystem is the FS given WithFS, or the operating system's.
+func (this *options) fileSystem() FS {
+  if this.fsys != nil {
+    return this.fsys
+  }
+  return osFS{}
+}
+
+// osFS is the operating system's filesystem, by way of package os.
+type osFS struct{}
+
+func (osFS) Open(name string) (File, error) {
+  file, err := os.Open(name)
+  if err != nil {
+    return nil, err
+  }
+  return file, nil
+}
+
+func (osFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+  file, err := os.OpenFile(name, flag, perm)
+  if err != nil {
+    return nil, err
+  }
+  return file, nil
+}
+
+func (osFS) Rename(oldpath, newpath string) error {
+  return os.Rename(oldpath, newpath)
+}
+
+func (osFS) Remove(name string) error {
+  return os.Remove(name)
+}
+
+func (osFS) Truncate(name string, size int64) error {
+  return os.Truncate(name, size)
+}
+
+func (osFS) MkdirAll(path string, perm os.FileMode) error {
+  return os.MkdirAll(path, perm)
+}
+
+func (osFS) namesWithPrefix(prefix string) ([]string, error) {
+  dir, base := filepath.Split(prefix)
+  entries, err := os.ReadDir(filepath.Clean(dir + "."))
+  if err != nil {
+    return nil, err
+  }
+  var names []string
+  for _, entry := range(entries) {
+    if strings.HasPrefix(entry.Name(), base) {
+      names = append(names, dir+entry.Name())
+    }
+  }
+  return names, nil
+}
+
+// nameLister is implemented by the FSs that can list their files, as a
+// RotatingLocalLogger needs to find the ones it rotated out before.
+type nameLister interface {
+  // namesWithPrefix returns the names of the files whose names start
+  // with prefix, which are in one directory.
+  namesWithPrefix(prefix string) ([]string, error)
+}
+
+// createTemp creates a new file in fsys named prefix and a random number,
+// as os.CreateTemp does, and returns it with its name.
+func createTemp(fsys FS, prefix string) (File, string, error) {
+  for try := 0; ; try++ {
+    name := prefix + strconv.FormatUint(uint64(rand.Uint32()), 10)
+    file, err := fsys.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+    if errors.Is(err, fs.ErrExist) && try < 10000 {
+      continue
+    }
+    return file, name, err
+  }
+}
+
+// isOS reports whether the logger's files are in the operating system's
+// filesystem.
+func (this *LocalLogger) isOS() bool {
+  _, ok := this.opts.fileSystem().(osFS)
+  return ok
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "regexp"
+)
+
+// FilterLogger forwards to another logger only the messages that a
+// predicate keeps. Reads go straight to the wrapped logger.
+type FilterLogger struct {
+  inner LoggerInterface
+  keep func(mesg string) bool
+}
+
+var _ LoggerInterface = (*FilterLogger)(nil)
+
+// NewFilterLogger forwards the messages for which keep returns true.
+// A nil keep forwards everything.
+func NewFilterLogger(inner LoggerInterface, keep func(mesg string) bool) *FilterLogger {
+  return &FilterLogger{inner: inner, keep: keep}
+}
+
+// NewRegexpFilter forwards the messages matching pattern if keepMatches is
+// set, and the ones not matching it otherwise.
+func NewRegexpFilter(inner LoggerInterface, pattern string, keepMatches bool) (*FilterLogger, error) {
+  re, err := regexp.Compile(pattern)
+  if err != nil {
+    return nil, err
+  }
+  keep := func(mesg string) bool {
+    return re.MatchString(mesg) == keepMatches
+  }
+  return NewFilterLogger(inner, keep), nil
+}
+
+func (this *FilterLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *FilterLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *FilterLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *FilterLogger) logEntry(e entry) error {
+  if this.keep != nil && !this.keep(e.mesg) {
+    return nil
+  }
+  return logEntry(this.inner, e)
+}
+
+func (this *FilterLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *FilterLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *FilterLogger) Messages() ([]string, error) {
+  return this.inner.Messages()
+}
+
+func (this *FilterLogger) Count() (int, error) {
+  return this.inner.Count()
+}
+
+func (this *FilterLogger) Tail(n int) ([]string, error) {
+  return this.inner.Tail(n)
+}
+
+func (this *FilterLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.inner.Search(pattern, useRegexp)
+}
+
+func (this *FilterLogger) ForEach(fn func(line string) error) error {
+  return this.inner.ForEach(fn)
+}
+
+func (this *FilterLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.inner.MessagesPage(offset, limit)
+}
+
+func (this *FilterLogger) Clear() error {
+  return this.inner.Clear()
+}
+
+func (this *FilterLogger) Sync() error {
+  return this.inner.Sync()
+}
+
+func (this *FilterLogger) Close() error {
+  return this.inner.Close()
+}
+
+
+// levelFilter forwards only the messages at or above a threshold.
+type levelFilter struct {
+  inner LoggerInterface
+  min Level
+}
+
+var _ LoggerInterface = (*levelFilter)(nil)
+
+// LevelFilter returns a logger that forwards to inner only the messages
+// logged at min or above, giving a threshold to loggers without one. Log
+// and LogCtx take the level the text names, as DetectLevel finds it, so
+// that pre-formatted lines are filtered too. Reads go straight to inner.
+func LevelFilter(inner LoggerInterface, min Level) LoggerInterface {
+  return &levelFilter{inner: inner, min: min}
+}
+
+func (this *levelFilter) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, DetectLevel(mesg), mesg))
+}
+
+func (this *levelFilter) Logf(level Level, format string, args ...interface{}) error {
+  if level < this.min {
+    return nil
+  }
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *levelFilter) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, DetectLevel(mesg), mesg))
+}
+
+func (this *levelFilter) logEntry(e entry) error {
+  if e.level < this.min {
+    return nil
+  }
+  return logEntry(this.inner, e)
+}
+
+func (this *levelFilter) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *levelFilter) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *levelFilter) Messages() ([]string, error) {
+  return this.inner.Messages()
+}
+
+func (this *levelFilter) Count() (int, error) {
+  return this.inner.Count()
+}
+
+func (this *levelFilter) Tail(n int) ([]string, error) {
+  return this.inner.Tail(n)
+}
+
+func (this *levelFilter) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.inner.Search(pattern, useRegexp)
+}
+
+func (this *levelFilter) ForEach(fn func(line string) error) error {
+  return this.inner.ForEach(fn)
+}
+
+func (this *levelFilter) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.inner.MessagesPage(offset, limit)
+}
+
+func (this *levelFilter) Clear() error {
+  return this.inner.Clear()
+}
+
+func (this *levelFilter) Sync() error {
+  return this.inner.Sync()
+}
+
+func (this *levelFilter) Close() error {
+  return this.inner.Close()
+}
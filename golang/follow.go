@@ -0,0 +1,198 @@
+package main
+
+import (
+  "bufio"
+  "context"
+  "errors"
+  "io"
+  "os"
+  "strings"
+  "time"
+)
+
+// followPollInterval is how often Follow looks for new lines.
+const followPollInterval = 100 * time.Millisecond
+
+// Follow streams the file like tail -f: it sends the lines already there,
+// then each line appended later, until ctx is done, when it closes the
+// channel. If the file shrinks, or is replaced as by rotation, it starts
+// again from the beginning. Lines are decoded as Messages decodes them,
+// into the message column of a CSV row or the "msg" of a JSON line, so
+// the two agree. Compressed files cannot be followed.
+func (this *LocalLogger) Follow(ctx context.Context) (<-chan string, error) {
+  if err := this.followable(); err != nil {
+    return nil, err
+  }
+  if err := this.beginRead(); err != nil {
+    return nil, err
+  }
+  filename := this.filename
+  file, err := os.Open(filename)
+  this.mu.RUnlock()
+  if err != nil {
+    return nil, err
+  }
+  ch := make(chan string)
+  go follow(ctx, filename, this.opts.recordSeparator(), this.followDecoder(), file, 0, ch)
+  return ch, nil
+}
+
+// followDecoder returns the message a followed line holds, as the reads
+// give it back, and false for a line they would not return: a CSV header
+// row, or a JSON line that does not decode, which is counted in
+// ParseErrors.
+func (this *LocalLogger) followDecoder() func(line string) (string, bool) {
+  switch {
+  case this.isCSV():
+    header := csvRow(csvHeader)
+    return func(line string) (string, bool) {
+      if line == header {
+        return "", false
+      }
+      return csvMessage(line), true
+    }
+  case this.isJSONL():
+    return func(line string) (string, bool) {
+      record, ok := decodeJSONL(line)
+      if !ok {
+        this.parseErrors.Add(1)
+        return "", false
+      }
+      return record.Msg, true
+    }
+  }
+  return func(line string) (string, bool) {
+    return line, true
+  }
+}
+
+// followable returns why the file cannot be followed, if it cannot.
+func (this *LocalLogger) followable() error {
+  switch {
+  case this.opts.gzip:
+    return errors.New("log: cannot follow a compressed LocalLogger")
+  case this.aead != nil:
+    return errors.New("log: cannot follow an encrypted LocalLogger")
+  case !this.isOS():
+    return errors.New("log: cannot follow a LocalLogger outside the OS filesystem")
+  }
+  return nil
+}
+
+// followTail is Follow sending only the lines appended from now on, and
+// returning the last n lines before them as Tail would, with none lost or
+// repeated between the two.
+func (this *LocalLogger) followTail(ctx context.Context, n int) ([]string, <-chan string, error) {
+  if err := this.followable(); err != nil {
+    return nil, nil, err
+  }
+  if err := this.beginRead(); err != nil {
+    return nil, nil, err
+  }
+  defer this.mu.RUnlock()
+  tail, err := this.tailLocked(n)
+  if err != nil {
+    return nil, nil, err
+  }
+  file, err := os.Open(this.filename)
+  if err != nil {
+    return nil, nil, err
+  }
+  offset, err := file.Seek(0, io.SeekEnd)
+  if err != nil {
+    file.Close()
+    return nil, nil, err
+  }
+  ch := make(chan string)
+  go follow(ctx, this.filename, this.opts.recordSeparator(), this.followDecoder(), file, offset, ch)
+  return tail, ch, nil
+}
+
+// follow sends the lines of file from offset, where it is positioned, on,
+// each as decode gives it back, leaving out those it refuses.
+func follow(ctx context.Context, filename string, sep byte, decode func(line string) (string, bool), file *os.File, offset int64, ch chan<- string) {
+  defer close(ch)
+  defer func() {
+    file.Close()
+  }()
+  ticker := time.NewTicker(followPollInterval)
+  defer ticker.Stop()
+
+  reader := bufio.NewReader(file)
+  var partial string // a last line whose separator has yet to be written
+  for {
+    for {
+      chunk, err := reader.ReadString(sep)
+      offset += int64(len(chunk))
+      if err != nil {
+        partial += chunk
+        break
+      }
+      line := partial + chunk[:len(chunk)-1]
+      if sep == '\n' {
+        line = strings.TrimSuffix(line, "\r")
+      }
+      partial = ""
+      line, ok := decode(line)
+      if !ok {
+        continue
+      }
+      select {
+      case ch <- line:
+      case <-ctx.Done():
+        return
+      }
+    }
+
+    select {
+    case <-ticker.C:
+    case <-ctx.Done():
+      return
+    }
+
+    info, err := os.Stat(filename)
+    if err != nil {
+      continue // moved away and not yet recreated
+    }
+    current, err := file.Stat()
+    if err != nil {
+      return
+    }
+    switch {
+    case !os.SameFile(info, current):
+      replacement, err := os.Open(filename)
+      if err != nil {
+        continue
+      }
+      file.Close()
+      file = replacement
+    case info.Size() < offset:
+      if _, err := file.Seek(0, io.SeekStart); err != nil {
+        return
+      }
+    default:
+      continue
+    }
+    reader.Reset(file)
+    offset = 0
+    partial = ""
+  }
+}
+
+// Collect gathers the lines from ch, such as the channel Follow returns,
+// until it is closed or ctx is done, and returns them in order. Stopping on
+// ctx as well means it also returns for a channel nobody closes.
+func Collect(ctx context.Context, ch <-chan string) []string {
+  var lines []string
+  for {
+    select {
+    case line, ok := <-ch:
+      if !ok {
+        return lines
+      }
+      lines = append(lines, line)
+    case <-ctx.Done():
+      return lines
+    }
+  }
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+  "context"
+  "path/filepath"
+  "reflect"
+  "testing"
+  "time"
+)
+
+// receive returns the next line from ch, failing t if none comes soon.
+func receive(t *testing.T, ch <-chan string) string {
+  t.Helper()
+  select {
+  case line, ok := <-ch:
+    if !ok {
+      t.Fatal("channel closed")
+    }
+    return line
+  case <-time.After(5 * time.Second):
+    t.Fatal("no line arrived")
+  }
+  return ""
+}
+
+func TestFollowNewLine(t *testing.T) {
+  l, err := NewLocalLogger(filepath.Join(t.TempDir(), "follow.log"), true)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Log("before")
+  ctx, cancel := context.WithCancel(context.Background())
+  defer cancel()
+  ch, err := l.Follow(ctx)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if got := receive(t, ch); got != "before" {
+    t.Errorf("first line %q, want %q", got, "before")
+  }
+  l.Log("after")
+  if got := receive(t, ch); got != "after" {
+    t.Errorf("appended line %q, want %q", got, "after")
+  }
+  cancel()
+  for range(ch) {
+  }
+}
+
+func TestFollowTruncated(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "follow.log")
+  l, err := NewLocalLogger(path, true)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Log("a long first line to shrink from")
+  ctx, cancel := context.WithCancel(context.Background())
+  defer cancel()
+  ch, err := l.Follow(ctx)
+  if err != nil {
+    t.Fatal(err)
+  }
+  receive(t, ch)
+  if err := l.Clear(); err != nil {
+    t.Fatal(err)
+  }
+  l.Log("fresh")
+  if got := receive(t, ch); got != "fresh" {
+    t.Errorf("after truncation got %q, want %q", got, "fresh")
+  }
+}
+
+// TestFollowDecodes checks that followed lines come back as Messages gives
+// them, without the CSV header or the JSON wrapping.
+func TestFollowDecodes(t *testing.T) {
+  for _, tc := range([]struct {
+    name string
+    opts []Option
+  }{
+    {"csv", []Option{WithFormatter(CSVFormatter{}), WithTimestamp("")}},
+    {"jsonl", []Option{WithJSONL()}},
+  }) {
+    t.Run(tc.name, func(t *testing.T) {
+      l, err := NewLocalLogger(filepath.Join(t.TempDir(), "follow.log"), true, tc.opts...)
+      if err != nil {
+        t.Fatal(err)
+      }
+      defer l.Close()
+      l.Log(`old, "quoted"`)
+      ctx, cancel := context.WithCancel(context.Background())
+      defer cancel()
+      ch, err := l.Follow(ctx)
+      if err != nil {
+        t.Fatal(err)
+      }
+      if got := receive(t, ch); got != `old, "quoted"` {
+        t.Errorf("existing line %q", got)
+      }
+      l.Log("new")
+      if got := receive(t, ch); got != "new" {
+        t.Errorf("appended line %q", got)
+      }
+    })
+  }
+}
+
+func TestFollowRefusesGzip(t *testing.T) {
+  l, err := NewLocalLogger(filepath.Join(t.TempDir(), "follow.log.gz"), true, WithGzip())
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  if _, err := l.Follow(context.Background()); err == nil {
+    t.Error("Follow of a compressed logger succeeded")
+  }
+}
+
+func TestCollectUntilClosed(t *testing.T) {
+  ch := make(chan string, 3)
+  ch <- "a"
+  ch <- "b"
+  ch <- "c"
+  close(ch)
+  if got, want := Collect(context.Background(), ch), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+    t.Errorf("Collect = %q, want %q", got, want)
+  }
+}
+
+func TestCollectUntilCancelled(t *testing.T) {
+  ctx, cancel := context.WithCancel(context.Background())
+  ch := make(chan string)
+  go func() {
+    ch <- "a"
+    ch <- "b"
+    cancel() // ch is never closed
+  }()
+  if got, want := Collect(ctx, ch), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+    t.Errorf("Collect = %q, want %q", got, want)
+  }
+}
+
+func TestCollectFollow(t *testing.T) {
+  l, err := NewLocalLogger(filepath.Join(t.TempDir(), "follow.log"), true)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Log("one")
+  ctx, cancel := context.WithCancel(context.Background())
+  ch, err := l.Follow(ctx)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if got := receive(t, ch); got != "one" {
+    t.Fatalf("first line %q, want %q", got, "one")
+  }
+  l.Log("two")
+  l.Log("three")
+  time.AfterFunc(500*time.Millisecond, cancel)
+  if got, want := Collect(ctx, ch), []string{"two", "three"}; !reflect.DeepEqual(got, want) {
+    t.Errorf("Collect = %q, want %q", got, want)
+  }
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "fmt"
+  "time"
+)
+
+// Formatter renders a message into the line that a logger stores or writes.
+// The time is zero unless the logger was built WithTimestamp.
+type Formatter interface {
+  Format(level Level, msg string, t time.Time) string
+}
+
+// FieldsFormatter is implemented by formatters that render Fields
+// themselves. Other formatters get the fields appended to the message.
+type FieldsFormatter interface {
+  Formatter
+  FormatFields(level Level, msg string, t time.Time, fields Fields) string
+}
+
+// PlainFormatter renders "<time> <LEVEL> msg", leaving out the time when it
+// is zero and the level unless ShowLevel is set. It is the default.
+type PlainFormatter struct {
+  Layout string // defaults to time.RFC3339Nano
+  ShowLevel bool
+}
+
+func (this PlainFormatter) Format(level Level, msg string, t time.Time) string {
+  line := msg
+  if this.ShowLevel {
+    line = level.String() + " " + line
+  }
+  if !t.IsZero() {
+    line = t.Format(layoutOrDefault(this.Layout)) + " " + line
+  }
+  return line
+}
+
+// FormatFields appends the fields as key=value pairs.
+func (this PlainFormatter) FormatFields(level Level, msg string, t time.Time, fields Fields) string {
+  return this.Format(level, appendFields(msg, fields), t)
+}
+
+// JSONFormatter renders {"ts":...,"level":...,"msg":...}, leaving out "ts"
+// when the time is zero.
+type JSONFormatter struct {
+  Layout string // defaults to time.RFC3339Nano
+}
+
+type jsonLine struct {
+  Ts string `json:"ts,omitempty"`
+  Level string `json:"level"`
+  Msg string `json:"msg"`
+}
+
+func (this JSONFormatter) Format(level Level, msg string, t time.Time) string {
+  record := jsonLine{Level: level.String(), Msg: msg}
+  if !t.IsZero() {
+    record.Ts = t.Format(layoutOrDefault(this.Layout))
+  }
+  line, err := json.Marshal(record)
+  if err != nil {
+    // Strings always marshal, so this cannot happen.
+    panic(err)
+  }
+  return string(line)
+}
+
+// FormatFields adds the fields as top-level keys. A field that would clash
+// with "ts", "level" or "msg" is written as "fields.<key>" instead.
+func (this JSONFormatter) FormatFields(level Level, msg string, t time.Time, fields Fields) string {
+  line := []byte(this.Format(level, msg, t))
+  if len(fields) == 0 {
+    return string(line)
+  }
+  var b bytes.Buffer
+  b.Write(line[:len(line)-1])
+  for _, k := range(fields.sortedKeys()) {
+    v, err := json.Marshal(fields[k])
+    if err != nil {
+      v, _ = json.Marshal(fmt.Sprint(fields[k]))
+    }
+    if k == "ts" || k == "level" || k == "msg" {
+      k = "fields." + k
+    }
+    key, _ := json.Marshal(k)
+    b.WriteByte(',')
+    b.Write(key)
+    b.WriteByte(':')
+    b.Write(v)
+  }
+  b.WriteByte('}')
+  return b.String()
+}
+
+func layoutOrDefault(layout string) string {
+  if layout == "" {
+    return time.RFC3339Nano
+  }
+  return layout
+}
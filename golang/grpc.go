@@ -0,0 +1,203 @@
+//go:build grpc
+
+package main
+
+import (
+  "context"
+  "fmt"
+  "sync"
+
+  "google.golang.org/grpc"
+  "google.golang.org/protobuf/types/known/emptypb"
+  "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// GRPCIngestMethod is the LogIngest.Ingest RPC of logingest.proto.
+const GRPCIngestMethod = "/logingest.LogIngest/Ingest"
+
+var grpcIngestStream = &grpc.StreamDesc{StreamName: "Ingest", ClientStreams: true}
+
+// GRPCLogger sends each message as one message of a client-streaming RPC,
+// by default GRPCIngestMethod. Messages returns the lines sent so far.
+type GRPCLogger struct {
+  mu sync.Mutex
+  conn *grpc.ClientConn
+  method string
+  opts options
+  minLevel Level
+  ctx context.Context // the streams' context, canceled by Close
+  cancel context.CancelFunc
+  stream grpc.ClientStream
+  sent []string
+  closed bool
+}
+
+var _ LoggerInterface = (*GRPCLogger)(nil)
+
+// NewGRPCLogger opens a stream to method over conn, which stays the
+// caller's to close; an empty method means GRPCIngestMethod.
+func NewGRPCLogger(conn *grpc.ClientConn, method string, opts ...Option) (*GRPCLogger, error) {
+  if method == "" {
+    method = GRPCIngestMethod
+  }
+  this := &GRPCLogger{conn: conn, method: method, opts: newOptions(opts)}
+  this.ctx, this.cancel = context.WithCancel(context.Background())
+  if err := this.open(); err != nil {
+    this.cancel()
+    return nil, err
+  }
+  return this, nil
+}
+
+// open starts a new stream. The caller must hold mu, or own this alone.
+func (this *GRPCLogger) open() error {
+  stream, err := this.conn.NewStream(this.ctx, grpcIngestStream, this.method)
+  if err != nil {
+    return err
+  }
+  this.stream = stream
+  return nil
+}
+
+func (this *GRPCLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *GRPCLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+// LogCtx checks ctx before sending only: the stream has a context of its
+// own, so a send stalled by flow control does not stop for ctx.
+func (this *GRPCLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *GRPCLogger) logEntry(e entry) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.closed {
+    return ErrClosed
+  }
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  if err := this.opts.validateUTF8(&e); err != nil {
+    return err
+  }
+  if e.level < this.minLevel {
+    return nil
+  }
+  line := this.opts.render(e)
+  if err := this.send(line); err != nil {
+    return this.opts.handle(writeFailed(err))
+  }
+  this.sent = append(this.sent, line)
+  this.opts.notify(e.level, line)
+  return nil
+}
+
+// send sends line, opening a new stream and trying once more if the
+// current one has failed. The caller must hold mu.
+func (this *GRPCLogger) send(line string) error {
+  if this.stream != nil {
+    if err := this.stream.SendMsg(wrapperspb.String(line)); err == nil {
+      return nil
+    }
+    this.stream.CloseSend()
+    this.stream = nil
+  }
+  if err := this.open(); err != nil {
+    return err
+  }
+  if err := this.stream.SendMsg(wrapperspb.String(line)); err != nil {
+    this.stream.CloseSend()
+    this.stream = nil
+    return err
+  }
+  return nil
+}
+
+// SetMinLevel drops subsequent messages below level.
+func (this *GRPCLogger) SetMinLevel(level Level) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.minLevel = level
+}
+
+func (this *GRPCLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *GRPCLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *GRPCLogger) Messages() ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return append([]string(nil), this.sent...), nil
+}
+
+func (this *GRPCLogger) Count() (int, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return len(this.sent), nil
+}
+
+func (this *GRPCLogger) Tail(n int) ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return tailOf(this.sent, n), nil
+}
+
+func (this *GRPCLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return searchIn(this.sent, pattern, useRegexp)
+}
+
+func (this *GRPCLogger) ForEach(fn func(line string) error) error {
+  messages, _ := this.Messages()
+  return forEachIn(messages, fn)
+}
+
+func (this *GRPCLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return pageOf(this.sent, offset, limit)
+}
+
+// Clear forgets the lines sent so far; the service keeps its copy.
+func (this *GRPCLogger) Clear() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.sent = nil
+  return nil
+}
+
+// Sync is a no-op: every Log has already been handed to the stream.
+func (this *GRPCLogger) Sync() error {
+  return nil
+}
+
+// Close ends the stream and waits for the service's reply, as a generated
+// client's CloseAndRecv would, reporting any error it ended with.
+func (this *GRPCLogger) Close() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.closed {
+    return nil
+  }
+  this.closed = true
+  defer this.cancel()
+  if this.stream == nil {
+    return nil
+  }
+  stream := this.stream
+  this.stream = nil
+  if err := stream.CloseSend(); err != nil {
+    return err
+  }
+  return stream.RecvMsg(new(emptypb.Empty))
+}
@@ -0,0 +1,143 @@
+//go:build grpc
+
+package main
+
+import (
+  "context"
+  "errors"
+  "io"
+  "net"
+  "reflect"
+  "sync"
+  "testing"
+  "time"
+
+  "google.golang.org/grpc"
+  "google.golang.org/grpc/credentials/insecure"
+  "google.golang.org/grpc/test/bufconn"
+  "google.golang.org/protobuf/types/known/emptypb"
+  "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ingestServer is an in-process LogIngest service recording each stream's
+// lines. With failFirst set, the first stream fails after one line.
+type ingestServer struct {
+  mu sync.Mutex
+  streams [][]string
+  failFirst bool
+}
+
+func (this *ingestServer) ingest(srv interface{}, stream grpc.ServerStream) error {
+  this.mu.Lock()
+  n := len(this.streams)
+  this.streams = append(this.streams, nil)
+  this.mu.Unlock()
+  for {
+    line := new(wrapperspb.StringValue)
+    if err := stream.RecvMsg(line); err == io.EOF {
+      return stream.SendMsg(new(emptypb.Empty))
+    } else if err != nil {
+      return err
+    }
+    this.mu.Lock()
+    this.streams[n] = append(this.streams[n], line.Value)
+    this.mu.Unlock()
+    if n == 0 && this.failFirst {
+      return errors.New("ingest restarting")
+    }
+  }
+}
+
+func (this *ingestServer) received() [][]string {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  var streams [][]string
+  for _, lines := range(this.streams) {
+    streams = append(streams, append([]string(nil), lines...))
+  }
+  return streams
+}
+
+// startIngest serves an ingestServer over a bufconn and returns a client
+// connection to it, both stopped when t ends.
+func startIngest(t *testing.T, ingest *ingestServer) *grpc.ClientConn {
+  t.Helper()
+  ln := bufconn.Listen(1 << 20)
+  server := grpc.NewServer()
+  server.RegisterService(&grpc.ServiceDesc{
+    ServiceName: "logingest.LogIngest",
+    HandlerType: (*interface{})(nil),
+    Streams: []grpc.StreamDesc{{StreamName: "Ingest", Handler: ingest.ingest, ClientStreams: true}},
+  }, ingest)
+  go server.Serve(ln)
+  conn, err := grpc.NewClient("passthrough:///bufconn",
+    grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+      return ln.DialContext(ctx)
+    }),
+    grpc.WithTransportCredentials(insecure.NewCredentials()))
+  if err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() {
+    conn.Close()
+    server.Stop()
+  })
+  return conn
+}
+
+func TestGRPCStreamsMessages(t *testing.T) {
+  ingest := &ingestServer{}
+  l, err := NewGRPCLogger(startIngest(t, ingest), "")
+  if err != nil {
+    t.Fatal(err)
+  }
+  want := []string{"one", "two", "three"}
+  for _, mesg := range(want) {
+    if err := l.Log(mesg); err != nil {
+      t.Fatalf("Log(%q): %v", mesg, err)
+    }
+  }
+  if err := l.Close(); err != nil {
+    t.Fatalf("Close: %v", err)
+  }
+  if got := ingest.received(); len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+    t.Errorf("server received %q, want one stream of %q", got, want)
+  }
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages = %q, want %q", got, want)
+  }
+  if err := l.Log("late"); !errors.Is(err, ErrClosed) {
+    t.Errorf("Log after Close = %v, want ErrClosed", err)
+  }
+}
+
+func TestGRPCReconnectsAfterStreamError(t *testing.T) {
+  ingest := &ingestServer{failFirst: true}
+  l, err := NewGRPCLogger(startIngest(t, ingest), "")
+  if err != nil {
+    t.Fatal(err)
+  }
+  l.Log("first")
+  // Messages sent before the client hears the stream has failed are lost
+  // with it; keep logging until one arrives on a new stream.
+  deadline := time.Now().Add(5 * time.Second)
+  for len(ingest.received()) < 2 || len(ingest.received()[1]) == 0 {
+    if time.Now().After(deadline) {
+      t.Fatalf("no second stream; server received %q", ingest.received())
+    }
+    l.Log("retry")
+    time.Sleep(10 * time.Millisecond)
+  }
+  l.Log("resumed")
+  if err := l.Close(); err != nil {
+    t.Fatalf("Close: %v", err)
+  }
+  streams := ingest.received()
+  if !reflect.DeepEqual(streams[0], []string{"first"}) {
+    t.Errorf("first stream received %q", streams[0])
+  }
+  last := streams[len(streams)-1]
+  if last[len(last)-1] != "resumed" {
+    t.Errorf("last stream received %q, want it to end with the message after reconnecting", last)
+  }
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+  "compress/gzip"
+  "errors"
+  "io"
+)
+
+// WithGzip makes a LocalLogger compress its file. Filenames ending in ".gz"
+// get this without asking. Sync and Close must be called for the stream to
+// be complete on disk; readers of this logger cope with it being unfinished.
+func WithGzip() Option {
+  return func(o *options) {
+    o.gzip = true
+  }
+}
+
+// openRead opens the named file for reading, decompressing and decrypting
+// it if need be.
+func (this *LocalLogger) openRead(filename string) (io.ReadCloser, error) {
+  r, err := this.openDecompressed(filename)
+  if err != nil || this.aead == nil {
+    return r, err
+  }
+  return &decryptReader{r: r, aead: this.aead}, nil
+}
+
+func (this *LocalLogger) openDecompressed(filename string) (io.ReadCloser, error) {
+  file, err := this.opts.fileSystem().Open(filename)
+  if err != nil || !this.opts.gzip {
+    return file, err
+  }
+  zr, err := gzip.NewReader(file)
+  if err == io.EOF {
+    return file, nil // nothing written yet
+  }
+  if err != nil {
+    file.Close()
+    return nil, err
+  }
+  return &gzipFileReader{zr: zr, file: file}, nil
+}
+
+// gzipFileReader reads a gzip file that may still be being written: the
+// stream ends at the last flush, without a trailer, which it treats as EOF.
+type gzipFileReader struct {
+  zr *gzip.Reader
+  file File
+}
+
+func (this *gzipFileReader) Read(p []byte) (int, error) {
+  n, err := this.zr.Read(p)
+  if errors.Is(err, io.ErrUnexpectedEOF) {
+    err = io.EOF
+  }
+  return n, err
+}
+
+func (this *gzipFileReader) Close() error {
+  return errors.Join(this.zr.Close(), this.file.Close())
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+  "encoding/json"
+  "net/http"
+  "strconv"
+)
+
+// Handler serves l's messages for live debugging. GET returns them as a
+// JSON array; ?level=warn keeps those at that level or above, as
+// DetectLevel reads it from each line, and ?tail=N keeps the last N of
+// those. HEAD returns just the count, in X-Log-Count.
+func Handler(l LoggerInterface) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    switch r.Method {
+    case http.MethodHead:
+      n, err := l.Count()
+      if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+      }
+      w.Header().Set("Content-Type", "application/json")
+      w.Header().Set("X-Log-Count", strconv.Itoa(n))
+    case http.MethodGet:
+      serveMessages(w, r, l)
+    default:
+      w.Header().Set("Allow", "GET, HEAD")
+      http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+    }
+  })
+}
+
+func serveMessages(w http.ResponseWriter, r *http.Request, l LoggerInterface) {
+  query := r.URL.Query()
+  tail := -1
+  if s := query.Get("tail"); s != "" {
+    n, err := strconv.Atoi(s)
+    if err != nil || n < 0 {
+      http.Error(w, "tail must be a non-negative integer", http.StatusBadRequest)
+      return
+    }
+    tail = n
+  }
+  min := LevelDebug
+  if s := query.Get("level"); s != "" {
+    level, err := ParseLevel(s)
+    if err != nil {
+      http.Error(w, err.Error(), http.StatusBadRequest)
+      return
+    }
+    min = level
+  }
+
+  messages, err := l.Messages()
+  if err != nil {
+    http.Error(w, err.Error(), http.StatusInternalServerError)
+    return
+  }
+  if min > LevelDebug {
+    kept := messages[:0]
+    for _, mesg := range(messages) {
+      if DetectLevel(mesg) >= min {
+        kept = append(kept, mesg)
+      }
+    }
+    messages = kept
+  }
+  if tail >= 0 {
+    messages = tailOf(messages, tail)
+  }
+  if messages == nil {
+    messages = []string{}
+  }
+  w.Header().Set("Content-Type", "application/json")
+  json.NewEncoder(w).Encode(messages)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+  "encoding/json"
+  "errors"
+  "net/http"
+  "net/http/httptest"
+  "reflect"
+  "strings"
+  "testing"
+)
+
+func getMessages(t *testing.T, h http.Handler, target string) []string {
+  t.Helper()
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+  if rec.Code != http.StatusOK {
+    t.Fatalf("GET %s: status %d: %s", target, rec.Code, rec.Body)
+  }
+  if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+    t.Errorf("GET %s: Content-Type %q", target, ct)
+  }
+  var messages []string
+  if err := json.Unmarshal(rec.Body.Bytes(), &messages); err != nil {
+    t.Fatalf("GET %s: %v", target, err)
+  }
+  return messages
+}
+
+func TestHandlerQuery(t *testing.T) {
+  l := NewInMemoryLogger()
+  for _, mesg := range([]string{"INFO: up", "WARN: slow", "ERROR: down", "INFO: again", "WARN: slow again"}) {
+    l.Log(mesg)
+  }
+  h := Handler(l)
+  tests := []struct {
+    target string
+    want []string
+  }{
+    {"/", []string{"INFO: up", "WARN: slow", "ERROR: down", "INFO: again", "WARN: slow again"}},
+    {"/?tail=2", []string{"INFO: again", "WARN: slow again"}},
+    {"/?level=warn", []string{"WARN: slow", "ERROR: down", "WARN: slow again"}},
+    {"/?level=warn&tail=2", []string{"ERROR: down", "WARN: slow again"}},
+    {"/?level=error&tail=0", []string{}},
+  }
+  for _, test := range(tests) {
+    if got := getMessages(t, h, test.target); !reflect.DeepEqual(got, test.want) {
+      t.Errorf("GET %s = %q, want %q", test.target, got, test.want)
+    }
+  }
+}
+
+func TestHandlerHead(t *testing.T) {
+  l := NewInMemoryLogger()
+  l.Log("one")
+  l.Log("two")
+  rec := httptest.NewRecorder()
+  Handler(l).ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/", nil))
+  if got := rec.Header().Get("X-Log-Count"); rec.Code != http.StatusOK || got != "2" {
+    t.Errorf("HEAD: status %d, X-Log-Count %q; want 200 and 2", rec.Code, got)
+  }
+}
+
+func TestHandlerBadQuery(t *testing.T) {
+  h := Handler(NewInMemoryLogger())
+  for _, target := range([]string{"/?tail=-1", "/?tail=x", "/?level=loud"}) {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+    if rec.Code != http.StatusBadRequest {
+      t.Errorf("GET %s: status %d, want 400", target, rec.Code)
+    }
+  }
+}
+
+// failingMessages is an InMemoryLogger whose Messages fails.
+type failingMessages struct {
+  *InMemoryLogger
+}
+
+func (this failingMessages) Messages() ([]string, error) {
+  return nil, errors.New("disk on fire")
+}
+
+func TestHandlerMessagesFails(t *testing.T) {
+  rec := httptest.NewRecorder()
+  Handler(failingMessages{NewInMemoryLogger()}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+  if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "disk on fire") {
+    t.Errorf("status %d, body %q; want 500 with the error", rec.Code, rec.Body)
+  }
+}
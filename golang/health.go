@@ -0,0 +1,49 @@
+package main
+
+import (
+  "sync/atomic"
+)
+
+// HealthReporter is implemented by the loggers that pass messages on to
+// others and may not return their failures to the caller, such as an
+// AsyncLogger writing in the background, so that a health check can poll
+// them to tell whether a sink is degraded.
+type HealthReporter interface {
+  LastError() error
+  FailedWrites() uint64
+  ResetErrors()
+}
+
+var (
+  _ HealthReporter = (*AsyncLogger)(nil)
+  _ HealthReporter = (*MultiLogger)(nil)
+  _ HealthReporter = (*FallbackLogger)(nil)
+)
+
+// writeFailures records the failed writes to a wrapped logger. It is safe
+// for concurrent use without a lock.
+type writeFailures struct {
+  n atomic.Uint64
+  last atomic.Pointer[error]
+}
+
+// add records err if it is non-nil.
+func (this *writeFailures) add(err error) {
+  if err == nil {
+    return
+  }
+  this.last.Store(&err)
+  this.n.Add(1)
+}
+
+func (this *writeFailures) lastError() error {
+  if err := this.last.Load(); err != nil {
+    return *err
+  }
+  return nil
+}
+
+func (this *writeFailures) reset() {
+  this.last.Store(nil)
+  this.n.Store(0)
+}
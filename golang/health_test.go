@@ -0,0 +1,82 @@
+package main
+
+import (
+  "fmt"
+  "sync"
+  "testing"
+)
+
+// failingEach is an InMemoryLogger that fails every message with an error
+// naming how many it has failed.
+type failingEach struct {
+  *InMemoryLogger
+  mu sync.Mutex
+  n int
+}
+
+func (this *failingEach) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *failingEach) logEntry(e entry) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.n++
+  return fmt.Errorf("failure %d", this.n)
+}
+
+func TestHealthReporters(t *testing.T) {
+  sinks := map[string]func(inner LoggerInterface) (LoggerInterface, func()){
+    "AsyncLogger": func(inner LoggerInterface) (LoggerInterface, func()) {
+      l := NewAsyncLogger(inner)
+      return l, func() { l.Flush() }
+    },
+    "MultiLogger": func(inner LoggerInterface) (LoggerInterface, func()) {
+      return NewMultiLogger(NewInMemoryLogger(), inner), func() {}
+    },
+    "FallbackLogger": func(inner LoggerInterface) (LoggerInterface, func()) {
+      return NewFallbackLogger(inner, NewInMemoryLogger()), func() {}
+    },
+  }
+  for name, sink := range(sinks) {
+    l, settle := sink(&failingEach{InMemoryLogger: NewInMemoryLogger()})
+    health := l.(HealthReporter)
+    if health.LastError() != nil || health.FailedWrites() != 0 {
+      t.Errorf("%s: LastError = %v, FailedWrites = %d before any write", name, health.LastError(), health.FailedWrites())
+    }
+    for i := 0; i < 3; i++ {
+      l.Log("x")
+    }
+    settle()
+    if got := health.FailedWrites(); got != 3 {
+      t.Errorf("%s: FailedWrites = %d, want 3", name, got)
+    }
+    if err := health.LastError(); err == nil || err.Error() != "failure 3" {
+      t.Errorf("%s: LastError = %v, want the most recent failure", name, err)
+    }
+    health.ResetErrors()
+    if health.LastError() != nil || health.FailedWrites() != 0 {
+      t.Errorf("%s: LastError = %v, FailedWrites = %d after ResetErrors", name, health.LastError(), health.FailedWrites())
+    }
+    l.Close()
+  }
+}
+
+func TestHealthReporterConcurrent(t *testing.T) {
+  l := NewMultiLogger(&failingEach{InMemoryLogger: NewInMemoryLogger()})
+  var wg sync.WaitGroup
+  for g := 0; g < 8; g++ {
+    wg.Add(1)
+    go func() {
+      defer wg.Done()
+      for i := 0; i < 100; i++ {
+        l.Log("x")
+        l.LastError()
+      }
+    }()
+  }
+  wg.Wait()
+  if got := l.FailedWrites(); got != 800 {
+    t.Errorf("FailedWrites = %d, want 800", got)
+  }
+}
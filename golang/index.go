@@ -0,0 +1,115 @@
+package main
+
+import (
+  "sort"
+  "time"
+)
+
+// timeIndex maps time buckets to the messages stored in them, so that a
+// time range query scans only the buckets it covers. Messages are numbered
+// from the first one stored, so that eviction does not renumber them.
+type timeIndex struct {
+  bucket time.Duration
+  marks []indexMark // one per bucket holding messages, in order
+  base int // number of the oldest stored message
+  next int // number the next message will get
+  last time.Time
+  ordered bool // false once the clock has gone backwards
+}
+
+// indexMark records the first message stored in a bucket.
+type indexMark struct {
+  bucket int64
+  seq int
+}
+
+// NewIndexedInMemoryLogger returns an InMemoryLogger that also indexes its
+// messages by the time they were stored, in buckets of the given width,
+// for QueryTimeRange. The index costs memory for each bucket used.
+func NewIndexedInMemoryLogger(bucket time.Duration, opts ...Option) *InMemoryLogger {
+  if bucket <= 0 {
+    bucket = time.Second
+  }
+  return &InMemoryLogger{opts: newOptions(opts), index: &timeIndex{bucket: bucket, ordered: true}}
+}
+
+func (this *timeIndex) bucketOf(t time.Time) int64 {
+  return t.UnixNano() / int64(this.bucket)
+}
+
+func (this *timeIndex) add(t time.Time) {
+  if t.Before(this.last) {
+    this.ordered = false
+  }
+  this.last = t
+  b := this.bucketOf(t)
+  if n := len(this.marks); n == 0 || b > this.marks[n-1].bucket {
+    this.marks = append(this.marks, indexMark{bucket: b, seq: this.next})
+  }
+  this.next++
+}
+
+// evict forgets the oldest n messages.
+func (this *timeIndex) evict(n int) {
+  this.base += n
+  drop := 0
+  for drop < len(this.marks) && this.markEnd(drop) <= this.base {
+    drop++
+  }
+  this.marks = this.marks[drop:]
+  if len(this.marks) > 0 && this.marks[0].seq < this.base {
+    this.marks[0].seq = this.base
+  }
+  if this.base == this.next {
+    this.ordered = true
+    this.last = time.Time{}
+  }
+}
+
+// markEnd is the number after the last message in the i'th mark's bucket.
+func (this *timeIndex) markEnd(i int) int {
+  if i+1 < len(this.marks) {
+    return this.marks[i+1].seq
+  }
+  return this.next
+}
+
+// span returns the stored positions that may hold messages from from to
+// to, or false if the index cannot tell.
+func (this *timeIndex) span(from, to time.Time) (int, int, bool) {
+  if !this.ordered {
+    return 0, 0, false
+  }
+  lo, hi := this.bucketOf(from), this.bucketOf(to)
+  first := sort.Search(len(this.marks), func(i int) bool {
+    return this.marks[i].bucket >= lo
+  })
+  end := sort.Search(len(this.marks), func(i int) bool {
+    return this.marks[i].bucket > hi
+  })
+  if first >= end {
+    return 0, 0, true
+  }
+  return this.marks[first].seq - this.base, this.markEnd(end-1) - this.base, true
+}
+
+// QueryTimeRange returns the messages stored from from to to, both
+// included. On a logger built with NewIndexedInMemoryLogger it scans only
+// the buckets in range; otherwise it scans every message.
+func (this *InMemoryLogger) QueryTimeRange(from, to time.Time) ([]string, error) {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  start, end := 0, len(this.messages)
+  if this.index != nil {
+    if s, e, ok := this.index.span(from, to); ok {
+      start, end = s, e
+    }
+  }
+  var found []string
+  for i := start; i < end; i++ {
+    if t := this.times[i]; !t.Before(from) && !t.After(to) {
+      found = append(found, this.messages[i])
+    }
+  }
+  return found, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+  "reflect"
+  "testing"
+  "time"
+)
+
+var indexStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fillTimed logs n messages to a logger built by newLogger on clock, one
+// every millisecond from indexStart.
+func fillTimed(n int, newLogger func(opts ...Option) *InMemoryLogger) *InMemoryLogger {
+  clock := NewManualClock(indexStart)
+  l := newLogger(WithClock(clock))
+  for i := 0; i < n; i++ {
+    l.Log("message")
+    clock.Advance(time.Millisecond)
+  }
+  return l
+}
+
+func indexed(opts ...Option) *InMemoryLogger {
+  return NewIndexedInMemoryLogger(time.Second, opts...)
+}
+
+func TestQueryTimeRangeAgrees(t *testing.T) {
+  clock := NewManualClock(indexStart)
+  plain := NewInMemoryLogger(WithClock(clock))
+  index := NewIndexedInMemoryLogger(time.Second, WithClock(clock))
+  for i := 0; i < 5000; i++ {
+    plain.Logf(LevelInfo, "message %d", i)
+    index.Logf(LevelInfo, "message %d", i)
+    clock.Advance(7 * time.Millisecond)
+  }
+  ranges := [][2]time.Duration{
+    {0, time.Second},
+    {1500 * time.Millisecond, 2500 * time.Millisecond},
+    {-time.Hour, time.Hour},
+    {time.Hour, 2 * time.Hour},
+    {34993 * time.Millisecond, 34993 * time.Millisecond},
+  }
+  for _, r := range(ranges) {
+    from, to := indexStart.Add(r[0]), indexStart.Add(r[1])
+    want, _ := plain.QueryTimeRange(from, to)
+    got, _ := index.QueryTimeRange(from, to)
+    if !reflect.DeepEqual(got, want) {
+      t.Errorf("QueryTimeRange over %v: indexed found %d, linear scan %d", r, len(got), len(want))
+    }
+  }
+  if got, _ := index.QueryTimeRange(indexStart.Add(34993*time.Millisecond), indexStart.Add(34993*time.Millisecond)); !reflect.DeepEqual(got, []string{"message 4999"}) {
+    t.Errorf("query for the last instant = %q", got)
+  }
+}
+
+func benchmarkQueryTimeRange(b *testing.B, newLogger func(opts ...Option) *InMemoryLogger) {
+  l := fillTimed(1000000, newLogger)
+  from := indexStart.Add(500 * time.Second)
+  to := from.Add(time.Second)
+  b.ResetTimer()
+  for i := 0; i < b.N; i++ {
+    if found, _ := l.QueryTimeRange(from, to); len(found) != 1001 {
+      b.Fatalf("found %d messages, want 1001", len(found))
+    }
+  }
+}
+
+func BenchmarkQueryTimeRangeIndexed(b *testing.B) {
+  benchmarkQueryTimeRange(b, indexed)
+}
+
+func BenchmarkQueryTimeRangeLinear(b *testing.B) {
+  benchmarkQueryTimeRange(b, NewInMemoryLogger)
+}
@@ -2,103 +2,1232 @@ package main
 
 import (
   "bufio"
+  "bytes"
+  "compress/gzip"
+  "crypto/cipher"
+  "context"
+  "errors"
+  "flag"
   "fmt"
+  "io"
   "log"
   "os"
+  "os/signal"
+  "path/filepath"
   "reflect"
+  "sort"
+  "strings"
+  "sync"
+  "sync/atomic"
+  "syscall"
+  "time"
+  "unsafe"
 )
 
-// A logging interface with two APIs.
+// A logging interface.
+// Log writes at LevelInfo; Logf writes at the given level. LogCtx is Log
+// giving up with ctx.Err() once ctx is done.
 type LoggerInterface interface {
-  Log(string)
-  Messages() []string
+  Log(string) error
+  Logf(level Level, format string, args ...interface{}) error
+  LogCtx(ctx context.Context, mesg string) error
+  WithFields(Fields) LoggerInterface
+  WithPrefix(prefix string) LoggerInterface
+  Messages() ([]string, error)
+  Count() (int, error)
+  Tail(n int) ([]string, error)
+  Search(pattern string, useRegexp bool) ([]string, error)
+  ForEach(fn func(line string) error) error
+  MessagesPage(offset, limit int) ([]string, bool, error)
+  Clear() error
+  Sync() error
+  Close() error
 }
 
-func Messages(li LoggerInterface) []string {
+func Log(li LoggerInterface, mesg string) error {
+  return logEntry(li, newEntry(nil, LevelInfo, mesg))
+}
+
+func Logf(li LoggerInterface, level Level, format string, args ...interface{}) error {
+  return logEntry(li, newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func LogCtx(ctx context.Context, li LoggerInterface, mesg string) error {
+  return logEntry(li, newEntry(ctx, LevelInfo, mesg))
+}
+
+func Messages(li LoggerInterface) ([]string, error) {
   return li.Messages()
 }
 
+// Replay logs each of src's messages into dst and returns how many made it.
+// It stops at the first failed Log, returning the count so far with the error.
+func Replay(dst, src LoggerInterface) (int, error) {
+  messages, err := src.Messages()
+  if err != nil {
+    return 0, err
+  }
+  for i, mesg := range(messages) {
+    if err := dst.Log(mesg); err != nil {
+      return i, err
+    }
+  }
+  return len(messages), nil
+}
+
 
 // InMemoryLogger saves the log messages in memory.
+// It is safe for concurrent use.
 type InMemoryLogger struct {
-  LoggerInterface
+  mu sync.RWMutex
+  opts options
+  minLevel Level
+  maxEntries int
+  maxBytes int
+  bytes int // total length of messages
+  highWater int // peak of Bytes
+  retention time.Duration
   messages []string
+  times []time.Time // when each message was stored
+  index *timeIndex // set by NewIndexedInMemoryLogger
+  counts levelCounts
+  rates *rateCounter // allocated by the first Log
+  checkpoint *checkpointer // allocated by the first store WithCheckpoint
+  interned *interner // allocated by the first store WithInterning
+  closed bool
+}
+
+var _ LoggerInterface = (*InMemoryLogger)(nil)
+
+// NewInMemoryLogger returns an InMemoryLogger; the zero value is also usable.
+func NewInMemoryLogger(opts ...Option) *InMemoryLogger {
+  return &InMemoryLogger{opts: newOptions(opts)}
+}
+
+// NewInMemoryLoggerWithCapacity returns an InMemoryLogger with room for n
+// messages set aside up front, so that logging them never grows the
+// history. It is purely a performance hint: the logger behaves exactly as
+// one from NewInMemoryLogger, growing past n as needed.
+func NewInMemoryLoggerWithCapacity(n int, opts ...Option) *InMemoryLogger {
+  if n < 0 {
+    n = 0
+  }
+  return &InMemoryLogger{opts: newOptions(opts), messages: make([]string, 0, n), times: make([]time.Time, 0, n)}
+}
+
+// NewBoundedInMemoryLogger returns an InMemoryLogger that keeps only the most
+// recent maxEntries messages. A maxEntries <= 0 means unbounded.
+func NewBoundedInMemoryLogger(maxEntries int, opts ...Option) *InMemoryLogger {
+  return &InMemoryLogger{opts: newOptions(opts), maxEntries: maxEntries}
+}
+
+// NewByteBoundedInMemoryLogger returns an InMemoryLogger that evicts the
+// oldest messages while their total length exceeds maxBytes. A message
+// longer than maxBytes on its own is kept, alone. A maxBytes <= 0 means
+// unbounded.
+func NewByteBoundedInMemoryLogger(maxBytes int, opts ...Option) *InMemoryLogger {
+  return &InMemoryLogger{opts: newOptions(opts), maxBytes: maxBytes}
+}
+
+func (this *InMemoryLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *InMemoryLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *InMemoryLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *InMemoryLogger) logEntry(e entry) error {
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  if err := this.opts.validateUTF8(&e); err != nil {
+    return err
+  }
+  entries, err := this.opts.splitLines(e)
+  if err != nil {
+    return err
+  }
+  this.mu.Lock()
+  if this.closed {
+    this.mu.Unlock()
+    return ErrClosed
+  }
+  if e.level < this.minLevel {
+    this.mu.Unlock()
+    return nil
+  }
+  now := this.opts.clock()
+  lines := this.opts.renderEach(entries, now)
+  size := 0
+  for _, line := range(lines) {
+    size += len(line)
+  }
+  if this.refuses(len(lines), size) {
+    this.mu.Unlock()
+    return this.opts.handle(ErrQueueFull)
+  }
+  for _, line := range(lines) {
+    if !this.opts.discardHistory {
+      this.store(line, now)
+    }
+    this.counts.add(e.level)
+  }
+  this.countRate(now, len(lines))
+  this.mu.Unlock()
+  for _, line := range(lines) {
+    this.opts.notify(e.level, line)
+  }
+  return nil
+}
+
+// Stats returns how many messages have been logged, by level. Evicted and
+// cleared messages still count.
+func (this *InMemoryLogger) Stats() Stats {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  return this.counts.stats()
+}
+
+// store appends line, stored at now, and evicts whatever the bounds no
+// longer allow. The caller must hold the write lock.
+func (this *InMemoryLogger) store(line string, now time.Time) {
+  if this.opts.internMax > 0 {
+    if this.interned == nil {
+      this.interned = newInterner(this.opts.internMax)
+    }
+    line = this.interned.intern(line)
+  }
+  this.messages = append(this.messages, line)
+  this.times = append(this.times, now)
+  this.bytes += len(line)
+  if this.index != nil {
+    this.index.add(now)
+  }
+  evicted := 0
+  if this.maxEntries > 0 && len(this.messages) > this.maxEntries {
+    evicted = len(this.messages) - this.maxEntries
+  }
+  for i := 0; i < evicted; i++ {
+    this.bytes -= len(this.messages[i])
+  }
+  for this.maxBytes > 0 && this.bytes > this.maxBytes && evicted < len(this.messages)-1 {
+    this.bytes -= len(this.messages[evicted])
+    evicted++
+  }
+  evicted += this.stale(evicted, now)
+  this.evict(evicted)
+  if b := this.usage(); b > this.highWater {
+    this.highWater = b
+  }
+  this.checkpointDue(now)
+}
+
+// stale counts the messages from index from on that are past the retention
+// period at now. The caller must hold the lock.
+func (this *InMemoryLogger) stale(from int, now time.Time) int {
+  if this.retention <= 0 {
+    return 0
+  }
+  cutoff := now.Add(-this.retention)
+  n := 0
+  for from+n < len(this.messages) && this.times[from+n].Before(cutoff) {
+    this.bytes -= len(this.messages[from+n])
+    n++
+  }
+  return n
+}
+
+// evict drops the oldest n messages. The caller must hold the write lock.
+func (this *InMemoryLogger) evict(n int) {
+  if n == 0 {
+    return
+  }
+  // Reslicing past the oldest entries keeps Log O(1); append copies just
+  // the live window whenever it has to grow the backing array.
+  for i := range(this.messages[:n]) {
+    this.messages[i] = ""
+  }
+  this.messages = this.messages[n:]
+  this.times = this.times[n:]
+  if this.index != nil {
+    this.index.evict(n)
+  }
+}
+
+// SetRetention evicts messages once they are older than d, by the clock.
+// Stale messages go at once and then whenever a message is logged, so reads
+// may see ones that expired since the last Log. A d <= 0 keeps them all.
+func (this *InMemoryLogger) SetRetention(d time.Duration) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.retention = d
+  this.evict(this.stale(0, this.opts.clock()))
 }
 
-func (this *InMemoryLogger) Log(mesg string) {
-  this.messages = append(this.messages, mesg)
+// CurrentBytes returns the total length of the stored messages.
+func (this *InMemoryLogger) CurrentBytes() int {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  return this.bytes
 }
 
-func (this *InMemoryLogger) Messages() []string {
-  return this.messages
+// entryOverhead is what each stored message costs besides its text: its
+// string header and timestamp.
+const entryOverhead = int(unsafe.Sizeof("") + unsafe.Sizeof(time.Time{}))
+
+// Bytes estimates the memory the stored messages take up: their length
+// plus a fixed overhead for each. Spare slice capacity is not counted.
+func (this *InMemoryLogger) Bytes() int {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  return this.usage()
+}
+
+func (this *InMemoryLogger) usage() int {
+  return this.bytes + len(this.messages)*entryOverhead
+}
+
+// HighWaterBytes returns the largest Bytes has been since the logger was
+// created, eviction and Clear notwithstanding.
+func (this *InMemoryLogger) HighWaterBytes() int {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  return this.highWater
+}
+
+// WithFields returns a child logger that adds fields to every message it
+// stores here. Nested calls merge, the innermost call winning on collisions.
+func (this *InMemoryLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+// WithPrefix returns a child logger that starts every message it stores here
+// with "[prefix] ". Nested calls concatenate: "[a][b] ".
+func (this *InMemoryLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+// SetMinLevel drops subsequent messages below level.
+func (this *InMemoryLogger) SetMinLevel(level Level) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.minLevel = level
+}
+
+// Messages returns a copy, so the caller may keep it while others Log.
+func (this *InMemoryLogger) Messages() ([]string, error) {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  return append([]string(nil), this.messages...), nil
+}
+
+// SortedMessages returns a copy of the messages sorted by less, or
+// lexicographically if less is nil. Equal messages keep their order, and
+// the stored order is left as it is.
+func (this *InMemoryLogger) SortedMessages(less func(a, b string) bool) []string {
+  messages, _ := this.Messages()
+  if less == nil {
+    sort.Strings(messages)
+    return messages
+  }
+  sort.SliceStable(messages, func(i, j int) bool {
+    return less(messages[i], messages[j])
+  })
+  return messages
+}
+
+func (this *InMemoryLogger) Count() (int, error) {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  return len(this.messages), nil
+}
+
+// Tail returns the last n messages, or all of them if there are fewer.
+func (this *InMemoryLogger) Tail(n int) ([]string, error) {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  return tailOf(this.messages, n), nil
+}
+
+// Search returns the messages containing pattern, or matching it as a
+// regular expression if useRegexp is set.
+func (this *InMemoryLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  return searchIn(this.messages, pattern, useRegexp)
+}
+
+// ForEach calls fn for each message in order, stopping at the first error
+// fn returns and returning it. fn may log to this logger.
+func (this *InMemoryLogger) ForEach(fn func(line string) error) error {
+  messages, _ := this.Messages()
+  return forEachIn(messages, fn)
+}
+
+// MessagesPage returns up to limit messages starting at offset, and whether
+// any follow them. A negative offset or a limit below one is an error.
+func (this *InMemoryLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  return pageOf(this.messages, offset, limit)
+}
+
+// Clear discards all messages; Messages then returns a nil slice.
+func (this *InMemoryLogger) Clear() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.reset()
+  return nil
+}
+
+// reset discards all messages. The caller must hold the write lock.
+func (this *InMemoryLogger) reset() {
+  this.messages = nil
+  this.times = nil
+  this.bytes = 0
+  if this.index != nil {
+    this.index.evict(this.index.next - this.index.base)
+  }
+  if this.checkpoint != nil {
+    this.checkpoint.dirty = true
+  }
+}
+
+// Sync is a no-op: there is nothing to make durable.
+func (this *InMemoryLogger) Sync() error {
+  return nil
+}
+
+// Close writes the last WithCheckpoint checkpoint, if there is one to
+// write. Logging afterwards fails with ErrClosed, but the messages can
+// still be read. Closing again does nothing.
+func (this *InMemoryLogger) Close() error {
+  this.mu.Lock()
+  if this.closed {
+    this.mu.Unlock()
+    return nil
+  }
+  this.closed = true
+  this.mu.Unlock()
+  return this.closeCheckpoint()
 }
 
 
 // LocalLogger saves the log messages in a file.
 type LocalLogger struct {
-  LoggerInterface
   filename string
-  file *os.File
+
+  // mu makes the logger safe for concurrent use. Whatever writes to the
+  // file or changes it underneath takes it exclusively: Log and LogBatch,
+  // the background flush, Clear, Compact, Recover, Reopen, rotation and
+  // Close. Reads flush and then take it shared for as long as they read,
+  // so that they see every record logged before they began and never a
+  // file half cleared or swapped; a function passed to ForEach runs under
+  // it and so must not log to the same logger. It guards the fields from
+  // file to needHeader, and filename when it changes by day.
+  mu sync.RWMutex
+  file File
+  gz *gzip.Writer // set in compressed mode; wraps file
+  buf *bufio.Writer // set in buffered mode; wraps gz or file
+  stop, stopped chan struct{} // end and await the background flusher
+
+  opts options
+  minLevel Level
+  rotation rotationPolicy
+  mirror []string
+  needHeader bool // a CSV file that has yet to get its header row
+  aead cipher.AEAD // set in encrypted mode
+  parseErrors atomic.Uint64 // JSON lines reads could not decode
+
+  // ReadFromDisk makes Messages re-scan the file even when WithMirror is set.
+  ReadFromDisk bool
+
+  // SkipTrailingPartial makes reads of the file ignore a last record that
+  // lacks its separator, as a crash in the middle of a write leaves one.
+  // CSV files are read as they are.
+  SkipTrailingPartial bool
 }
 
-func makeLocalLogger(filename string) *LocalLogger {
-  file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0755)
+var _ LoggerInterface = (*LocalLogger)(nil)
+
+// rotationPolicy lets a wrapper replace the file underneath LocalLogger's
+// write path, so that every way of logging honors it.
+type rotationPolicy interface {
+  // beforeWrite and afterWrite are told the size of the record written.
+  beforeWrite(n int) error
+  afterWrite(n int) error
+}
+
+// NewLocalLogger opens filename for appending, creating it if need be.
+// With truncate set, whatever the file held before is discarded.
+func NewLocalLogger(filename string, truncate bool, opts ...Option) (*LocalLogger, error) {
+  this := &LocalLogger{filename: filename, opts: newOptions(opts)}
+  if strings.HasSuffix(filename, ".gz") {
+    this.opts.gzip = true
+  }
+  if this.opts.encryptionKey != nil {
+    aead, err := newAEAD(this.opts.encryptionKey)
+    if err != nil {
+      return nil, err
+    }
+    this.aead = aead
+  }
+  if err := this.open(truncate); err != nil {
+    return nil, err
+  }
+  if this.opts.bufferSize > 0 {
+    this.startFlusher()
+  }
+  return this, nil
+}
+
+// open always appends, so processes sharing the file never overwrite
+// each other's records.
+func (this *LocalLogger) open(truncate bool) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return this.openLocked(truncate)
+}
+
+// openLocked is open for a caller holding mu.
+func (this *LocalLogger) openLocked(truncate bool) error {
+  file, size, err := this.openFile(truncate)
   if err != nil {
-    log.Fatal(err)
+    return err
   }
-  return &LocalLogger{filename: filename, file:file}
+  this.install(file, size)
+  return nil
 }
 
-func (this *LocalLogger) Log(mesg string) {
-  fmt.Fprintln(this.file, mesg)
+// openFile opens filename for writing, returning its size.
+func (this *LocalLogger) openFile(truncate bool) (File, int64, error) {
+  flag := os.O_RDWR|os.O_CREATE|os.O_APPEND
+  if truncate {
+    flag |= os.O_TRUNC
+  }
+  fsys := this.opts.fileSystem()
+  if mkdir, ok := fsys.(interface{ MkdirAll(string, os.FileMode) error }); ok && this.opts.mkdirAll {
+    if err := mkdir.MkdirAll(filepath.Dir(this.filename), 0755); err != nil {
+      return nil, 0, err
+    }
+  }
+  mode := this.opts.fileMode
+  if mode == 0 {
+    mode = 0644
+  }
+  file, err := fsys.OpenFile(this.filename, flag, mode)
+  if err != nil {
+    return nil, 0, err
+  }
+  info, err := file.Stat()
+  if err != nil {
+    file.Close()
+    return nil, 0, err
+  }
+  return file, info.Size(), nil
 }
 
-func (this *LocalLogger) Messages() []string {
-  file, err := os.Open(this.filename)
+// install makes file, of the given size, the one written to. The caller
+// must hold mu.
+func (this *LocalLogger) install(file File, size int64) {
+  this.file = file
+  this.needHeader = this.isCSV() && size == 0
+  if this.opts.gzip {
+    this.gz = gzip.NewWriter(file)
+  }
+  if this.opts.bufferSize > 0 {
+    this.buf = bufio.NewWriterSize(this.sink(), this.opts.bufferSize)
+  }
+}
+
+// sink is what the buffer, if any, writes through to.
+func (this *LocalLogger) sink() io.Writer {
+  if this.gz != nil {
+    return this.gz
+  }
+  return this.file
+}
+
+// out is where records are written.
+func (this *LocalLogger) out() io.Writer {
+  if this.buf != nil {
+    return this.buf
+  }
+  return this.sink()
+}
+
+// flush pushes buffered records through to the file, so that a reader
+// opening it sees everything logged so far.
+func (this *LocalLogger) flush() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return this.flushLocked()
+}
+
+// beginRead flushes and then takes mu shared, which the caller must
+// release with RUnlock once it has read the file.
+func (this *LocalLogger) beginRead() error {
+  if err := this.flush(); err != nil {
+    return err
+  }
+  this.mu.RLock()
+  return nil
+}
+
+func (this *LocalLogger) flushLocked() error {
+  if this.buf != nil {
+    if err := this.buf.Flush(); err != nil {
+      return err
+    }
+  }
+  if this.gz != nil {
+    return this.gz.Flush()
+  }
+  return nil
+}
+
+// Flush writes out the buffer, and the pending gzip block if any, without
+// syncing the file to stable storage as Sync does.
+func (this *LocalLogger) Flush() error {
+  return this.flush()
+}
+
+// closeFile releases the file, first writing out the buffer and ending the
+// gzip stream if any. A file already released is left alone.
+func (this *LocalLogger) closeFile() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.file == nil {
+    return nil
+  }
+  return this.closeLocked()
+}
+
+func (this *LocalLogger) closeLocked() error {
+  var bufErr, gzErr error
+  if this.buf != nil {
+    bufErr = this.buf.Flush()
+    this.buf = nil
+  }
+  if this.gz != nil {
+    gzErr = this.gz.Close()
+    this.gz = nil
+  }
+  err := this.file.Close()
+  this.file = nil
+  return errors.Join(bufErr, gzErr, err)
+}
+
+func (this *LocalLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *LocalLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *LocalLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *LocalLogger) logEntry(e entry) error {
+  admitted, err := this.admits(e.level)
   if err != nil {
-    log.Fatal(err)
+    return err
+  }
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  if err := this.opts.validateUTF8(&e); err != nil {
+    return err
+  }
+  if !admitted {
+    return nil
+  }
+  entries, err := this.opts.splitLines(e)
+  if err != nil {
+    return err
+  }
+  lines := this.opts.renderEach(entries, this.opts.clock())
+  if err := this.writeSync(this.opts.syncsAt(e.level), lines...); errors.Is(err, ErrClosed) {
+    return err
+  } else if err != nil {
+    return this.opts.handle(writeFailed(err))
+  }
+  for _, line := range(lines) {
+    this.opts.notify(e.level, line)
+  }
+  return nil
+}
+
+// admits reports whether a message at level is to be written, failing
+// once the logger is closed.
+func (this *LocalLogger) admits(level Level) (bool, error) {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  if this.file == nil {
+    return false, ErrClosed
+  }
+  return level >= this.minLevel, nil
+}
+
+// WithFields returns a child logger that adds fields to every message it
+// writes here. Nested calls merge, the innermost call winning on collisions.
+func (this *LocalLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+// WithPrefix returns a child logger that starts every message it writes here
+// with "[prefix] ". Nested calls concatenate: "[a][b] ".
+func (this *LocalLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+// write writes lines as one run of records, in a single Write, and so
+// never rotates in the middle of them. It fails with ErrClosed if the
+// logger was closed since the caller looked.
+func (this *LocalLogger) write(lines ...string) error {
+  return this.writeSync(false, lines...)
+}
+
+// writeSync is write, syncing the file afterwards if sync is set, as it
+// does for every write WithSyncOnWrite.
+func (this *LocalLogger) writeSync(sync bool, lines ...string) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.file == nil {
+    return ErrClosed
+  }
+  sep := this.opts.recordSeparator()
+  var records []byte
+  header := this.needHeader
+  for _, line := range(lines) {
+    var record []byte
+    if header {
+      record = append([]byte(csvRow(csvHeader)), sep)
+      header = false
+    }
+    record = append(append(record, line...), sep)
+    if this.aead != nil {
+      sealed, err := this.seal(record)
+      if err != nil {
+        return err
+      }
+      record = sealed
+    }
+    records = append(records, record...)
+  }
+  n := len(records)
+  if this.rotation != nil {
+    if err := this.rotation.beforeWrite(n); err != nil {
+      return err
+    }
+  }
+  if this.buf != nil && n > this.buf.Available() {
+    // Left to itself bufio would fill the buffer and write it out with
+    // part of these records, for a read between writes to find.
+    if err := this.buf.Flush(); err != nil {
+      return err
+    }
+  }
+  _, err := this.out().Write(records)
+  if err == nil {
+    this.needHeader = false
+  }
+  if err == nil && (sync || this.opts.syncOnWrite) {
+    err = this.syncLocked()
+  }
+  if err != nil {
+    return err
+  }
+  for _, line := range(lines) {
+    if this.opts.mirror {
+      this.mirror = append(this.mirror, this.messageOf(line))
+    }
+  }
+  if this.rotation != nil {
+    return this.rotation.afterWrite(n)
+  }
+  return nil
+}
+
+// messageOf returns what reads of the file give back for a line written
+// to it: the message column of a CSV row, the "msg" of a JSON line, or
+// else the line itself.
+func (this *LocalLogger) messageOf(line string) string {
+  switch {
+  case this.isCSV():
+    return csvMessage(line)
+  case this.isJSONL():
+    return jsonlMessage(line)
+  }
+  return line
+}
+
+// SetMinLevel drops subsequent messages below level.
+func (this *LocalLogger) SetMinLevel(level Level) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.minLevel = level
+}
+
+func (this *LocalLogger) Messages() ([]string, error) {
+  if err := this.beginRead(); err != nil {
+    return nil, err
+  }
+  defer this.mu.RUnlock()
+  return this.messagesLocked()
+}
+
+// messagesLocked is Messages for a caller that has begun reading.
+func (this *LocalLogger) messagesLocked() ([]string, error) {
+  if this.opts.mirror && !this.ReadFromDisk {
+    return append([]string(nil), this.mirror...), nil
+  }
+  return this.readLines(this.filename)
+}
+
+// Count streams the file rather than building the slice Messages would.
+// A large plain file is mapped into memory instead, where mmap is
+// available, and its separators counted in place.
+func (this *LocalLogger) Count() (int, error) {
+  if err := this.beginRead(); err != nil {
+    return 0, err
+  }
+  defer this.mu.RUnlock()
+  return this.countLocked()
+}
+
+// countLocked is Count for a caller that has begun reading.
+func (this *LocalLogger) countLocked() (int, error) {
+  if this.opts.mirror && !this.ReadFromDisk {
+    return len(this.mirror), nil
+  }
+  return this.countLines(this.filename)
+}
+
+// countLines counts the lines of the named file, including a last line
+// that lacks its separator, the way readLines would split them.
+func (this *LocalLogger) countLines(filename string) (int, error) {
+  if this.parsed() {
+    // A quoted message may hold line breaks, and a JSON line may not
+    // decode, so the records must be parsed.
+    count := 0
+    err := this.scanLines(filename, func(string) error {
+      count++
+      return nil
+    })
+    return count, err
+  }
+  if count, ok := this.countMapped(filename); ok {
+    return count, nil
+  }
+  file, err := this.openRead(filename)
+  if err != nil {
+    return 0, err
+  }
+  defer file.Close()
+
+  sep := this.opts.recordSeparator()
+  count := 0
+  last := sep
+  buf := make([]byte, 32*1024)
+  for {
+    n, err := file.Read(buf)
+    if n > 0 {
+      count += bytes.Count(buf[:n], []byte{sep})
+      last = buf[n-1]
+    }
+    if err == io.EOF {
+      break
+    }
+    if err != nil {
+      return 0, err
+    }
+  }
+  if last != sep && !this.SkipTrailingPartial {
+    count++
+  }
+  return count, nil
+}
+
+// Tail reads the file backwards from the end, so that it touches only about
+// as much of it as the last n lines take up, through a memory mapping if
+// it is a large plain file and mmap is available.
+func (this *LocalLogger) Tail(n int) ([]string, error) {
+  if err := this.beginRead(); err != nil {
+    return nil, err
+  }
+  defer this.mu.RUnlock()
+  return this.tailLocked(n)
+}
+
+// tailLocked is Tail for a caller that has begun reading.
+func (this *LocalLogger) tailLocked(n int) ([]string, error) {
+  if this.opts.mirror && !this.ReadFromDisk {
+    return tailOf(this.mirror, n), nil
+  }
+  return this.tailLines(this.filename, n)
+}
+
+// tailChunk is how much of the file tailLines reads per step.
+const tailChunk = 4096
+
+func (this *LocalLogger) tailLines(filename string, n int) ([]string, error) {
+  if n <= 0 {
+    return nil, nil
+  }
+  if this.opts.gzip || this.aead != nil || this.parsed() {
+    // Compressed and encrypted streams can only be read from the start,
+    // and CSV rows and JSON lines must be parsed from it.
+    lines, err := this.readLines(filename)
+    return tailOf(lines, n), err
+  }
+  if lines, ok := this.tailMapped(filename, n); ok {
+    return lines, nil
+  }
+  file, err := this.opts.fileSystem().Open(filename)
+  if err != nil {
+    return nil, err
   }
   defer file.Close()
+  sep := this.opts.recordSeparator()
+  pos, err := this.readEnd(file, sep)
+  if err != nil {
+    return nil, err
+  }
 
+  // Read whole chunks backwards until the data holds n separators besides
+  // the one ending the file, or the start of the file is reached.
+  var data []byte
+  breaks := 0
+  for pos > 0 && breaks < n {
+    step := int64(tailChunk)
+    if step > pos {
+      step = pos
+    }
+    pos -= step
+    chunk := make([]byte, step)
+    if _, err := file.ReadAt(chunk, pos); err != nil {
+      return nil, err
+    }
+    breaks += bytes.Count(chunk, []byte{sep})
+    if len(data) == 0 && chunk[len(chunk)-1] == sep {
+      breaks--
+    }
+    data = append(chunk, data...)
+  }
+  if len(data) == 0 {
+    return nil, nil
+  }
+
+  lines := strings.Split(string(bytes.TrimSuffix(data, []byte{sep})), string([]byte{sep}))
+  if pos > 0 {
+    lines = lines[1:] // the first line was only partly read
+  }
+  if sep == '\n' {
+    for i := range(lines) {
+      lines[i] = strings.TrimSuffix(lines[i], "\r") // as bufio.ScanLines does
+    }
+  }
+  return tailOf(lines, n), nil
+}
+
+// tailOf returns a copy of the last n messages.
+func tailOf(messages []string, n int) []string {
+  if n <= 0 {
+    return nil
+  }
+  if n > len(messages) {
+    n = len(messages)
+  }
+  return append([]string(nil), messages[len(messages)-n:]...)
+}
+
+// Search streams the file, keeping only the matching lines in memory. A
+// large plain file is mapped into memory instead, where mmap is
+// available, and only the matching lines copied out of it.
+func (this *LocalLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  if err := this.beginRead(); err != nil {
+    return nil, err
+  }
+  defer this.mu.RUnlock()
+  if this.opts.mirror && !this.ReadFromDisk {
+    return searchIn(this.mirror, pattern, useRegexp)
+  }
+  if !this.parsed() {
+    if found, ok, err := this.searchMapped(this.filename, pattern, useRegexp); ok {
+      return found, err
+    }
+  }
+  match, err := newMatcher(pattern, useRegexp)
+  if err != nil {
+    return nil, err
+  }
+  var found []string
+  err = this.scanLines(this.filename, func(line string) error {
+    if match(line) {
+      found = append(found, line)
+    }
+    return nil
+  })
+  return found, err
+}
+
+// ForEach streams the file through fn, so that memory use stays constant
+// however large it is. Logging waits while it reads, so fn must not log
+// to this logger.
+func (this *LocalLogger) ForEach(fn func(line string) error) error {
+  if err := this.beginRead(); err != nil {
+    return err
+  }
+  defer this.mu.RUnlock()
+  return this.forEachLocked(fn)
+}
+
+// forEachLocked is ForEach for a caller that has begun reading.
+func (this *LocalLogger) forEachLocked(fn func(line string) error) error {
+  if this.opts.mirror && !this.ReadFromDisk {
+    return forEachIn(append([]string(nil), this.mirror...), fn)
+  }
+  return this.scanLines(this.filename, fn)
+}
+
+// MessagesPage streams the file, skipping the lines before offset without
+// keeping them.
+func (this *LocalLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return pageFrom(this.ForEach, offset, limit)
+}
+
+// forEachIn calls fn for each message, stopping at the first error.
+func forEachIn(messages []string, fn func(line string) error) error {
+  for _, mesg := range(messages) {
+    if err := fn(mesg); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+// readLines returns the lines of the named file.
+func (this *LocalLogger) readLines(filename string) ([]string, error) {
   var messages []string
+  err := this.scanLines(filename, func(line string) error {
+    messages = append(messages, line)
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  return messages, nil
+}
+
+// scanLines calls fn for each line of the named file, stopping at the
+// first error fn returns.
+func (this *LocalLogger) scanLines(filename string, fn func(line string) error) error {
+  if this.isJSONL() {
+    return this.scanJSONL(filename, fn)
+  }
+  return this.scanRaw(filename, fn)
+}
+
+// scanRaw is scanLines handing fn each record as it is in the file, but
+// for CSV rows, which are parsed into their message column.
+func (this *LocalLogger) scanRaw(filename string, fn func(line string) error) error {
+  file, err := this.openRead(filename)
+  if err != nil {
+    return err
+  }
+  defer file.Close()
+
+  if this.isCSV() {
+    return scanCSV(file, fn)
+  }
   scanner := bufio.NewScanner(file)
+  sep := this.opts.recordSeparator()
+  split := bufio.ScanLines
+  if sep != '\n' {
+    split = scanRecords(sep)
+  }
+  if this.SkipTrailingPartial {
+    split = dropPartial(split, sep)
+  }
+  scanner.Split(split)
   for scanner.Scan() {
-    messages = append(messages, scanner.Text())
+    if err := fn(scanner.Text()); err != nil {
+      return err
+    }
   }
-  if err := scanner.Err(); err != nil {
-    log.Fatal(err)
+  return scanner.Err()
+}
+
+// scanRecords is bufio.ScanLines for records ending in sep, leaving any
+// carriage returns in place.
+func scanRecords(sep byte) bufio.SplitFunc {
+  return func(data []byte, atEOF bool) (int, []byte, error) {
+    if atEOF && len(data) == 0 {
+      return 0, nil, nil
+    }
+    if i := bytes.IndexByte(data, sep); i >= 0 {
+      return i + 1, data[:i], nil
+    }
+    if atEOF {
+      return len(data), data, nil
+    }
+    return 0, nil, nil
   }
-  return messages
+}
+
+// Clear empties the file; Messages then returns a nil slice.
+func (this *LocalLogger) Clear() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return this.clearLocked()
+}
+
+// clearLocked is Clear for a caller holding mu.
+func (this *LocalLogger) clearLocked() error {
+  if this.file == nil {
+    return ErrClosed
+  }
+  if err := this.opts.fileSystem().Truncate(this.filename, 0); err != nil {
+    return err
+  }
+  if this.gz != nil {
+    this.gz.Reset(this.file)
+  }
+  if this.buf != nil {
+    this.buf.Reset(this.sink())
+  }
+  this.mirror = nil
+  this.needHeader = this.isCSV()
+  _, err := this.file.Seek(0, io.SeekStart)
+  return err
+}
+
+// Sync commits the file to stable storage, so that messages logged so far
+// survive a crash.
+func (this *LocalLogger) Sync() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.file == nil {
+    return ErrClosed
+  }
+  return this.syncLocked()
+}
+
+func (this *LocalLogger) syncLocked() error {
+  if err := this.flushLocked(); err != nil {
+    return err
+  }
+  return this.file.Sync()
+}
+
+// Reopen closes the file and opens filename afresh, creating it if it has
+// been moved away. Send it SIGHUP after logrotate renames the file, so that
+// later messages go to the new one rather than the renamed inode. It may be
+// called while another goroutine logs.
+func (this *LocalLogger) Reopen() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return this.reopenLocked()
+}
+
+// reopenLocked is Reopen for a caller holding mu.
+func (this *LocalLogger) reopenLocked() error {
+  if this.file == nil {
+    return ErrClosed
+  }
+  file, size, err := this.openFile(false)
+  if err != nil {
+    return err
+  }
+  err = this.closeLocked()
+  this.install(file, size)
+  return err
+}
+
+func (this *LocalLogger) isClosed() bool {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  return this.file == nil
+}
+
+// Close releases the file handle. Closing an already closed logger is a no-op.
+func (this *LocalLogger) Close() error {
+  if this.isClosed() {
+    return nil
+  }
+  this.stopFlusher()
+  return this.closeFile()
 }
 
 
 func main() {
+  console := flag.Bool("console", false, "also echo the messages to stdout")
+  flag.Parse()
   filename := "/tmp/outfile_golang.txt";
-  if len(os.Args) > 1 {
-    filename = os.Args[1]
+  if flag.NArg() > 0 {
+    filename = flag.Arg(0)
   }
 
   // A sequential collection of interfaces via slices.
   var loggers []LoggerInterface
-  loggers = append(loggers, &InMemoryLogger{})
-  loggers = append(loggers, makeLocalLogger(filename))
+  loggers = append(loggers, NewInMemoryLogger())
+  // Truncate, so that the check below sees only this run's messages.
+  localLogger, err := NewLocalLogger(filename, true)
+  if err != nil {
+    log.Fatal(err)
+  }
+  loggers = append(loggers, localLogger)
+  for _, logger := range(loggers) {
+    defer logger.Close()
+  }
+
+  // Reopen the file on SIGHUP, so that logrotate can move it aside.
+  hup := make(chan os.Signal, 1)
+  signal.Notify(hup, syscall.SIGHUP)
+  defer signal.Stop(hup)
+  go func() {
+    for range(hup) {
+      if err := localLogger.Reopen(); err != nil {
+        log.Print(err)
+      }
+    }
+  }()
 
   var testMessages = []string{
     "Hello, World!",
     "abracadabra",
     "Sayonara!",
   }
+  // The console keeps no history, so it is written to but not checked.
+  sinks := append([]LoggerInterface{}, loggers...)
+  if *console {
+    sinks = append(sinks, NewConsoleLogger(os.Stdout))
+  }
+  multiLogger := NewMultiLogger(sinks...)
   for _, mesg := range(testMessages) {
-    for _, logger := range(loggers) {
-      logger.Log(mesg)
+    if err := multiLogger.Log(mesg); err != nil {
+      log.Fatal(err)
     }
   }
 
   for _, logger := range(loggers) {
-    observedMessages := logger.Messages()
+    observedMessages, err := logger.Messages()
+    if err != nil {
+      log.Fatal(err)
+    }
     if !reflect.DeepEqual(observedMessages, testMessages) {
+      missing, extra := diffMessages(testMessages, observedMessages)
       log.Fatal("expected: ", testMessages,
-        "; but observed: ", observedMessages, "\n")
+        "; but observed: ", observedMessages,
+        "; missing: ", missing, "; extra: ", extra, "\n")
     }
   }
 }
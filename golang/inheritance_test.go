@@ -0,0 +1,445 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+  "os"
+  "path/filepath"
+  "reflect"
+  "strings"
+  "sync"
+  "testing"
+  "time"
+)
+
+// newTestLocal returns a LocalLogger on a new file in t's temporary
+// directory, closed when t ends.
+func newTestLocal(t testing.TB, opts ...Option) *LocalLogger {
+  t.Helper()
+  l, err := NewLocalLogger(filepath.Join(t.TempDir(), "test.log"), true, opts...)
+  if err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() {
+    l.Close()
+  })
+  return l
+}
+
+// bothLoggers returns a fresh one of each of the two original
+// implementations, by name, for tests that they agree.
+func bothLoggers(t testing.TB, opts ...Option) map[string]LoggerInterface {
+  return map[string]LoggerInterface{
+    "InMemoryLogger": NewInMemoryLogger(opts...),
+    "LocalLogger": newTestLocal(t, opts...),
+  }
+}
+
+func TestBoundedInMemoryKeepsNewest(t *testing.T) {
+  l := NewBoundedInMemoryLogger(10)
+  for i := 0; i < 1000; i++ {
+    l.Logf(LevelInfo, "message %d", i)
+  }
+  var want []string
+  for i := 990; i < 1000; i++ {
+    want = append(want, fmt.Sprintf("message %d", i))
+  }
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages = %q, want %q", got, want)
+  }
+}
+
+func TestBoundedInMemoryUnbounded(t *testing.T) {
+  for _, max := range([]int{0, -1}) {
+    l := NewBoundedInMemoryLogger(max)
+    for i := 0; i < 50; i++ {
+      l.Log("x")
+    }
+    AssertLoggedCount(t, l, 50)
+  }
+}
+
+func TestClear(t *testing.T) {
+  for name, l := range(bothLoggers(t)) {
+    l.Log("one")
+    l.Log("two")
+    if err := l.Clear(); err != nil {
+      t.Fatalf("%s: Clear: %v", name, err)
+    }
+    if got, err := l.Messages(); err != nil || got != nil {
+      t.Errorf("%s: Messages after Clear = %#v, %v; want nil", name, got, err)
+    }
+    l.Log("three")
+    if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"three"}) {
+      t.Errorf("%s: Messages after logging again = %q", name, got)
+    }
+  }
+}
+
+func TestCountMatchesMessages(t *testing.T) {
+  for name, l := range(bothLoggers(t)) {
+    for i := 0; i < 37; i++ {
+      l.Logf(LevelInfo, "line %d", i)
+    }
+    messages, _ := l.Messages()
+    if n, err := l.Count(); err != nil || n != len(messages) {
+      t.Errorf("%s: Count = %d, %v; len(Messages) = %d", name, n, err, len(messages))
+    }
+  }
+}
+
+// countingFS is a MemFS that counts the bytes read from its files.
+type countingFS struct {
+  *MemFS
+  read int64
+}
+
+func (this *countingFS) Open(name string) (File, error) {
+  file, err := this.MemFS.Open(name)
+  if err != nil {
+    return nil, err
+  }
+  return &countingFile{File: file, fs: this}, nil
+}
+
+type countingFile struct {
+  File
+  fs *countingFS
+}
+
+func (this *countingFile) Read(p []byte) (int, error) {
+  n, err := this.File.Read(p)
+  this.fs.read += int64(n)
+  return n, err
+}
+
+func (this *countingFile) ReadAt(p []byte, off int64) (int, error) {
+  n, err := this.File.ReadAt(p, off)
+  this.fs.read += int64(n)
+  return n, err
+}
+
+func TestTailReadsOnlyTheEnd(t *testing.T) {
+  fsys := &countingFS{MemFS: NewMemFS()}
+  l, err := NewLocalLogger("big.log", true, WithFS(fsys))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  for i := 0; i < 100000; i++ {
+    l.Logf(LevelInfo, "line %06d of a file of several megabytes", i)
+  }
+  data, _ := fsys.ReadFile("big.log")
+  fsys.read = 0
+  got, err := l.Tail(5)
+  if err != nil {
+    t.Fatal(err)
+  }
+  want := []string{}
+  for i := 99995; i < 100000; i++ {
+    want = append(want, fmt.Sprintf("line %06d of a file of several megabytes", i))
+  }
+  if !reflect.DeepEqual(got, want) {
+    t.Errorf("Tail(5) = %q", got)
+  }
+  if fsys.read >= int64(len(data))/100 {
+    t.Errorf("Tail(5) read %d bytes of a %d byte file", fsys.read, len(data))
+  }
+}
+
+func TestTailMoreThanHeld(t *testing.T) {
+  for name, l := range(bothLoggers(t)) {
+    l.Log("a")
+    l.Log("b")
+    if got, _ := l.Tail(10); !reflect.DeepEqual(got, []string{"a", "b"}) {
+      t.Errorf("%s: Tail(10) = %q", name, got)
+    }
+  }
+}
+
+func TestSyncIsVisibleToAnotherHandle(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "sync.log")
+  l, err := NewLocalLogger(filename, true, WithBufferSize(64<<10))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Log("first")
+  l.Log("second")
+  if err := l.Sync(); err != nil {
+    t.Fatalf("Sync: %v", err)
+  }
+  data, err := os.ReadFile(filename)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if got, want := string(data), "first\nsecond\n"; got != want {
+    t.Errorf("file after Sync = %q, want %q", got, want)
+  }
+}
+
+func TestReplayLocalIntoInMemory(t *testing.T) {
+  src := newTestLocal(t)
+  for i := 0; i < 20; i++ {
+    src.Logf(LevelInfo, "line %d", i)
+  }
+  dst := NewInMemoryLogger()
+  n, err := Replay(dst, src)
+  if err != nil || n != 20 {
+    t.Fatalf("Replay = %d, %v; want 20, nil", n, err)
+  }
+  want, _ := src.Messages()
+  if got, _ := dst.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("replayed %q, want %q", got, want)
+  }
+}
+
+// failAfter is an InMemoryLogger whose Log fails once it holds n messages.
+type failAfter struct {
+  *InMemoryLogger
+  n int
+}
+
+var errFailAfter = errors.New("no more room")
+
+func (this *failAfter) Log(mesg string) error {
+  if count, _ := this.Count(); count >= this.n {
+    return errFailAfter
+  }
+  return this.InMemoryLogger.Log(mesg)
+}
+
+func TestReplayStopsAtFailure(t *testing.T) {
+  src := NewInMemoryLogger()
+  for i := 0; i < 10; i++ {
+    src.Logf(LevelInfo, "line %d", i)
+  }
+  dst := &failAfter{InMemoryLogger: NewInMemoryLogger(), n: 4}
+  n, err := Replay(dst, src)
+  if n != 4 || !errors.Is(err, errFailAfter) {
+    t.Errorf("Replay = %d, %v; want 4, %v", n, err, errFailAfter)
+  }
+}
+
+func TestForEachMatchesMessages(t *testing.T) {
+  for name, l := range(bothLoggers(t)) {
+    for i := 0; i < 100; i++ {
+      l.Logf(LevelInfo, "message number %d", i*i)
+    }
+    messages, _ := l.Messages()
+    want := 0
+    for _, mesg := range(messages) {
+      want += len(mesg)
+    }
+    got := 0
+    err := l.ForEach(func(line string) error {
+      got += len(line)
+      return nil
+    })
+    if err != nil || got != want {
+      t.Errorf("%s: ForEach summed %d, %v; Messages sum to %d", name, got, err, want)
+    }
+  }
+}
+
+func TestForEachStopsEarly(t *testing.T) {
+  stop := errors.New("stop")
+  for name, l := range(bothLoggers(t)) {
+    for i := 0; i < 10; i++ {
+      l.Log("x")
+    }
+    calls := 0
+    err := l.ForEach(func(line string) error {
+      calls++
+      if calls == 3 {
+        return stop
+      }
+      return nil
+    })
+    if err != stop || calls != 3 {
+      t.Errorf("%s: ForEach = %v after %d calls; want %v after 3", name, err, calls, stop)
+    }
+  }
+}
+
+func TestRetentionDropsStale(t *testing.T) {
+  clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+  l := NewInMemoryLogger(WithClock(clock))
+  l.SetRetention(time.Hour)
+  l.Log("old")
+  clock.Advance(30 * time.Minute)
+  l.Log("middle")
+  clock.Advance(31 * time.Minute)
+  l.Log("new")
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"middle", "new"}) {
+    t.Errorf("Messages = %q, want the two under an hour old", got)
+  }
+  clock.Advance(2 * time.Hour)
+  l.SetRetention(time.Hour)
+  if got, _ := l.Messages(); got != nil {
+    t.Errorf("Messages after all expired = %q, want none", got)
+  }
+}
+
+func TestReopenAfterRename(t *testing.T) {
+  dir := t.TempDir()
+  filename := filepath.Join(dir, "app.log")
+  l, err := NewLocalLogger(filename, true)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Log("before")
+  rotated := filepath.Join(dir, "app.log.1")
+  if err := os.Rename(filename, rotated); err != nil {
+    t.Fatal(err)
+  }
+  if err := l.Reopen(); err != nil {
+    t.Fatalf("Reopen: %v", err)
+  }
+  l.Log("after")
+  if data, _ := os.ReadFile(filename); string(data) != "after\n" {
+    t.Errorf("new file = %q, want just the message after Reopen", data)
+  }
+  if data, _ := os.ReadFile(rotated); string(data) != "before\n" {
+    t.Errorf("rotated file = %q, want just the message before", data)
+  }
+}
+
+func TestBytesAndHighWater(t *testing.T) {
+  l := NewInMemoryLogger()
+  if l.Bytes() != 0 || l.HighWaterBytes() != 0 {
+    t.Fatalf("a new logger uses %d bytes, peak %d", l.Bytes(), l.HighWaterBytes())
+  }
+  for i := 0; i < 10; i++ {
+    l.Log("0123456789")
+  }
+  full := l.Bytes()
+  if want := 10*(10+entryOverhead); full != want || l.CurrentBytes() != 100 {
+    t.Errorf("Bytes = %d, CurrentBytes = %d; want %d and 100", full, l.CurrentBytes(), want)
+  }
+  l.Clear()
+  if l.Bytes() != 0 {
+    t.Errorf("Bytes after Clear = %d, want 0", l.Bytes())
+  }
+  if l.HighWaterBytes() != full {
+    t.Errorf("HighWaterBytes after Clear = %d, want the peak %d", l.HighWaterBytes(), full)
+  }
+}
+
+func TestHighWaterSurvivesEviction(t *testing.T) {
+  l := NewBoundedInMemoryLogger(2)
+  l.Log("a long message to begin with")
+  l.Log("b")
+  peak := l.HighWaterBytes()
+  l.Log("c")
+  if l.Bytes() >= peak || l.HighWaterBytes() != peak {
+    t.Errorf("after eviction Bytes = %d, HighWaterBytes = %d; want under and at %d", l.Bytes(), l.HighWaterBytes(), peak)
+  }
+}
+
+func TestZeroValueInMemoryLogger(t *testing.T) {
+  var l InMemoryLogger
+  l.Log("works")
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"works"}) {
+    t.Errorf("Messages = %q", got)
+  }
+}
+
+func TestWithCapacityGrowsPastIt(t *testing.T) {
+  l := NewInMemoryLoggerWithCapacity(2)
+  for i := 0; i < 5; i++ {
+    l.Log("x")
+  }
+  AssertLoggedCount(t, l, 5)
+}
+
+// stressed is what TestLocalLoggerStress runs concurrently.
+type stressed interface {
+  LoggerInterface
+  Reopen() error
+}
+
+// TestLocalLoggerStress runs Log, the reads and the operations that change
+// the file underneath them all at once; run it with -race.
+func TestLocalLoggerStress(t *testing.T) {
+  rotating, err := NewRotatingLocalLogger(filepath.Join(t.TempDir(), "rotating.log"), 4096, 2)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer rotating.Close()
+  for name, l := range(map[string]stressed{
+    "LocalLogger": newTestLocal(t),
+    "buffered": newTestLocal(t, WithBufferSize(256), WithFlushInterval(time.Millisecond)),
+    "RotatingLocalLogger": rotating,
+  }) {
+    stop := make(chan struct{})
+    var wg sync.WaitGroup
+    fail := make(chan error, 16)
+    run := func(fn func() error) {
+      wg.Add(1)
+      go func() {
+        defer wg.Done()
+        for {
+          select {
+          case <-stop:
+            return
+          default:
+          }
+          if err := fn(); err != nil {
+            fail <- err
+            return
+          }
+        }
+      }()
+    }
+    for g := 0; g < 4; g++ {
+      run(func() error { return l.Logf(LevelInfo, "message %04d", g) })
+    }
+    run(func() error {
+      messages, err := l.Messages()
+      for _, mesg := range(messages) {
+        if !strings.HasPrefix(mesg, "message ") || len(mesg) != len("message 0000") {
+          return fmt.Errorf("read a corrupt line %q", mesg)
+        }
+      }
+      return err
+    })
+    run(func() error { _, err := l.Count(); return err })
+    run(func() error { _, err := l.Tail(5); return err })
+    run(l.Clear)
+    run(l.Reopen)
+    if local, ok := l.(*LocalLogger); ok {
+      run(local.Compact)
+    }
+    time.Sleep(300 * time.Millisecond)
+    close(stop)
+    wg.Wait()
+    close(fail)
+    for err := range(fail) {
+      t.Errorf("%s: %v", name, err)
+    }
+  }
+}
+
+const benchMessages = 100000
+
+func BenchmarkInMemoryGrowing(b *testing.B) {
+  b.ReportAllocs()
+  for i := 0; i < b.N; i++ {
+    l := NewInMemoryLogger()
+    for j := 0; j < benchMessages; j++ {
+      l.Log("message")
+    }
+  }
+}
+
+func BenchmarkInMemoryPreallocated(b *testing.B) {
+  b.ReportAllocs()
+  for i := 0; i < b.N; i++ {
+    l := NewInMemoryLoggerWithCapacity(benchMessages)
+    for j := 0; j < benchMessages; j++ {
+      l.Log("message")
+    }
+  }
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+  "container/list"
+)
+
+// WithInterning makes an InMemoryLogger keep one copy of a line it stores
+// many times, sharing it between the messages, for workloads that log a
+// few distinct messages over and over. It remembers the max distinct
+// lines stored most recently, so a rare one is not held forever; a max
+// <= 0 means 1024. Lines are interned as stored, so timestamps or a
+// sequence number make every one distinct.
+func WithInterning(max int) Option {
+  return func(o *options) {
+    if max <= 0 {
+      max = 1024
+    }
+    o.internMax = max
+  }
+}
+
+// interner is the pool behind WithInterning. It is not synchronized: it
+// must be used under the logger's write lock.
+type interner struct {
+  max int
+  seen map[string]*list.Element
+  order *list.List // of string, most recently stored first
+  total int
+}
+
+func newInterner(max int) *interner {
+  return &interner{max: max, seen: make(map[string]*list.Element), order: list.New()}
+}
+
+// intern returns the pooled copy of s, pooling s if there is none.
+func (this *interner) intern(s string) string {
+  this.total++
+  if elem, ok := this.seen[s]; ok {
+    this.order.MoveToFront(elem)
+    return elem.Value.(string)
+  }
+  this.seen[s] = this.order.PushFront(s)
+  if this.order.Len() > this.max {
+    oldest := this.order.Back()
+    this.order.Remove(oldest)
+    delete(this.seen, oldest.Value.(string))
+  }
+  return s
+}
+
+// InternStats returns how many distinct lines the WithInterning pool
+// holds, and how many lines have been stored through it in all.
+func (this *InMemoryLogger) InternStats() (distinct, total int) {
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  if this.interned == nil {
+    return 0, 0
+  }
+  return len(this.interned.seen), this.interned.total
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+  "fmt"
+  "runtime"
+  "strings"
+  "testing"
+  "unsafe"
+)
+
+func TestInterningShares(t *testing.T) {
+  l := NewInMemoryLogger(WithInterning(0))
+  for i := 0; i < 6; i++ {
+    l.Log(fmt.Sprintf("template %d", i%2)) // a fresh copy each time
+  }
+  messages, _ := l.Messages()
+  for i := 2; i < len(messages); i++ {
+    if unsafe.StringData(messages[i]) != unsafe.StringData(messages[i%2]) {
+      t.Errorf("message %d does not share the first copy of %q", i, messages[i])
+    }
+  }
+  if distinct, total := l.InternStats(); distinct != 2 || total != 6 {
+    t.Errorf("InternStats = %d, %d; want 2, 6", distinct, total)
+  }
+}
+
+func TestInterningBounded(t *testing.T) {
+  l := NewInMemoryLogger(WithInterning(2))
+  for _, mesg := range([]string{"a", "b", "a", "c"}) {
+    l.Log(strings.Clone(mesg))
+  }
+  if distinct, total := l.InternStats(); distinct != 2 || total != 4 {
+    t.Errorf("InternStats = %d, %d; want 2, 4", distinct, total)
+  }
+  l.Log(strings.Clone("b")) // evicted by c, as a was used since
+  messages, _ := l.Messages()
+  if unsafe.StringData(messages[4]) == unsafe.StringData(messages[1]) {
+    t.Error("an evicted line was still shared")
+  }
+  l.Log(strings.Clone("a"))
+  messages, _ = l.Messages()
+  if unsafe.StringData(messages[5]) == unsafe.StringData(messages[0]) {
+    t.Error("a line evicted in turn was still shared")
+  }
+}
+
+func TestInterningOff(t *testing.T) {
+  l := NewInMemoryLogger()
+  l.Log("x")
+  if distinct, total := l.InternStats(); distinct != 0 || total != 0 {
+    t.Errorf("InternStats without WithInterning = %d, %d", distinct, total)
+  }
+}
+
+// repeatedInput is benchMessages lines from 10 distinct templates.
+var repeatedInput = func() string {
+  var b strings.Builder
+  for i := 0; i < benchMessages; i++ {
+    fmt.Fprintf(&b, "GET /api/v1/resource/%d status=200\n", i%10)
+  }
+  return b.String()
+}()
+
+// benchmarkLoadRepeated loads repeatedInput, each line a fresh string as
+// a reader gives it, into a logger made with opts, and reports the heap
+// such a logger retains per message.
+func benchmarkLoadRepeated(b *testing.B, opts ...Option) {
+  b.ReportAllocs()
+  for i := 0; i < b.N; i++ {
+    l := NewInMemoryLoggerWithCapacity(benchMessages, opts...)
+    LoadFrom(l, strings.NewReader(repeatedInput))
+  }
+  b.StopTimer()
+  base := heapInUse()
+  l := NewInMemoryLoggerWithCapacity(benchMessages, opts...)
+  LoadFrom(l, strings.NewReader(repeatedInput))
+  retained := heapInUse() - base
+  runtime.KeepAlive(l)
+  b.ReportMetric(float64(retained)/benchMessages, "retained-B/msg")
+}
+
+func BenchmarkLoadRepeated(b *testing.B) {
+  benchmarkLoadRepeated(b)
+}
+
+func BenchmarkLoadRepeatedInterned(b *testing.B) {
+  benchmarkLoadRepeated(b, WithInterning(0))
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+  "encoding/json"
+  "time"
+)
+
+// Record is a message as a structured logger stored it.
+type Record struct {
+  Time time.Time // zero if the record had none
+  Level Level
+  Text string
+}
+
+// WithJSONL makes a LocalLogger write each message as one JSON object per
+// line, {"ts":...,"level":...,"msg":...} as JSONFormatter renders it, for
+// log shippers. Its reads decode the lines back into the "msg" values,
+// and MessagesStructured into Records; a line that does not decode is
+// skipped and counted in ParseErrors.
+func WithJSONL() Option {
+  return func(o *options) {
+    o.jsonl = true
+    o.timestamps = true
+    o.formatter = JSONFormatter{}
+  }
+}
+
+// isJSONL reports whether the logger writes JSON lines.
+func (this *LocalLogger) isJSONL() bool {
+  _, ok := this.opts.formatter.(JSONFormatter)
+  return ok && this.opts.jsonl
+}
+
+// parsed reports whether the records in the file must be parsed to get the
+// messages back, and so be read from the start.
+func (this *LocalLogger) parsed() bool {
+  return this.isCSV() || this.isJSONL()
+}
+
+// decodeJSONL decodes a line WithJSONL wrote.
+func decodeJSONL(line string) (jsonLine, bool) {
+  var record jsonLine
+  if err := json.Unmarshal([]byte(line), &record); err != nil {
+    return jsonLine{}, false
+  }
+  return record, true
+}
+
+// jsonlMessage returns the "msg" of a line WithJSONL wrote.
+func jsonlMessage(line string) string {
+  if record, ok := decodeJSONL(line); ok {
+    return record.Msg
+  }
+  return line
+}
+
+// scanJSONL calls fn with the "msg" of each line of the named file,
+// skipping and counting the ones that do not decode.
+func (this *LocalLogger) scanJSONL(filename string, fn func(line string) error) error {
+  return this.scanRaw(filename, func(line string) error {
+    record, ok := decodeJSONL(line)
+    if !ok {
+      this.parseErrors.Add(1)
+      return nil
+    }
+    return fn(record.Msg)
+  })
+}
+
+// ParseErrors returns how many lines reads have skipped for not decoding,
+// counting a line again each time it is read.
+func (this *LocalLogger) ParseErrors() uint64 {
+  return this.parseErrors.Load()
+}
+
+// MessagesStructured returns every record in the file with its time and
+// level: from the fields of a WithJSONL line, skipping those that do not
+// decode as Messages does, or from the columns of a CSVFormatter row.
+// Other lines have them parsed from their start, where WithTimestamp("")
+// and PlainFormatter.ShowLevel put them; a line with neither is returned
+// whole, with the zero Time and Level.
+func (this *LocalLogger) MessagesStructured() ([]Record, error) {
+  if err := this.beginRead(); err != nil {
+    return nil, err
+  }
+  defer this.mu.RUnlock()
+  var records []Record
+  var err error
+  switch {
+  case this.isJSONL():
+    err = this.scanRaw(this.filename, func(line string) error {
+      decoded, ok := decodeJSONL(line)
+      if !ok {
+        this.parseErrors.Add(1)
+        return nil
+      }
+      record := Record{Text: decoded.Msg}
+      record.Level, _ = ParseLevel(decoded.Level)
+      record.Time, _ = time.Parse(time.RFC3339Nano, decoded.Ts)
+      records = append(records, record)
+      return nil
+    })
+  case this.isCSV():
+    err = this.scanCSVRecords(this.filename, func(record Record) error {
+      records = append(records, record)
+      return nil
+    })
+  default:
+    err = this.scanRaw(this.filename, func(line string) error {
+      records = append(records, parseRecord(line))
+      return nil
+    })
+  }
+  if err != nil {
+    return nil, err
+  }
+  return records, nil
+}
+
+// scanCSVRecords calls fn with the Record of each row of the named file.
+func (this *LocalLogger) scanCSVRecords(filename string, fn func(record Record) error) error {
+  file, err := this.openRead(filename)
+  if err != nil {
+    return err
+  }
+  defer file.Close()
+  formatter := this.opts.formatter.(CSVFormatter)
+  return scanCSVRows(file, func(row []string) error {
+    return fn(formatter.record(row))
+  })
+}
+
+// MessagesStructured returns the messages as Records, parsing each as
+// LocalLogger.MessagesStructured does a plain line.
+func (this *InMemoryLogger) MessagesStructured() ([]Record, error) {
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  records := make([]Record, len(messages))
+  for i, mesg := range(messages) {
+    records[i] = parseRecord(mesg)
+  }
+  return records, nil
+}
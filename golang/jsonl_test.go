@@ -0,0 +1,108 @@
+package main
+
+import (
+  "encoding/json"
+  "fmt"
+  "os"
+  "path/filepath"
+  "reflect"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestJSONLRoundTrip(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "app.jsonl")
+  clock := NewManualClock(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+  l, err := NewLocalLogger(filename, true, WithJSONL(), WithClock(clock))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Log(`with "quotes", commas and a \ backslash`)
+  clock.Advance(time.Second)
+  l.Logf(LevelError, "disk %s", "full")
+
+  want := []string{`with "quotes", commas and a \ backslash`, "disk full"}
+  if got, err := l.Messages(); err != nil || !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages = %q, %v; want %q", got, err, want)
+  }
+  records, err := l.MessagesStructured()
+  if err != nil || len(records) != 2 {
+    t.Fatalf("MessagesStructured = %+v, %v", records, err)
+  }
+  if records[1].Level != LevelError || records[1].Text != "disk full" || !records[1].Time.Equal(clock.Now()) {
+    t.Errorf("second record = %+v", records[1])
+  }
+
+  data, _ := os.ReadFile(filename)
+  for _, line := range(strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")) {
+    if !json.Valid([]byte(line)) {
+      t.Errorf("line %q is not JSON", line)
+    }
+  }
+}
+
+func TestJSONLSkipsMalformed(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "app.jsonl")
+  l, err := NewLocalLogger(filename, true, WithJSONL())
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Log("good")
+  f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0)
+  if err != nil {
+    t.Fatal(err)
+  }
+  f.WriteString("not json\n{\"msg\":\n")
+  f.Close()
+  l.Log("also good")
+  if got, err := l.Messages(); err != nil || !reflect.DeepEqual(got, []string{"good", "also good"}) {
+    t.Errorf("Messages = %q, %v; want the two good lines", got, err)
+  }
+  if n := l.ParseErrors(); n != 2 {
+    t.Errorf("ParseErrors = %d, want 2", n)
+  }
+}
+
+func TestMessagesStructuredModes(t *testing.T) {
+  start := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+  modes := map[string][]Option{
+    "JSONL": {WithJSONL()},
+    "CSV": {WithTimestamp(""), WithFormatter(CSVFormatter{})},
+    "plain": {WithTimestamp(""), WithFormatter(PlainFormatter{ShowLevel: true})},
+  }
+  for name, opts := range(modes) {
+    clock := NewManualClock(start)
+    l := newTestLocal(t, append(opts, WithClock(clock))...)
+    var want []Record
+    for i, level := range([]Level{LevelDebug, LevelInfo, LevelWarn, LevelError}) {
+      text := fmt.Sprintf("message %d, with \"quotes\"", i)
+      l.Logf(level, "%s", text)
+      want = append(want, Record{Time: clock.Now(), Level: level, Text: text})
+      clock.Advance(time.Second)
+    }
+    records, err := l.MessagesStructured()
+    if err != nil || len(records) != len(want) {
+      t.Fatalf("%s: MessagesStructured = %+v, %v", name, records, err)
+    }
+    for i, record := range(records) {
+      if !record.Time.Equal(want[i].Time) || record.Level != want[i].Level || record.Text != want[i].Text {
+        t.Errorf("%s: record %d = %+v, want %+v", name, i, record, want[i])
+      }
+    }
+  }
+}
+
+func TestMessagesStructuredUnparsed(t *testing.T) {
+  l := NewInMemoryLogger()
+  l.Log("no time or level here")
+  records, err := l.MessagesStructured()
+  if err != nil || len(records) != 1 {
+    t.Fatalf("MessagesStructured = %+v, %v", records, err)
+  }
+  if want := (Record{Text: "no time or level here"}); records[0] != want {
+    t.Errorf("record = %+v, want %+v", records[0], want)
+  }
+}
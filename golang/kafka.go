@@ -0,0 +1,172 @@
+//go:build kafka
+
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "sync"
+  "time"
+
+  "github.com/segmentio/kafka-go"
+)
+
+// kafkaProducer is the part of *kafka.Writer KafkaLogger uses, so that a
+// test can stand in for the brokers.
+type kafkaProducer interface {
+  WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+  Close() error
+}
+
+// KafkaLogger produces each message as a record on a Kafka topic, the
+// stored line as its value and its level in a "level" header. Messages
+// returns the values produced so far.
+type KafkaLogger struct {
+  mu sync.Mutex
+  producer kafkaProducer
+  topic string
+  opts options
+  minLevel Level
+  sent []string
+  closed bool
+}
+
+var _ LoggerInterface = (*KafkaLogger)(nil)
+
+// NewKafkaLogger produces to topic on the given brokers. Each Log waits
+// until the brokers have acknowledged its record.
+func NewKafkaLogger(brokers []string, topic string, opts ...Option) (*KafkaLogger, error) {
+  if len(brokers) == 0 {
+    return nil, errors.New("log: KafkaLogger needs a broker")
+  }
+  writer := &kafka.Writer{
+    Addr: kafka.TCP(brokers...), // the topic is set on each record
+    BatchTimeout: 10 * time.Millisecond, // each Log is its own batch
+    RequiredAcks: kafka.RequireAll,
+  }
+  return newKafkaLogger(writer, topic, opts...), nil
+}
+
+func newKafkaLogger(producer kafkaProducer, topic string, opts ...Option) *KafkaLogger {
+  return &KafkaLogger{producer: producer, topic: topic, opts: newOptions(opts)}
+}
+
+func (this *KafkaLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *KafkaLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *KafkaLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *KafkaLogger) logEntry(e entry) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.closed {
+    return ErrClosed
+  }
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  if err := this.opts.validateUTF8(&e); err != nil {
+    return err
+  }
+  if e.level < this.minLevel {
+    return nil
+  }
+  ctx := e.ctx
+  if ctx == nil {
+    ctx = context.Background()
+  }
+  line := this.opts.render(e)
+  record := kafka.Message{
+    Topic: this.topic,
+    Value: []byte(line),
+    Headers: []kafka.Header{{Key: "level", Value: []byte(e.level.String())}},
+  }
+  if err := this.producer.WriteMessages(ctx, record); err != nil {
+    return this.opts.handle(writeFailed(err))
+  }
+  this.sent = append(this.sent, line)
+  this.opts.notify(e.level, line)
+  return nil
+}
+
+// SetMinLevel drops subsequent messages below level.
+func (this *KafkaLogger) SetMinLevel(level Level) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.minLevel = level
+}
+
+func (this *KafkaLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *KafkaLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *KafkaLogger) Messages() ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return append([]string(nil), this.sent...), nil
+}
+
+func (this *KafkaLogger) Count() (int, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return len(this.sent), nil
+}
+
+func (this *KafkaLogger) Tail(n int) ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return tailOf(this.sent, n), nil
+}
+
+func (this *KafkaLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return searchIn(this.sent, pattern, useRegexp)
+}
+
+func (this *KafkaLogger) ForEach(fn func(line string) error) error {
+  messages, _ := this.Messages()
+  return forEachIn(messages, fn)
+}
+
+func (this *KafkaLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return pageOf(this.sent, offset, limit)
+}
+
+// Clear forgets the values produced so far; the topic keeps its records.
+func (this *KafkaLogger) Clear() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.sent = nil
+  return nil
+}
+
+// Sync is a no-op: every Log has already been acknowledged.
+func (this *KafkaLogger) Sync() error {
+  return nil
+}
+
+// Close waits for any records still in flight and closes the producer.
+func (this *KafkaLogger) Close() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.closed {
+    return nil
+  }
+  this.closed = true
+  return this.producer.Close()
+}
@@ -0,0 +1,77 @@
+//go:build kafka
+
+package main
+
+import (
+  "context"
+  "errors"
+  "reflect"
+  "testing"
+
+  "github.com/segmentio/kafka-go"
+)
+
+// mockProducer records the records a KafkaLogger produces.
+type mockProducer struct {
+  records []kafka.Message
+  fail error
+  closed bool
+}
+
+func (this *mockProducer) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+  if this.fail != nil {
+    return this.fail
+  }
+  this.records = append(this.records, msgs...)
+  return nil
+}
+
+func (this *mockProducer) Close() error {
+  this.closed = true
+  return nil
+}
+
+func TestKafkaProducesToTopic(t *testing.T) {
+  producer := &mockProducer{}
+  l := newKafkaLogger(producer, "app-logs")
+  l.Log("started")
+  l.Logf(LevelError, "disk %s", "full")
+  if len(producer.records) != 2 {
+    t.Fatalf("produced %d records, want 2", len(producer.records))
+  }
+  for i, want := range([]struct {
+    value, level string
+  }{{"started", "INFO"}, {"disk full", "ERROR"}}) {
+    record := producer.records[i]
+    if record.Topic != "app-logs" || string(record.Value) != want.value {
+      t.Errorf("record %d = %q on %q, want %q on app-logs", i, record.Value, record.Topic, want.value)
+    }
+    if len(record.Headers) != 1 || record.Headers[0].Key != "level" || string(record.Headers[0].Value) != want.level {
+      t.Errorf("record %d headers = %v, want level %s", i, record.Headers, want.level)
+    }
+  }
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"started", "disk full"}) {
+    t.Errorf("Messages = %q", got)
+  }
+  if err := l.Close(); err != nil || !producer.closed {
+    t.Errorf("Close = %v, producer closed %v", err, producer.closed)
+  }
+  if err := l.Log("late"); !errors.Is(err, ErrClosed) {
+    t.Errorf("Log after Close = %v, want ErrClosed", err)
+  }
+}
+
+func TestKafkaProduceFails(t *testing.T) {
+  producer := &mockProducer{fail: errors.New("broker down")}
+  l := newKafkaLogger(producer, "app-logs")
+  if err := l.Log("lost"); !errors.Is(err, ErrWriteFailed) {
+    t.Errorf("Log = %v, want ErrWriteFailed", err)
+  }
+  AssertLoggedCount(t, l, 0)
+}
+
+func TestNewKafkaLoggerNeedsBroker(t *testing.T) {
+  if _, err := NewKafkaLogger(nil, "app-logs"); err == nil {
+    t.Error("NewKafkaLogger succeeded without brokers")
+  }
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+  "fmt"
+  "strings"
+  "time"
+  "unicode"
+)
+
+// Level is the severity of a log message.
+type Level int
+
+const (
+  LevelDebug Level = iota
+  LevelInfo
+  LevelWarn
+  LevelError
+)
+
+var levelNames = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+func (this Level) String() string {
+  if this < LevelDebug || int(this) >= len(levelNames) {
+    return fmt.Sprintf("LEVEL(%d)", int(this))
+  }
+  return levelNames[this]
+}
+
+// ParseLevel converts a level name such as "warn" back into a Level.
+// Matching is case-insensitive and "warning" is accepted for LevelWarn.
+func ParseLevel(name string) (Level, error) {
+  name = strings.ToUpper(strings.TrimSpace(name))
+  if name == "WARNING" {
+    return LevelWarn, nil
+  }
+  for i, levelName := range(levelNames) {
+    if name == levelName {
+      return Level(i), nil
+    }
+  }
+  return LevelDebug, fmt.Errorf("log: unknown level %q", name)
+}
+
+// DetectLevel recognizes the level name a pre-formatted line starts with,
+// such as "ERROR: disk full", "[warn] low space" or "Info ...", in any case
+// and after an RFC 3339 timestamp if there is one. A line that names no
+// level is LevelInfo.
+func DetectLevel(line string) Level {
+  rest := strings.TrimSpace(line)
+  if head, tail, ok := strings.Cut(rest, " "); ok {
+    if _, err := time.Parse(time.RFC3339Nano, head); err == nil {
+      rest = strings.TrimSpace(tail)
+    }
+  }
+  rest = strings.TrimLeft(rest, "[<(")
+  end := strings.IndexFunc(rest, func(r rune) bool {
+    return !unicode.IsLetter(r)
+  })
+  if end < 0 {
+    end = len(rest)
+  }
+  if level, err := ParseLevel(rest[:end]); err == nil {
+    return level
+  }
+  return LevelInfo
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+  "testing"
+)
+
+func TestDetectLevel(t *testing.T) {
+  tests := []struct {
+    line string
+    want Level
+  }{
+    {"ERROR: disk full", LevelError},
+    {"error disk full", LevelError},
+    {"[warn] low space", LevelWarn},
+    {"WARNING: low space", LevelWarn},
+    {"<debug> cache miss", LevelDebug},
+    {"(Info) started", LevelInfo},
+    {"DEBUG", LevelDebug},
+    {"2024-01-02T03:04:05Z ERROR boom", LevelError},
+    {"  WARN: padded", LevelWarn},
+    {"disk full", LevelInfo},
+    {"errors happen", LevelInfo},
+    {"", LevelInfo},
+  }
+  for _, test := range(tests) {
+    if got := DetectLevel(test.line); got != test.want {
+      t.Errorf("DetectLevel(%q) = %v, want %v", test.line, got, test.want)
+    }
+  }
+}
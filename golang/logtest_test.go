@@ -0,0 +1,109 @@
+package main
+
+import (
+  "fmt"
+  "strings"
+  "testing"
+)
+
+// CaptureLogger makes a new InMemoryLogger the Default, for a test of code
+// that logs through Info and the like, and returns it with a function that
+// puts the previous Default back. Tests that capture must not run in
+// parallel with each other.
+func CaptureLogger() (*InMemoryLogger, func()) {
+  previous := Default()
+  capture := NewInMemoryLogger()
+  SetDefault(capture)
+  return capture, func() {
+    SetDefault(previous)
+  }
+}
+
+// AssertLogged fails t unless some message of l contains substring.
+func AssertLogged(t testing.TB, l LoggerInterface, substring string) {
+  t.Helper()
+  found, err := l.Search(substring, false)
+  if err != nil {
+    t.Errorf("reading the log: %v", err)
+  } else if len(found) == 0 {
+    t.Errorf("no message contains %q; messages:\n%s", substring, logDump(l))
+  }
+}
+
+// AssertNotLogged fails t if any message of l contains substring.
+func AssertNotLogged(t testing.TB, l LoggerInterface, substring string) {
+  t.Helper()
+  found, err := l.Search(substring, false)
+  if err != nil {
+    t.Errorf("reading the log: %v", err)
+  } else if len(found) > 0 {
+    t.Errorf("%d messages contain %q, first %q", len(found), substring, found[0])
+  }
+}
+
+// AssertLoggedCount fails t unless l holds exactly n messages.
+func AssertLoggedCount(t testing.TB, l LoggerInterface, n int) {
+  t.Helper()
+  count, err := l.Count()
+  if err != nil {
+    t.Errorf("reading the log: %v", err)
+  } else if count != n {
+    t.Errorf("logged %d messages, want %d; messages:\n%s", count, n, logDump(l))
+  }
+}
+
+// logDump lists l's messages for a failure report, one per line.
+func logDump(l LoggerInterface) string {
+  messages, err := l.Messages()
+  if err != nil {
+    return "(" + err.Error() + ")"
+  }
+  return "\t" + strings.Join(messages, "\n\t")
+}
+
+// recordingTB is a testing.TB that notes failures instead of reporting
+// them, so the assertions themselves can be tested.
+type recordingTB struct {
+  testing.TB
+  failures []string
+}
+
+func (this *recordingTB) Helper() {}
+
+func (this *recordingTB) Errorf(format string, args ...interface{}) {
+  this.failures = append(this.failures, fmt.Sprintf(format, args...))
+}
+
+func TestCaptureLogger(t *testing.T) {
+  outer := NewInMemoryLogger()
+  previous := Default()
+  SetDefault(outer)
+  defer SetDefault(previous)
+
+  capture, restore := CaptureLogger()
+  Info("user %d signed in", 42)
+  Warn("disk at %d%%", 91)
+  restore()
+  if Default() != LoggerInterface(outer) {
+    t.Fatal("restore did not put the previous Default back")
+  }
+  Info("after restore")
+  AssertLogged(t, outer, "after restore")
+
+  AssertLogged(t, capture, "user 42 signed in")
+  AssertNotLogged(t, capture, "after restore")
+  AssertLoggedCount(t, capture, 2)
+}
+
+func TestAssertionsFail(t *testing.T) {
+  l := NewInMemoryLogger()
+  l.Log("present")
+  rec := &recordingTB{TB: t}
+  AssertLogged(rec, l, "absent")
+  AssertNotLogged(rec, l, "present")
+  AssertLoggedCount(rec, l, 3)
+  AssertLogged(rec, l, "present")
+  if len(rec.failures) != 3 {
+    t.Fatalf("got %d failures, want 3: %q", len(rec.failures), rec.failures)
+  }
+}
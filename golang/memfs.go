@@ -0,0 +1,307 @@
+package main
+
+import (
+  "errors"
+  "io"
+  "io/fs"
+  "os"
+  "path"
+  "sort"
+  "strings"
+  "sync"
+  "time"
+)
+
+// MemFS is an FS held in memory, for tests: with WithFS, a LocalLogger's
+// rotation, reopening and compaction run fast and the same every time.
+// Directories are not modeled; any name is a file. An open file keeps
+// its contents through a Rename or Remove, as on Unix.
+type MemFS struct {
+  mu sync.Mutex
+  files map[string]*memData
+}
+
+var _ FS = (*MemFS)(nil)
+
+// memData is the contents of a MemFS file, shared by its open handles.
+type memData struct {
+  data []byte
+  mode os.FileMode
+}
+
+func NewMemFS() *MemFS {
+  return &MemFS{files: make(map[string]*memData)}
+}
+
+func (this *MemFS) Open(name string) (File, error) {
+  return this.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (this *MemFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  d, ok := this.files[name]
+  switch {
+  case ok && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0:
+    return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+  case !ok && flag&os.O_CREATE == 0:
+    return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+  case !ok:
+    d = &memData{mode: perm.Perm()}
+    this.files[name] = d
+  }
+  if flag&os.O_TRUNC != 0 {
+    d.data = nil
+  }
+  return &memFile{fsys: this, name: name, d: d, flag: flag}, nil
+}
+
+func (this *MemFS) Rename(oldpath, newpath string) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  d, ok := this.files[oldpath]
+  if !ok {
+    return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+  }
+  delete(this.files, oldpath)
+  this.files[newpath] = d
+  return nil
+}
+
+func (this *MemFS) Remove(name string) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if _, ok := this.files[name]; !ok {
+    return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+  }
+  delete(this.files, name)
+  return nil
+}
+
+func (this *MemFS) Truncate(name string, size int64) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  d, ok := this.files[name]
+  if !ok {
+    return &fs.PathError{Op: "truncate", Path: name, Err: fs.ErrNotExist}
+  }
+  d.truncate(size)
+  return nil
+}
+
+// ReadFile returns a copy of the named file's contents.
+func (this *MemFS) ReadFile(name string) ([]byte, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  d, ok := this.files[name]
+  if !ok {
+    return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+  }
+  return append([]byte(nil), d.data...), nil
+}
+
+// Names returns the names of the files, sorted.
+func (this *MemFS) Names() []string {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  names := make([]string, 0, len(this.files))
+  for name := range(this.files) {
+    names = append(names, name)
+  }
+  sort.Strings(names)
+  return names
+}
+
+func (this *MemFS) namesWithPrefix(prefix string) ([]string, error) {
+  var names []string
+  for _, name := range(this.Names()) {
+    if strings.HasPrefix(name, prefix) {
+      names = append(names, name)
+    }
+  }
+  return names, nil
+}
+
+func (this *memData) truncate(size int64) {
+  if size <= int64(len(this.data)) {
+    this.data = this.data[:size]
+    return
+  }
+  this.data = append(this.data, make([]byte, size-int64(len(this.data)))...)
+}
+
+
+// memFile is an open MemFS file. Its operations take the MemFS's lock.
+type memFile struct {
+  fsys *MemFS
+  name string
+  d *memData
+  flag int
+  offset int64
+  closed bool
+}
+
+var _ File = (*memFile)(nil)
+
+var errMemFileReadOnly = errors.New("log: MemFS file is not open for writing")
+
+// check returns the error for using the file once it is closed; the
+// caller must hold the lock.
+func (this *memFile) check(op string) error {
+  if this.closed {
+    return &fs.PathError{Op: op, Path: this.name, Err: fs.ErrClosed}
+  }
+  return nil
+}
+
+func (this *memFile) Read(p []byte) (int, error) {
+  this.fsys.mu.Lock()
+  defer this.fsys.mu.Unlock()
+  if err := this.check("read"); err != nil {
+    return 0, err
+  }
+  if this.offset >= int64(len(this.d.data)) {
+    return 0, io.EOF
+  }
+  n := copy(p, this.d.data[this.offset:])
+  this.offset += int64(n)
+  return n, nil
+}
+
+func (this *memFile) ReadAt(p []byte, off int64) (int, error) {
+  this.fsys.mu.Lock()
+  defer this.fsys.mu.Unlock()
+  if err := this.check("read"); err != nil {
+    return 0, err
+  }
+  if off >= int64(len(this.d.data)) {
+    return 0, io.EOF
+  }
+  n := copy(p, this.d.data[off:])
+  if n < len(p) {
+    return n, io.EOF
+  }
+  return n, nil
+}
+
+func (this *memFile) Write(p []byte) (int, error) {
+  this.fsys.mu.Lock()
+  defer this.fsys.mu.Unlock()
+  if err := this.check("write"); err != nil {
+    return 0, err
+  }
+  if this.flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+    return 0, &fs.PathError{Op: "write", Path: this.name, Err: errMemFileReadOnly}
+  }
+  if this.flag&os.O_APPEND != 0 {
+    this.offset = int64(len(this.d.data))
+  }
+  if end := this.offset + int64(len(p)); end > int64(len(this.d.data)) {
+    this.d.truncate(end)
+  }
+  copy(this.d.data[this.offset:], p)
+  this.offset += int64(len(p))
+  return len(p), nil
+}
+
+func (this *memFile) Seek(offset int64, whence int) (int64, error) {
+  this.fsys.mu.Lock()
+  defer this.fsys.mu.Unlock()
+  if err := this.check("seek"); err != nil {
+    return 0, err
+  }
+  switch whence {
+  case io.SeekCurrent:
+    offset += this.offset
+  case io.SeekEnd:
+    offset += int64(len(this.d.data))
+  }
+  if offset < 0 {
+    return 0, &fs.PathError{Op: "seek", Path: this.name, Err: fs.ErrInvalid}
+  }
+  this.offset = offset
+  return offset, nil
+}
+
+func (this *memFile) Close() error {
+  this.fsys.mu.Lock()
+  defer this.fsys.mu.Unlock()
+  if err := this.check("close"); err != nil {
+    return err
+  }
+  this.closed = true
+  return nil
+}
+
+func (this *memFile) Stat() (os.FileInfo, error) {
+  this.fsys.mu.Lock()
+  defer this.fsys.mu.Unlock()
+  if err := this.check("stat"); err != nil {
+    return nil, err
+  }
+  return memFileInfo{name: path.Base(this.name), size: int64(len(this.d.data)), mode: this.d.mode}, nil
+}
+
+// Sync is a no-op: there is no stable storage behind a MemFS.
+func (this *memFile) Sync() error {
+  this.fsys.mu.Lock()
+  defer this.fsys.mu.Unlock()
+  return this.check("sync")
+}
+
+func (this *memFile) Truncate(size int64) error {
+  this.fsys.mu.Lock()
+  defer this.fsys.mu.Unlock()
+  if err := this.check("truncate"); err != nil {
+    return err
+  }
+  if size < 0 {
+    return &fs.PathError{Op: "truncate", Path: this.name, Err: fs.ErrInvalid}
+  }
+  this.d.truncate(size)
+  return nil
+}
+
+// Chmod lets Compact give the rewritten file the original's permissions.
+func (this *memFile) Chmod(mode os.FileMode) error {
+  this.fsys.mu.Lock()
+  defer this.fsys.mu.Unlock()
+  if err := this.check("chmod"); err != nil {
+    return err
+  }
+  this.d.mode = mode.Perm()
+  return nil
+}
+
+
+// memFileInfo describes a MemFS file. Its modification time is always the
+// zero time, so that nothing about a MemFS depends on the clock.
+type memFileInfo struct {
+  name string
+  size int64
+  mode os.FileMode
+}
+
+func (this memFileInfo) Name() string {
+  return this.name
+}
+
+func (this memFileInfo) Size() int64 {
+  return this.size
+}
+
+func (this memFileInfo) Mode() os.FileMode {
+  return this.mode
+}
+
+func (this memFileInfo) ModTime() time.Time {
+  return time.Time{}
+}
+
+func (this memFileInfo) IsDir() bool {
+  return false
+}
+
+func (this memFileInfo) Sys() interface{} {
+  return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+  "reflect"
+  "strings"
+  "testing"
+  "time"
+)
+
+// newMemRotating returns a RotatingLocalLogger on "app.log" in fsys,
+// closed when t ends.
+func newMemRotating(t *testing.T, fsys *MemFS, maxBytes int64, keep int) *RotatingLocalLogger {
+  t.Helper()
+  l, err := NewRotatingLocalLogger("app.log", maxBytes, keep, WithFS(fsys))
+  if err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() { l.Close() })
+  return l
+}
+
+// memContents returns each file in fsys with what it holds.
+func memContents(fsys *MemFS) map[string]string {
+  files := make(map[string]string)
+  for _, name := range(fsys.Names()) {
+    data, _ := fsys.ReadFile(name)
+    files[name] = string(data)
+  }
+  return files
+}
+
+func TestMemFSRotation(t *testing.T) {
+  fsys := NewMemFS()
+  l := newMemRotating(t, fsys, 10, 2)
+  for _, mesg := range([]string{"aaaa", "bbbb", "cccc", "dddd", "eeee"}) {
+    l.Log(mesg)
+  }
+  want := map[string]string{
+    "app.log": "eeee\n",
+    "app.log.1": "aaaa\nbbbb\n",
+    "app.log.2": "cccc\ndddd\n",
+  }
+  if got := memContents(fsys); !reflect.DeepEqual(got, want) {
+    t.Errorf("files = %q, want %q", got, want)
+  }
+  l.IncludeRotated = true
+  all := []string{"aaaa", "bbbb", "cccc", "dddd", "eeee"}
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, all) {
+    t.Errorf("Messages() with IncludeRotated = %q, want %q", got, all)
+  }
+
+  l.Log("ffff")
+  l.Log("gggg") // rotates a third time, dropping the oldest file
+  want = map[string]string{
+    "app.log": "gggg\n",
+    "app.log.2": "cccc\ndddd\n",
+    "app.log.3": "eeee\nffff\n",
+  }
+  if got := memContents(fsys); !reflect.DeepEqual(got, want) {
+    t.Errorf("after a third rotation files = %q, want %q", got, want)
+  }
+}
+
+func TestMemFSRotationResumesNumbering(t *testing.T) {
+  fsys := NewMemFS()
+  first := newMemRotating(t, fsys, 10, 5)
+  for _, mesg := range([]string{"aaaaaaaa", "bbbbbbbb", "cccccccc"}) {
+    first.Log(mesg)
+  }
+  first.Close()
+  second := newMemRotating(t, fsys, 10, 5)
+  second.Log("dddddddd")
+  second.IncludeRotated = true
+  want := []string{"aaaaaaaa", "bbbbbbbb", "cccccccc", "dddddddd"}
+  if got, _ := second.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages() after reopening = %q, want %q", got, want)
+  }
+  if data, _ := fsys.ReadFile("app.log.3"); string(data) != "cccccccc\n" {
+    t.Errorf("app.log.3 holds %q, want the file rotated after reopening", data)
+  }
+}
+
+func TestMemFSRotationKeepNone(t *testing.T) {
+  fsys := NewMemFS()
+  l := newMemRotating(t, fsys, 10, 0)
+  l.Log("aaaaaaaa")
+  l.Log("bbbbbbbb")
+  if got := memContents(fsys); !reflect.DeepEqual(got, map[string]string{"app.log": "bbbbbbbb\n"}) {
+    t.Errorf("files = %q, want only the active one", got)
+  }
+}
+
+func TestMemFSReopenAfterRename(t *testing.T) {
+  fsys := NewMemFS()
+  l := newMemRotating(t, fsys, 1<<20, 1)
+  l.Log("before")
+  if err := fsys.Rename("app.log", "app.log.old"); err != nil {
+    t.Fatal(err)
+  }
+  l.Log("still old") // the open handle follows the rename
+  if err := l.Reopen(); err != nil {
+    t.Fatal(err)
+  }
+  l.Log("after")
+  want := map[string]string{
+    "app.log": "after\n",
+    "app.log.old": "before\nstill old\n",
+  }
+  if got := memContents(fsys); !reflect.DeepEqual(got, want) {
+    t.Errorf("files = %q, want %q", got, want)
+  }
+}
+
+func TestMemFSCompact(t *testing.T) {
+  fsys := NewMemFS()
+  l, err := NewLocalLogger("app.log", true, WithFS(fsys), WithGzip())
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  for i := 0; i < 20; i++ {
+    l.Log(strings.Repeat("x", 40))
+    l.Sync()
+  }
+  before, _ := fsys.ReadFile("app.log")
+  if err := l.Compact(); err != nil {
+    t.Fatal(err)
+  }
+  after, _ := fsys.ReadFile("app.log")
+  if len(after) >= len(before) {
+    t.Errorf("Compact left %d bytes from %d", len(after), len(before))
+  }
+  if names := fsys.Names(); !reflect.DeepEqual(names, []string{"app.log"}) {
+    t.Errorf("files after Compact = %q, want no temporary left", names)
+  }
+  AssertLoggedCount(t, l, 20)
+}
+
+func TestMemFSTimeRotation(t *testing.T) {
+  fsys := NewMemFS()
+  clock := NewManualClock(time.Date(2024, 3, 9, 23, 59, 0, 0, time.UTC))
+  l, err := NewTimeRotatingLocalLogger("app.log", WithFS(fsys), WithClock(clock))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Log("late")
+  clock.Advance(2 * time.Minute)
+  l.Log("early")
+  want := map[string]string{
+    "app-2024-03-09.log": "late\n",
+    "app-2024-03-10.log": "early\n",
+  }
+  if got := memContents(fsys); !reflect.DeepEqual(got, want) {
+    t.Errorf("files = %q, want %q", got, want)
+  }
+}
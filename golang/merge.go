@@ -0,0 +1,89 @@
+package main
+
+import (
+  "container/heap"
+  "time"
+)
+
+// MergeSorted reads each logger's messages and merges them into one slice
+// in order of their RFC 3339 timestamp prefixes, as WithTimestamp("")
+// writes them. Each logger's messages are assumed to be in order already.
+// A message without a timestamp sorts with the one before it in its
+// source; ties go to the earlier logger.
+func MergeSorted(loggers ...LoggerInterface) ([]string, error) {
+  var h mergeHeap
+  total := 0
+  for i, logger := range(loggers) {
+    messages, err := logger.Messages()
+    if err != nil {
+      return nil, err
+    }
+    total += len(messages)
+    if len(messages) > 0 {
+      h = append(h, &mergeSource{index: i, messages: messages})
+    }
+  }
+  for _, src := range(h) {
+    src.stamp()
+  }
+  heap.Init(&h)
+
+  merged := make([]string, 0, total)
+  for len(h) > 0 {
+    src := h[0]
+    merged = append(merged, src.messages[src.next])
+    src.next++
+    if src.next == len(src.messages) {
+      heap.Pop(&h)
+      continue
+    }
+    src.stamp()
+    heap.Fix(&h, 0)
+  }
+  return merged, nil
+}
+
+// mergeSource is one logger's messages, consumed from next on.
+type mergeSource struct {
+  index int
+  messages []string
+  next int
+  at time.Time // when messages[next] was logged, as far as is known
+}
+
+// stamp sets at from the next message, keeping the previous time if it
+// has none.
+func (this *mergeSource) stamp() {
+  if t, _, _ := parseLine(this.messages[this.next]); !t.IsZero() {
+    this.at = t
+  }
+}
+
+// mergeHeap orders the sources by the time of their next message.
+type mergeHeap []*mergeSource
+
+func (this mergeHeap) Len() int {
+  return len(this)
+}
+
+func (this mergeHeap) Less(i, j int) bool {
+  if !this[i].at.Equal(this[j].at) {
+    return this[i].at.Before(this[j].at)
+  }
+  return this[i].index < this[j].index
+}
+
+func (this mergeHeap) Swap(i, j int) {
+  this[i], this[j] = this[j], this[i]
+}
+
+func (this *mergeHeap) Push(x interface{}) {
+  *this = append(*this, x.(*mergeSource))
+}
+
+func (this *mergeHeap) Pop() interface{} {
+  old := *this
+  src := old[len(old)-1]
+  *this = old[:len(old)-1]
+  return src
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+  "path/filepath"
+  "reflect"
+  "testing"
+  "time"
+)
+
+func TestMergeSortedInterleavedFiles(t *testing.T) {
+  dir := t.TempDir()
+  clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+  var files []*LocalLogger
+  for _, name := range([]string{"web.log", "db.log"}) {
+    l, err := NewLocalLogger(filepath.Join(dir, name), true, WithTimestamp(""), WithClock(clock))
+    if err != nil {
+      t.Fatal(err)
+    }
+    defer l.Close()
+    files = append(files, l)
+  }
+  var want []string
+  for i, source := range([]int{0, 1, 1, 0, 1, 0, 0}) {
+    mesg := "event " + string(rune('a'+i))
+    files[source].Log(mesg)
+    want = append(want, clock.Now().Format(time.RFC3339Nano)+" "+mesg)
+    clock.Advance(time.Duration(i+1) * time.Millisecond)
+  }
+  got, err := MergeSorted(files[0], files[1])
+  if err != nil || !reflect.DeepEqual(got, want) {
+    t.Errorf("MergeSorted = %q, %v; want %q", got, err, want)
+  }
+}
+
+func TestMergeSortedUntimestamped(t *testing.T) {
+  a := loggerOf(
+    "2024-01-01T12:00:00Z panic: boom",
+    "goroutine 1 [running]:",
+    "main.main()",
+    "2024-01-01T12:00:03Z restarted",
+  )
+  b := loggerOf(
+    "2024-01-01T12:00:01Z request one",
+    "2024-01-01T12:00:02Z request two",
+  )
+  want := []string{
+    "2024-01-01T12:00:00Z panic: boom",
+    "goroutine 1 [running]:",
+    "main.main()",
+    "2024-01-01T12:00:01Z request one",
+    "2024-01-01T12:00:02Z request two",
+    "2024-01-01T12:00:03Z restarted",
+  }
+  if got, err := MergeSorted(a, b); err != nil || !reflect.DeepEqual(got, want) {
+    t.Errorf("MergeSorted = %q, %v; want %q", got, err, want)
+  }
+  plainA := loggerOf("a1", "a2")
+  plainB := loggerOf("b1", "b2")
+  if got, _ := MergeSorted(plainA, plainB); !reflect.DeepEqual(got, []string{"a1", "a2", "b1", "b2"}) {
+    t.Errorf("MergeSorted without timestamps = %q, want each logger in order", got)
+  }
+}
+
+func TestMergeSortedEqualTimestampsStable(t *testing.T) {
+  a := loggerOf("2024-01-01T12:00:00Z a1", "2024-01-01T12:00:00Z a2", "2024-01-01T12:00:01Z a3")
+  b := loggerOf("2024-01-01T12:00:00Z b1", "2024-01-01T12:00:01Z b2")
+  c := loggerOf("2024-01-01T12:00:00Z c1")
+  want := []string{
+    "2024-01-01T12:00:00Z a1",
+    "2024-01-01T12:00:00Z a2",
+    "2024-01-01T12:00:00Z b1",
+    "2024-01-01T12:00:00Z c1",
+    "2024-01-01T12:00:01Z a3",
+    "2024-01-01T12:00:01Z b2",
+  }
+  if got, err := MergeSorted(a, b, c); err != nil || !reflect.DeepEqual(got, want) {
+    t.Errorf("MergeSorted = %q, %v; want %q", got, err, want)
+  }
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+  "bytes"
+  "math"
+  "os"
+  "regexp"
+  "runtime/debug"
+)
+
+// mmapMinSize is the smallest file that Count, Tail and Search map into
+// memory rather than read through a buffer; below it the mapping costs
+// more than it saves.
+const mmapMinSize = 1 << 20
+
+// withMapped calls fn with the records of the named file mapped into
+// memory, up to the end of the last one if SkipTrailingPartial is set,
+// and reports whether it could. It cannot for a compressed or encrypted
+// file, one smaller than mmapMinSize, or where mmap is unavailable; the
+// caller then reads the file the usual way. The mapping is of the file as
+// it stood when opened. Writes through this logger wait for the read, but
+// another process may cut the file short meanwhile: the fault that
+// causes is recovered and withMapped reports false, so fn must keep what
+// it finds to itself until withMapped returns true.
+func (this *LocalLogger) withMapped(filename string, fn func(data []byte)) (mapped bool) {
+  if this.opts.gzip || this.aead != nil || !this.isOS() {
+    return false
+  }
+  file, err := os.Open(filename)
+  if err != nil {
+    return false
+  }
+  defer file.Close()
+  info, err := file.Stat()
+  if err != nil || info.Size() < mmapMinSize || info.Size() > math.MaxInt {
+    return false
+  }
+  data, unmap, err := mapFile(file, info.Size())
+  if err != nil {
+    return false
+  }
+  defer unmap()
+
+  defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+  defer func() {
+    if r := recover(); r != nil {
+      mapped = false
+    }
+  }()
+  if this.SkipTrailingPartial {
+    data = data[:bytes.LastIndexByte(data, this.opts.recordSeparator())+1]
+  }
+  fn(data)
+  return true
+}
+
+// eachRecord calls fn with each record in data, without its separator,
+// and for newline-separated records without a carriage return before it,
+// as bufio.ScanLines splits them. A last record without its separator
+// counts. The slices alias data.
+func eachRecord(data []byte, sep byte, fn func(record []byte)) {
+  for len(data) > 0 {
+    i := bytes.IndexByte(data, sep)
+    record := data
+    data = nil
+    if i >= 0 {
+      record, data = record[:i], record[i+1:]
+    }
+    if sep == '\n' {
+      record = bytes.TrimSuffix(record, []byte{'\r'})
+    }
+    fn(record)
+  }
+}
+
+// countMapped is countLines over a mapped file.
+func (this *LocalLogger) countMapped(filename string) (int, bool) {
+  sep := this.opts.recordSeparator()
+  count := 0
+  mapped := this.withMapped(filename, func(data []byte) {
+    count = bytes.Count(data, []byte{sep})
+    if len(data) > 0 && data[len(data)-1] != sep {
+      count++
+    }
+  })
+  return count, mapped
+}
+
+// tailMapped is tailLines over a mapped file, looking back from the end
+// for the start of the last n records.
+func (this *LocalLogger) tailMapped(filename string, n int) ([]string, bool) {
+  sep := this.opts.recordSeparator()
+  var lines []string
+  mapped := this.withMapped(filename, func(data []byte) {
+    end := len(data)
+    if end > 0 && data[end-1] == sep {
+      end-- // the separator ending the file starts no record
+    }
+    from := 0
+    for i := 0; i < n; i++ {
+      j := bytes.LastIndexByte(data[:end], sep)
+      if j < 0 {
+        from = 0
+        break
+      }
+      end, from = j, j+1
+    }
+    eachRecord(data[from:], sep, func(record []byte) {
+      lines = append(lines, string(record))
+    })
+  })
+  return lines, mapped
+}
+
+// searchMapped is Search over a mapped file, matching the records in
+// place so that only the ones that match are copied.
+func (this *LocalLogger) searchMapped(filename string, pattern string, useRegexp bool) ([]string, bool, error) {
+  literal := []byte(pattern)
+  match := func(record []byte) bool {
+    return bytes.Contains(record, literal)
+  }
+  if useRegexp {
+    re, err := regexp.Compile(pattern)
+    if err != nil {
+      return nil, true, err
+    }
+    match = re.Match
+  }
+  var found []string
+  mapped := this.withMapped(filename, func(data []byte) {
+    eachRecord(data, this.opts.recordSeparator(), func(record []byte) {
+      if match(record) {
+        found = append(found, string(record))
+      }
+    })
+  })
+  return found, mapped, nil
+}
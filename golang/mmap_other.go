@@ -0,0 +1,14 @@
+//go:build !unix
+
+package main
+
+import (
+  "errors"
+  "os"
+)
+
+// mapFile always fails where there is no mmap, so that reads take the
+// buffered path.
+func mapFile(file *os.File, size int64) ([]byte, func() error, error) {
+  return nil, nil, errors.New("log: mmap is not supported on this platform")
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+  "bufio"
+  "flag"
+  "fmt"
+  "os"
+  "path/filepath"
+  "reflect"
+  "testing"
+)
+
+var mmapFixtureSize = flag.Int64("mmapfixture", 1<<30, "size in bytes of the file the mmap benchmarks read")
+
+// unmappedFS is the OS file system under another type, so that a
+// LocalLogger on it reads its files the usual way instead of mapping them.
+type unmappedFS struct {
+  osFS
+}
+
+// writeFixture writes numbered lines to the named file until it holds at
+// least size bytes, and returns how many it wrote.
+func writeFixture(t testing.TB, filename string, size int64) int {
+  t.Helper()
+  file, err := os.Create(filename)
+  if err != nil {
+    t.Fatal(err)
+  }
+  w := bufio.NewWriterSize(file, 1<<20)
+  n := 0
+  for written := int64(0); written < size; n++ {
+    m, _ := fmt.Fprintf(w, "%012d GET /api/v1/orders status=200 latency_ms=%03d\n", n, n%1000)
+    written += int64(m)
+  }
+  if err := w.Flush(); err != nil {
+    t.Fatal(err)
+  }
+  if err := file.Close(); err != nil {
+    t.Fatal(err)
+  }
+  return n
+}
+
+// mappedAndNot returns two loggers on the same file, the first free to
+// map it and the second not.
+func mappedAndNot(t testing.TB, filename string) (*LocalLogger, *LocalLogger) {
+  t.Helper()
+  mapped, err := NewLocalLogger(filename, false)
+  if err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() { mapped.Close() })
+  unmapped, err := NewLocalLogger(filename, false, WithFS(unmappedFS{}))
+  if err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() { unmapped.Close() })
+  return mapped, unmapped
+}
+
+func TestMappedReadsMatch(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "big.log")
+  lines := writeFixture(t, filename, 2*mmapMinSize)
+  mapped, unmapped := mappedAndNot(t, filename)
+  if n, err := mapped.Count(); err != nil || n != lines {
+    t.Errorf("mapped Count = %d, %v; want %d", n, err, lines)
+  }
+  if n, _ := unmapped.Count(); n != lines {
+    t.Errorf("unmapped Count = %d, want %d", n, lines)
+  }
+  for _, n := range([]int{1, 3, 1000}) {
+    got, _ := mapped.Tail(n)
+    want, _ := unmapped.Tail(n)
+    if len(got) != n || !reflect.DeepEqual(got, want) {
+      t.Errorf("mapped Tail(%d) = %d lines, differing from the unmapped read", n, len(got))
+    }
+  }
+  for _, search := range([]struct {
+    pattern string
+    regexp bool
+  }{{"latency_ms=999", false}, {`^0+4\d{3} `, true}}) {
+    got, _ := mapped.Search(search.pattern, search.regexp)
+    want, _ := unmapped.Search(search.pattern, search.regexp)
+    if len(got) == 0 || !reflect.DeepEqual(got, want) {
+      t.Errorf("mapped Search(%q) found %d lines, differing from the unmapped read", search.pattern, len(got))
+    }
+  }
+}
+
+func TestMappedSkipsTrailingPartial(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "big.log")
+  lines := writeFixture(t, filename, 2*mmapMinSize)
+  file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0)
+  if err != nil {
+    t.Fatal(err)
+  }
+  file.WriteString("cut sho")
+  file.Close()
+  mapped, unmapped := mappedAndNot(t, filename)
+  if n, _ := mapped.Count(); n != lines+1 {
+    t.Errorf("Count = %d, want %d with the partial line", n, lines+1)
+  }
+  mapped.SkipTrailingPartial = true
+  unmapped.SkipTrailingPartial = true
+  if n, _ := mapped.Count(); n != lines {
+    t.Errorf("Count with SkipTrailingPartial = %d, want %d", n, lines)
+  }
+  got, _ := mapped.Tail(2)
+  want, _ := unmapped.Tail(2)
+  if !reflect.DeepEqual(got, want) {
+    t.Errorf("Tail(2) with SkipTrailingPartial = %q, want %q", got, want)
+  }
+}
+
+// benchmarkFixture returns a logger on a file of -mmapfixture bytes,
+// mapped or not.
+func benchmarkFixture(b *testing.B, mapped bool) *LocalLogger {
+  filename := filepath.Join(b.TempDir(), "big.log")
+  writeFixture(b, filename, *mmapFixtureSize)
+  m, u := mappedAndNot(b, filename)
+  b.ReportAllocs()
+  b.SetBytes(*mmapFixtureSize)
+  b.ResetTimer()
+  if mapped {
+    return m
+  }
+  return u
+}
+
+func BenchmarkCountMapped(b *testing.B) {
+  l := benchmarkFixture(b, true)
+  for i := 0; i < b.N; i++ {
+    l.Count()
+  }
+}
+
+func BenchmarkCountUnmapped(b *testing.B) {
+  l := benchmarkFixture(b, false)
+  for i := 0; i < b.N; i++ {
+    l.Count()
+  }
+}
+
+func BenchmarkTailMapped(b *testing.B) {
+  l := benchmarkFixture(b, true)
+  for i := 0; i < b.N; i++ {
+    l.Tail(100)
+  }
+}
+
+func BenchmarkTailUnmapped(b *testing.B) {
+  l := benchmarkFixture(b, false)
+  for i := 0; i < b.N; i++ {
+    l.Tail(100)
+  }
+}
+
+func BenchmarkSearchMapped(b *testing.B) {
+  l := benchmarkFixture(b, true)
+  for i := 0; i < b.N; i++ {
+    l.Search("latency_ms=999", false)
+  }
+}
+
+func BenchmarkSearchUnmapped(b *testing.B) {
+  l := benchmarkFixture(b, false)
+  for i := 0; i < b.N; i++ {
+    l.Search("latency_ms=999", false)
+  }
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package main
+
+import (
+  "os"
+  "syscall"
+)
+
+// mapFile maps the first size bytes of file into memory, read-only. The
+// returned function unmaps them.
+func mapFile(file *os.File, size int64) ([]byte, func() error, error) {
+  data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+  if err != nil {
+    return nil, nil, err
+  }
+  return data, func() error {
+    return syscall.Munmap(data)
+  }, nil
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package main
+
+import (
+  "path/filepath"
+  "testing"
+)
+
+func TestLargeFileIsMapped(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "big.log")
+  lines := writeFixture(t, filename, 2*mmapMinSize)
+  mapped, unmapped := mappedAndNot(t, filename)
+  if n, ok := mapped.countMapped(filename); !ok || n != lines {
+    t.Errorf("countMapped = %d, %t; want %d, true", n, ok, lines)
+  }
+  if _, ok := unmapped.countMapped(filename); ok {
+    t.Error("a logger off the OS file system mapped its file")
+  }
+  small := filepath.Join(t.TempDir(), "small.log")
+  writeFixture(t, small, mmapMinSize/2)
+  if _, ok := mapped.countMapped(small); ok {
+    t.Error("a file under mmapMinSize was mapped")
+  }
+}
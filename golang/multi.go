@@ -0,0 +1,176 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "strconv"
+  "sync"
+)
+
+// MultiLogger fans every message out to a set of loggers.
+type MultiLogger struct {
+  loggers []LoggerInterface
+
+  // Set by NewSequencedMultiLogger.
+  sequenced bool
+  showSeq bool
+  mu sync.Mutex // held across a sequenced dispatch
+  seq uint64
+
+  failures writeFailures
+}
+
+var _ LoggerInterface = (*MultiLogger)(nil)
+
+func NewMultiLogger(loggers ...LoggerInterface) *MultiLogger {
+  return &MultiLogger{loggers: loggers}
+}
+
+// NewSequencedMultiLogger returns a MultiLogger that numbers the messages
+// 1, 2, ... and hands each one to every child before taking the next, so
+// that concurrent Logs reach all children in the same order. A child that
+// defers its writes, such as an AsyncLogger with one worker, keeps that
+// order. With showSeq set, each message starts with "#N ".
+func NewSequencedMultiLogger(showSeq bool, loggers ...LoggerInterface) *MultiLogger {
+  return &MultiLogger{loggers: loggers, sequenced: true, showSeq: showSeq}
+}
+
+// Log writes to every child, even after one of them fails, and returns
+// the failures joined together.
+func (this *MultiLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *MultiLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *MultiLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *MultiLogger) logEntry(e entry) error {
+  if this.sequenced {
+    this.mu.Lock()
+    defer this.mu.Unlock()
+    this.seq++
+    if this.showSeq {
+      e.mesg = "#" + strconv.FormatUint(this.seq, 10) + " " + e.mesg
+    }
+  }
+  var errs []error
+  for _, logger := range(this.loggers) {
+    err := logEntry(logger, e)
+    this.failures.add(err)
+    errs = append(errs, err)
+  }
+  return errors.Join(errs...)
+}
+
+// LastError returns the most recent error a child returned for a message,
+// or nil if none has failed since ResetErrors.
+func (this *MultiLogger) LastError() error {
+  return this.failures.lastError()
+}
+
+// FailedWrites returns how many writes to a child have failed since
+// ResetErrors, counting a message once for each child it failed on.
+func (this *MultiLogger) FailedWrites() uint64 {
+  return this.failures.n.Load()
+}
+
+// ResetErrors clears LastError and FailedWrites.
+func (this *MultiLogger) ResetErrors() {
+  this.failures.reset()
+}
+
+func (this *MultiLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *MultiLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+// Messages returns the concatenation of the children's messages, in the
+// order the children were given to NewMultiLogger.
+func (this *MultiLogger) Messages() ([]string, error) {
+  var messages []string
+  for _, logger := range(this.loggers) {
+    childMessages, err := logger.Messages()
+    if err != nil {
+      return nil, err
+    }
+    messages = append(messages, childMessages...)
+  }
+  return messages, nil
+}
+
+func (this *MultiLogger) Count() (int, error) {
+  total := 0
+  for _, logger := range(this.loggers) {
+    n, err := logger.Count()
+    if err != nil {
+      return 0, err
+    }
+    total += n
+  }
+  return total, nil
+}
+
+// Tail returns the last n of the messages Messages would return.
+func (this *MultiLogger) Tail(n int) ([]string, error) {
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return tailOf(messages, n), nil
+}
+
+func (this *MultiLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return searchIn(messages, pattern, useRegexp)
+}
+
+// ForEach visits each child's messages in turn, as Messages orders them.
+func (this *MultiLogger) ForEach(fn func(line string) error) error {
+  for _, logger := range(this.loggers) {
+    if err := logger.ForEach(fn); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+// MessagesPage pages through the children's messages as ForEach visits them.
+func (this *MultiLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return pageFrom(this.ForEach, offset, limit)
+}
+
+func (this *MultiLogger) Clear() error {
+  var errs []error
+  for _, logger := range(this.loggers) {
+    errs = append(errs, logger.Clear())
+  }
+  return errors.Join(errs...)
+}
+
+func (this *MultiLogger) Sync() error {
+  var errs []error
+  for _, logger := range(this.loggers) {
+    errs = append(errs, logger.Sync())
+  }
+  return errors.Join(errs...)
+}
+
+func (this *MultiLogger) Close() error {
+  var errs []error
+  for _, logger := range(this.loggers) {
+    errs = append(errs, logger.Close())
+  }
+  return errors.Join(errs...)
+}
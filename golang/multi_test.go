@@ -0,0 +1,51 @@
+package main
+
+import (
+  "fmt"
+  "reflect"
+  "strings"
+  "sync"
+  "testing"
+)
+
+func TestSequencedChildrenAgree(t *testing.T) {
+  fast := NewInMemoryLogger()
+  slowInner := NewInMemoryLogger()
+  slow := NewAsyncLogger(slowInner)
+  l := NewSequencedMultiLogger(true, fast, slow)
+  var wg sync.WaitGroup
+  for g := 0; g < 8; g++ {
+    wg.Add(1)
+    go func() {
+      defer wg.Done()
+      for i := 0; i < 200; i++ {
+        l.Logf(LevelInfo, "goroutine %d message %d", g, i)
+      }
+    }()
+  }
+  wg.Wait()
+  slow.Close()
+  got, _ := fast.Messages()
+  if want, _ := slowInner.Messages(); !reflect.DeepEqual(got, want) {
+    t.Fatal("the children saw the messages in different orders")
+  }
+  if len(got) != 8*200 {
+    t.Fatalf("got %d messages, want %d", len(got), 8*200)
+  }
+  for i, mesg := range(got) {
+    if prefix := fmt.Sprintf("#%d ", i+1); !strings.HasPrefix(mesg, prefix) {
+      t.Fatalf("message %d = %q, want it numbered %q", i, mesg, prefix)
+    }
+  }
+}
+
+func TestMultiJoinsChildErrors(t *testing.T) {
+  closed := NewInMemoryLogger()
+  closed.Close()
+  open := NewInMemoryLogger()
+  l := NewMultiLogger(closed, open)
+  if err := l.Log("x"); err == nil || l.FailedWrites() != 1 {
+    t.Errorf("Log = %v with %d failed writes, want ErrClosed once", err, l.FailedWrites())
+  }
+  AssertLoggedCount(t, open, 1)
+}
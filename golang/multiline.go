@@ -0,0 +1,109 @@
+package main
+
+import (
+  "strings"
+  "time"
+)
+
+// MultilinePolicy is what InMemoryLoggers and LocalLoggers do with a
+// message that has a newline in it, which a file of newline-separated
+// records would otherwise read back as several messages.
+type MultilinePolicy int
+
+const (
+  MultilineEscape MultilinePolicy = iota // write each newline as `\n`, keeping one record
+  MultilineSplit // log each line of the message as a message of its own
+  MultilineReject // refuse the message, returning ErrMultiline
+  MultilineKeep // store the message as it is, as the loggers once did
+)
+
+// WithMultilinePolicy sets the MultilinePolicy of an InMemoryLogger, a
+// LocalLogger or a SpillingLogger's file. The default is MultilineEscape,
+// so that the two agree on such a message, but MultilineKeep for a
+// logger WithRecordSeparator other than a newline, whose records keep
+// them whole. The string values of fields are escaped under Split as
+// well, as the lines share them.
+func WithMultilinePolicy(policy MultilinePolicy) Option {
+  return func(o *options) {
+    o.multiline = policy
+    o.multilineSet = true
+  }
+}
+
+// multilinePolicy is the policy set WithMultilinePolicy, or the default.
+func (this *options) multilinePolicy() MultilinePolicy {
+  if this.multilineSet {
+    return this.multiline
+  }
+  if this.recordSeparator() != '\n' {
+    return MultilineKeep
+  }
+  return MultilineEscape
+}
+
+// splitLines applies the MultilinePolicy to e, returning the entries to
+// log in its place.
+func (this *options) splitLines(e entry) ([]entry, error) {
+  policy := this.multilinePolicy()
+  if policy == MultilineKeep || !multiline(e) {
+    return []entry{e}, nil
+  }
+  if policy == MultilineReject {
+    return nil, this.handle(ErrMultiline)
+  }
+  if e.fields != nil {
+    fields := make(Fields, len(e.fields))
+    for k, v := range(e.fields) {
+      if s, ok := v.(string); ok {
+        v = escapeNewlines(s)
+      }
+      fields[escapeNewlines(k)] = v
+    }
+    e.fields = fields
+  }
+  if policy != MultilineSplit {
+    e.mesg = escapeNewlines(e.mesg)
+    return []entry{e}, nil
+  }
+  lines := strings.Split(e.mesg, "\n")
+  entries := make([]entry, len(lines))
+  for i, line := range(lines) {
+    if i < len(lines)-1 {
+      line = strings.TrimSuffix(line, "\r") // a CRLF ends the line, as reads of the file take it
+    }
+    entries[i] = e
+    entries[i].mesg = line
+  }
+  return entries, nil
+}
+
+// renderEach renders entries, all logged at now.
+func (this *options) renderEach(entries []entry, now time.Time) []string {
+  lines := make([]string, len(entries))
+  for i, e := range(entries) {
+    lines[i] = this.renderAt(e, now)
+  }
+  return lines
+}
+
+// multiline reports whether e's message, field names or string field
+// values have a newline in them.
+func multiline(e entry) bool {
+  if strings.Contains(e.mesg, "\n") {
+    return true
+  }
+  for k, v := range(e.fields) {
+    if strings.Contains(k, "\n") {
+      return true
+    }
+    if s, ok := v.(string); ok && strings.Contains(s, "\n") {
+      return true
+    }
+  }
+  return false
+}
+
+// escapeNewlines writes each newline in s as a backslash and an n.
+func escapeNewlines(s string) string {
+  return strings.ReplaceAll(s, "\n", `\n`)
+}
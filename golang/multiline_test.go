@@ -0,0 +1,70 @@
+package main
+
+import (
+  "errors"
+  "reflect"
+  "testing"
+)
+
+// storedBoth logs mesg to an InMemoryLogger and a LocalLogger made with
+// opts and returns what each reads back, failing t unless Log returned
+// wantErr from both.
+func storedBoth(t *testing.T, mesg string, wantErr error, opts ...Option) map[string][]string {
+  t.Helper()
+  stored := make(map[string][]string)
+  for name, l := range(bothLoggers(t, opts...)) {
+    if err := l.Log(mesg); !errors.Is(err, wantErr) {
+      t.Errorf("%s: Log = %v, want %v", name, err, wantErr)
+    }
+    stored[name], _ = l.Messages()
+  }
+  return stored
+}
+
+func TestMultilinePoliciesAgree(t *testing.T) {
+  tests := []struct {
+    name string
+    mesg string
+    opts []Option
+    err error
+    want []string
+  }{
+    {"default", "first\nsecond", nil, nil, []string{`first\nsecond`}},
+    {"Escape", "first\nsecond", []Option{WithMultilinePolicy(MultilineEscape)}, nil, []string{`first\nsecond`}},
+    {"Split", "first\nsecond", []Option{WithMultilinePolicy(MultilineSplit)}, nil, []string{"first", "second"}},
+    {"Split CRLF", "first\r\nsecond", []Option{WithMultilinePolicy(MultilineSplit)}, nil, []string{"first", "second"}},
+    {"Reject", "first\nsecond", []Option{WithMultilinePolicy(MultilineReject)}, ErrMultiline, nil},
+    {"Reject one line", "only", []Option{WithMultilinePolicy(MultilineReject)}, nil, []string{"only"}},
+  }
+  for _, test := range(tests) {
+    stored := storedBoth(t, test.mesg, test.err, test.opts...)
+    if !reflect.DeepEqual(stored["InMemoryLogger"], test.want) || !reflect.DeepEqual(stored["LocalLogger"], test.want) {
+      t.Errorf("%s: memory holds %q and the file %q, want both %q", test.name, stored["InMemoryLogger"], stored["LocalLogger"], test.want)
+    }
+  }
+}
+
+func TestMultilineKeepDiffers(t *testing.T) {
+  stored := storedBoth(t, "first\nsecond", nil, WithMultilinePolicy(MultilineKeep))
+  if got := stored["InMemoryLogger"]; !reflect.DeepEqual(got, []string{"first\nsecond"}) {
+    t.Errorf("memory holds %q, want the message whole", got)
+  }
+  if got := stored["LocalLogger"]; !reflect.DeepEqual(got, []string{"first", "second"}) {
+    t.Errorf("the file holds %q, want it read back as two lines", got)
+  }
+}
+
+func TestMultilineFields(t *testing.T) {
+  for _, policy := range([]MultilinePolicy{MultilineEscape, MultilineSplit}) {
+    fields := Fields{"stack": "at a\nat b"}
+    for name, l := range(bothLoggers(t, WithMultilinePolicy(policy))) {
+      l.WithFields(fields).Log("panic")
+      if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{`panic stack="at a\\nat b"`}) {
+        t.Errorf("%s, policy %d: Messages() = %q", name, policy, got)
+      }
+    }
+    if fields["stack"] != "at a\nat b" {
+      t.Error("the caller's fields were changed")
+    }
+  }
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "io"
+  "net"
+  "sync"
+  "time"
+)
+
+const (
+  defaultDialTimeout = 5 * time.Second
+  defaultReconnectBackoff = 100 * time.Millisecond
+  defaultBatchDelay = 100 * time.Millisecond
+)
+
+// WithDialTimeout bounds how long a NetworkLogger waits to connect.
+func WithDialTimeout(d time.Duration) Option {
+  return func(o *options) {
+    o.dialTimeout = d
+  }
+}
+
+// WithDialer makes a NetworkLogger connect with dial, such as to go through
+// a proxy or, in a test, to hand back one end of a net.Pipe, rather than
+// with a net.Dialer. The dial timeout still bounds its ctx.
+func WithDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+  return func(o *options) {
+    o.dialer = dial
+  }
+}
+
+// dial connects to addr with the WithDialer function, or a net.Dialer.
+func (this *options) dial(ctx context.Context, addr string) (net.Conn, error) {
+  if this.dialer == nil {
+    dialer := net.Dialer{Timeout: this.dialTimeout}
+    return dialer.DialContext(ctx, "tcp", addr)
+  }
+  ctx, cancel := context.WithTimeout(ctx, this.dialTimeout)
+  defer cancel()
+  return this.dialer(ctx, "tcp", addr)
+}
+
+// WithReconnectBackoff sets how long a NetworkLogger waits after a failed
+// write before it reconnects.
+func WithReconnectBackoff(d time.Duration) Option {
+  return func(o *options) {
+    o.reconnectBackoff = d
+  }
+}
+
+// WithBatchSize makes a NetworkLogger send messages n at a time, in one
+// write, rather than each on its own. A partial batch goes out once the
+// batch delay has passed since its first message, or on Flush, Sync and
+// Close. An n of 0 or 1 sends each message as it is logged.
+func WithBatchSize(n int) Option {
+  return func(o *options) {
+    o.batchSize = n
+  }
+}
+
+// WithBatchDelay sets how long a batching NetworkLogger holds a partial
+// batch. The default is 100ms.
+func WithBatchDelay(d time.Duration) Option {
+  return func(o *options) {
+    o.batchDelay = d
+  }
+}
+
+// NetworkLogger ships each message to a TCP collector as a newline
+// terminated frame. Messages returns the lines sent so far.
+type NetworkLogger struct {
+  mu sync.Mutex
+  addr string
+  conn net.Conn
+  opts options
+  minLevel Level
+  sent []string
+  closed bool
+
+  batch []byte // frames waiting to be sent together
+  batched []string // the lines in batch
+  batchedLevels []Level
+  timer *time.Timer // sends a partial batch once the delay is up
+  batchErr error // from a send made by timer, kept for Flush
+}
+
+var _ LoggerInterface = (*NetworkLogger)(nil)
+
+// NewNetworkLogger connects to addr, failing if it cannot.
+func NewNetworkLogger(addr string, opts ...Option) (*NetworkLogger, error) {
+  this := &NetworkLogger{addr: addr, opts: newOptions(opts)}
+  if this.opts.dialTimeout <= 0 {
+    this.opts.dialTimeout = defaultDialTimeout
+  }
+  if this.opts.reconnectBackoff <= 0 {
+    this.opts.reconnectBackoff = defaultReconnectBackoff
+  }
+  if this.opts.batchDelay <= 0 {
+    this.opts.batchDelay = defaultBatchDelay
+  }
+  if err := this.dial(context.Background()); err != nil {
+    return nil, err
+  }
+  return this, nil
+}
+
+func (this *NetworkLogger) dial(ctx context.Context) error {
+  conn, err := this.opts.dial(ctx, this.addr)
+  if err != nil {
+    return err
+  }
+  this.conn = conn
+  return nil
+}
+
+func (this *NetworkLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *NetworkLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *NetworkLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *NetworkLogger) logEntry(e entry) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.closed {
+    return ErrClosed
+  }
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  if err := this.opts.validateUTF8(&e); err != nil {
+    return err
+  }
+  if e.level < this.minLevel {
+    return nil
+  }
+  ctx := e.ctx
+  if ctx == nil {
+    ctx = context.Background()
+  }
+  line := this.opts.render(e)
+  if this.opts.batchSize > 1 {
+    this.batch = append(append(this.batch, line...), '\n')
+    this.batched = append(this.batched, line)
+    this.batchedLevels = append(this.batchedLevels, e.level)
+    if len(this.batched) >= this.opts.batchSize {
+      return this.opts.handle(writeFailed(this.sendBatch(ctx)))
+    }
+    if this.timer == nil {
+      this.timer = time.AfterFunc(this.opts.batchDelay, this.sendLater)
+    }
+    return nil
+  }
+  if err := this.send(ctx, line + "\n"); err != nil {
+    return this.opts.handle(writeFailed(err))
+  }
+  this.sent = append(this.sent, line)
+  this.opts.notify(e.level, line)
+  return nil
+}
+
+// sendBatch sends the pending batch as one write. A batch that fails to
+// go is dropped, as a single message would be. The caller must hold mu.
+func (this *NetworkLogger) sendBatch(ctx context.Context) error {
+  if this.timer != nil {
+    this.timer.Stop()
+    this.timer = nil
+  }
+  if len(this.batched) == 0 {
+    return nil
+  }
+  err := this.send(ctx, string(this.batch))
+  if err == nil {
+    this.sent = append(this.sent, this.batched...)
+    for i, line := range(this.batched) {
+      this.opts.notify(this.batchedLevels[i], line)
+    }
+  }
+  this.batch = this.batch[:0]
+  this.batched = nil
+  this.batchedLevels = nil
+  return err
+}
+
+// sendLater is run by the timer to send a partial batch.
+func (this *NetworkLogger) sendLater() {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.timer = nil
+  if this.closed {
+    return
+  }
+  if err := this.opts.handle(writeFailed(this.sendBatch(context.Background()))); err != nil && this.batchErr == nil {
+    this.batchErr = err
+  }
+}
+
+// send writes frame, reconnecting once if the connection has failed.
+// The write, backoff and dial all stop early once ctx is done.
+func (this *NetworkLogger) send(ctx context.Context, frame string) error {
+  if this.conn != nil {
+    if err := this.writeFrame(ctx, frame); err == nil {
+      return nil
+    }
+    this.conn.Close()
+    this.conn = nil
+    if err := ctx.Err(); err != nil {
+      return err
+    }
+  }
+  timer := time.NewTimer(this.opts.reconnectBackoff)
+  select {
+  case <-timer.C:
+  case <-ctx.Done():
+    timer.Stop()
+    return ctx.Err()
+  }
+  if err := this.dial(ctx); err != nil {
+    return err
+  }
+  if err := this.writeFrame(ctx, frame); err != nil {
+    this.conn.Close()
+    this.conn = nil
+    return err
+  }
+  return nil
+}
+
+func (this *NetworkLogger) writeFrame(ctx context.Context, frame string) error {
+  deadline, _ := ctx.Deadline() // the zero time clears any old deadline
+  if err := this.conn.SetWriteDeadline(deadline); err != nil {
+    return err
+  }
+  _, err := io.WriteString(this.conn, frame)
+  return err
+}
+
+// Ping checks the collector is reachable by opening a connection of its
+// own, within ctx and the dial timeout, and closing it again. The
+// connection messages go over is left alone.
+func (this *NetworkLogger) Ping(ctx context.Context) error {
+  this.mu.Lock()
+  closed := this.closed
+  this.mu.Unlock()
+  if closed {
+    return ErrClosed
+  }
+  conn, err := this.opts.dial(ctx, this.addr)
+  if err != nil {
+    return err
+  }
+  return conn.Close()
+}
+
+// SetMinLevel drops subsequent messages below level.
+func (this *NetworkLogger) SetMinLevel(level Level) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.minLevel = level
+}
+
+func (this *NetworkLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *NetworkLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *NetworkLogger) Messages() ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return append([]string(nil), this.sent...), nil
+}
+
+func (this *NetworkLogger) Count() (int, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return len(this.sent), nil
+}
+
+func (this *NetworkLogger) Tail(n int) ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return tailOf(this.sent, n), nil
+}
+
+func (this *NetworkLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return searchIn(this.sent, pattern, useRegexp)
+}
+
+func (this *NetworkLogger) ForEach(fn func(line string) error) error {
+  messages, _ := this.Messages()
+  return forEachIn(messages, fn)
+}
+
+func (this *NetworkLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return pageOf(this.sent, offset, limit)
+}
+
+// Clear forgets the lines sent so far; the collector keeps its copy.
+func (this *NetworkLogger) Clear() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.sent = nil
+  return nil
+}
+
+// Flush sends any partial batch. It also reports the first error from a
+// batch sent in the background since the last Flush. Without batching,
+// every Log has already been written to the connection.
+func (this *NetworkLogger) Flush() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.closed {
+    return nil
+  }
+  return this.flushLocked()
+}
+
+func (this *NetworkLogger) flushLocked() error {
+  err := errors.Join(this.batchErr, this.opts.handle(writeFailed(this.sendBatch(context.Background()))))
+  this.batchErr = nil
+  return err
+}
+
+// Sync is Flush: the collector's copy is out of reach.
+func (this *NetworkLogger) Sync() error {
+  return this.Flush()
+}
+
+// Close sends any partial batch and closes the connection.
+func (this *NetworkLogger) Close() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.closed {
+    return nil
+  }
+  flushErr := this.flushLocked()
+  this.closed = true
+  if this.conn == nil {
+    return flushErr
+  }
+  err := this.conn.Close()
+  this.conn = nil
+  return errors.Join(flushErr, err)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "io"
+  "net"
+  "strings"
+  "sync"
+  "testing"
+  "time"
+)
+
+// fakeConn is the client end of a net.Pipe that records each Write, and
+// can be made to fail them.
+type fakeConn struct {
+  net.Conn
+  mu sync.Mutex
+  writes []string
+  fail bool
+}
+
+func (this *fakeConn) Write(p []byte) (int, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.fail {
+    return 0, errors.New("connection reset")
+  }
+  this.writes = append(this.writes, string(p))
+  return len(p), nil
+}
+
+func (this *fakeConn) Writes() []string {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return append([]string(nil), this.writes...)
+}
+
+// fakeDialer hands out fakeConns, draining the far end of each pipe.
+type fakeDialer struct {
+  mu sync.Mutex
+  conns []*fakeConn
+}
+
+func (this *fakeDialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+  client, server := net.Pipe()
+  go io.Copy(io.Discard, server)
+  conn := &fakeConn{Conn: client}
+  this.mu.Lock()
+  this.conns = append(this.conns, conn)
+  this.mu.Unlock()
+  return conn, nil
+}
+
+func (this *fakeDialer) last() *fakeConn {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return this.conns[len(this.conns)-1]
+}
+
+func TestNetworkBatchSingleWrite(t *testing.T) {
+  dialer := &fakeDialer{}
+  l, err := NewNetworkLogger("collector:9000", WithDialer(dialer.dial), WithBatchSize(10), WithBatchDelay(time.Hour))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  for i := 0; i < 10; i++ {
+    l.Logf(LevelInfo, "message %d", i)
+  }
+  writes := dialer.last().Writes()
+  if len(writes) != 1 {
+    t.Fatalf("got %d writes, want 1: %q", len(writes), writes)
+  }
+  if lines := strings.Split(strings.TrimSuffix(writes[0], "\n"), "\n"); len(lines) != 10 || lines[9] != "message 9" {
+    t.Errorf("batch holds %q", lines)
+  }
+}
+
+func TestNetworkBatchDelayAndClose(t *testing.T) {
+  dialer := &fakeDialer{}
+  l, err := NewNetworkLogger("collector:9000", WithDialer(dialer.dial), WithBatchSize(10), WithBatchDelay(10*time.Millisecond))
+  if err != nil {
+    t.Fatal(err)
+  }
+  l.Log("early")
+  deadline := time.Now().Add(5 * time.Second)
+  for len(dialer.last().Writes()) == 0 && time.Now().Before(deadline) {
+    time.Sleep(5 * time.Millisecond)
+  }
+  if writes := dialer.last().Writes(); len(writes) != 1 || writes[0] != "early\n" {
+    t.Fatalf("after the delay got %q", writes)
+  }
+  l.Log("late")
+  if err := l.Close(); err != nil {
+    t.Fatal(err)
+  }
+  if writes := dialer.last().Writes(); len(writes) != 2 || writes[1] != "late\n" {
+    t.Errorf("Close left the partial batch unsent: %q", writes)
+  }
+}
+
+func TestNetworkReconnects(t *testing.T) {
+  dialer := &fakeDialer{}
+  l, err := NewNetworkLogger("collector:9000", WithDialer(dialer.dial), WithReconnectBackoff(time.Millisecond))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  first := dialer.last()
+  first.mu.Lock()
+  first.fail = true
+  first.mu.Unlock()
+  if err := l.Log("survives"); err != nil {
+    t.Fatal(err)
+  }
+  if second := dialer.last(); second == first {
+    t.Fatal("no reconnect after a failed write")
+  } else if writes := second.Writes(); len(writes) != 1 || writes[0] != "survives\n" {
+    t.Errorf("new connection got %q", writes)
+  }
+  AssertLoggedCount(t, l, 1)
+}
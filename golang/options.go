@@ -0,0 +1,214 @@
+package main
+
+import (
+  "context"
+  "net"
+  "os"
+  "sync/atomic"
+  "time"
+)
+
+// Option configures a logger when it is constructed.
+type Option func(*options)
+
+// options holds the settings shared by the logger implementations.
+// The zero value logs the bare message, as the loggers always have.
+type options struct {
+  timestamps bool
+  layout string
+  timeSource Clock
+  formatter Formatter
+  jsonl bool
+  mirror bool
+  discardHistory bool
+  internMax int
+  gzip bool
+  fsys FS
+  checkpointPath string
+  checkpointInterval time.Duration
+  fileMode os.FileMode
+  mkdirAll bool
+  syncOnWrite bool
+  flushOnLevel Level
+  flushOnLevelSet bool // LevelDebug is the zero value, not the default
+  separator byte
+  separatorSet bool // a zero separator is '\x00', not the default
+  multiline MultilinePolicy
+  multilineSet bool // the default depends on the separator
+  encryptionKey []byte
+  stripANSI bool
+  transforms []func(string) string
+  extractors []func(context.Context) Fields
+  redactors []func(string) string
+  utf8Mode UTF8Mode
+  maxMessageLength int
+  truncated *atomic.Uint64 // shared by the copies of the options
+  sequence *atomic.Uint64 // the next number, shared like truncated
+  sequenceWidth int
+  caller bool
+  callerSkip int
+  color bool
+  colorForced bool // color regardless of whether the output is a terminal
+  bufferSize int
+  flushInterval time.Duration
+  queueSize int
+  overflow OverflowPolicy
+  overflowSet bool // DropOldest is the zero value, not the default
+  workers int
+  onLog []func(Level, string)
+  errorHandler func(error)
+  dialTimeout time.Duration
+  dialer func(ctx context.Context, network, addr string) (net.Conn, error)
+  reconnectBackoff time.Duration
+  batchSize int
+  batchDelay time.Duration
+}
+
+func newOptions(opts []Option) options {
+  var o options
+  for _, opt := range(opts) {
+    opt(&o)
+  }
+  return o
+}
+
+// WithTimestamp prefixes each message with the time it was logged, formatted
+// with layout. An empty layout means time.RFC3339Nano. The layout applies to
+// the default formatter; one given WithFormatter uses its own.
+func WithTimestamp(layout string) Option {
+  return func(o *options) {
+    o.timestamps = true
+    o.layout = layout
+  }
+}
+
+// WithFormatter renders messages with f instead of a PlainFormatter.
+func WithFormatter(f Formatter) Option {
+  return func(o *options) {
+    o.formatter = f
+  }
+}
+
+// WithNow replaces time.Now as the clock, so tests can get fixed timestamps.
+// It is WithClock for a plain function.
+func WithNow(now func() time.Time) Option {
+  if now == nil {
+    return WithClock(nil)
+  }
+  return WithClock(clockFunc(now))
+}
+
+// WithMirror makes a LocalLogger remember what it writes, so that Messages
+// need not re-read the file. The mirror holds only this process's writes:
+// lines already in the file, or added by other writers, are not reflected.
+func WithMirror() Option {
+  return func(o *options) {
+    o.mirror = true
+  }
+}
+
+// WithDiscardHistory makes an InMemoryLogger keep no messages: each one is
+// counted in Stats and handed to the OnLog hooks, and then dropped, so
+// that Messages returns nil. It suits a logger used only as a sink.
+func WithDiscardHistory() Option {
+  return func(o *options) {
+    o.discardHistory = true
+  }
+}
+
+// WithFileMode sets the permissions a LocalLogger creates its file with,
+// before the umask. The default is 0644.
+func WithFileMode(mode os.FileMode) Option {
+  return func(o *options) {
+    o.fileMode = mode
+  }
+}
+
+// WithMkdirAll makes a LocalLogger create any missing parent directories of
+// its file, with mode 0755, instead of failing to open it.
+func WithMkdirAll(enabled bool) Option {
+  return func(o *options) {
+    o.mkdirAll = enabled
+  }
+}
+
+// WithSyncOnWrite makes a LocalLogger sync the file to stable storage
+// before each Log returns, first flushing any buffer and gzip block, so
+// that an acknowledged record survives a crash. Expect each write to cost
+// a disk flush: orders of magnitude slower than without.
+func WithSyncOnWrite(enabled bool) Option {
+  return func(o *options) {
+    o.syncOnWrite = enabled
+  }
+}
+
+// WithRecordSeparator makes a LocalLogger end each record with sep instead
+// of a newline, and split the file on it when reading. With the newline
+// default, a message with a newline in it would read back as several, so
+// the MultilinePolicy escapes it. A separator such as '\x00' that messages
+// never contain keeps them whole.
+func WithRecordSeparator(sep byte) Option {
+  return func(o *options) {
+    o.separator = sep
+    o.separatorSet = true
+  }
+}
+
+// recordSeparator is the byte that ends a LocalLogger record.
+func (this *options) recordSeparator() byte {
+  if this.separatorSet {
+    return this.separator
+  }
+  return '\n'
+}
+
+func (this *options) clock() time.Time {
+  if this.timeSource != nil {
+    return this.timeSource.Now()
+  }
+  return realClock{}.Now()
+}
+
+// render turns a message into the line that is stored. It must be called at
+// Log time so that the timestamp reflects when the message was logged.
+func (this *options) render(e entry) string {
+  return this.renderAt(e, this.clock())
+}
+
+// renderAt is render for a caller that has already read the clock.
+func (this *options) renderAt(e entry, now time.Time) string {
+  e.mesg = this.truncate(this.redact(this.transform(this.stripEscapes(e.mesg))))
+  e.fields = this.extract(e)
+  if this.caller {
+    e.mesg = e.callerAt(this.callerSkip) + " " + e.mesg
+  }
+  if this.sequence != nil {
+    e.mesg = this.nextSequence() + " " + e.mesg
+  }
+  var t time.Time
+  if this.timestamps {
+    t = now
+  }
+  fallback := PlainFormatter{Layout: this.layout}
+  if this.formatter == nil {
+    return formatEntry(fallback, e, t)
+  }
+  var line string
+  ok := this.safeCall("formatter", func() {
+    line = formatEntry(this.formatter, e, t)
+  })
+  if !ok {
+    // Write the message out plain rather than lose it.
+    return formatEntry(fallback, e, t)
+  }
+  return line
+}
+
+// formatEntry renders e with formatter, handing it the fields if it takes
+// them.
+func formatEntry(formatter Formatter, e entry, t time.Time) string {
+  if ff, ok := formatter.(FieldsFormatter); ok {
+    return ff.FormatFields(e.level, e.mesg, t, e.fields)
+  }
+  return formatter.Format(e.level, appendFields(e.mesg, e.fields), t)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+  "os"
+  "path/filepath"
+  "runtime"
+  "strings"
+  "testing"
+)
+
+// umasked returns the permissions the umask leaves of mode on a new
+// directory, or on a new file if dir is false.
+func umasked(t *testing.T, mode os.FileMode, dir bool) os.FileMode {
+  t.Helper()
+  path := filepath.Join(t.TempDir(), "reference")
+  var err error
+  if dir {
+    err = os.Mkdir(path, mode)
+  } else {
+    err = os.WriteFile(path, nil, mode)
+  }
+  if err != nil {
+    t.Fatal(err)
+  }
+  info, err := os.Stat(path)
+  if err != nil {
+    t.Fatal(err)
+  }
+  return info.Mode().Perm()
+}
+
+func TestMkdirAllAndFileMode(t *testing.T) {
+  root := t.TempDir()
+  filename := filepath.Join(root, "nested", "dir", "out.log")
+  l, err := NewLocalLogger(filename, true, WithMkdirAll(true), WithFileMode(0640))
+  if err != nil {
+    t.Fatalf("NewLocalLogger: %v", err)
+  }
+  defer l.Close()
+  for _, dir := range([]string{filepath.Join(root, "nested"), filepath.Dir(filename)}) {
+    info, err := os.Stat(dir)
+    if err != nil {
+      t.Fatal(err)
+    }
+    if want := umasked(t, 0755, true); !info.IsDir() || info.Mode().Perm() != want {
+      t.Errorf("%s: mode %v, want a directory with %v", dir, info.Mode(), want)
+    }
+  }
+  info, err := os.Stat(filename)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if want := umasked(t, 0640, false); info.Mode().Perm() != want {
+    t.Errorf("file mode %v, want %v", info.Mode().Perm(), want)
+  }
+}
+
+func TestDefaultFileMode(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "out.log")
+  l, err := NewLocalLogger(filename, true)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  info, err := os.Stat(filename)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if want := umasked(t, 0644, false); info.Mode().Perm() != want {
+    t.Errorf("file mode %v, want %v", info.Mode().Perm(), want)
+  }
+}
+
+func TestMissingDirectoryWithoutMkdirAll(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "missing", "out.log")
+  if l, err := NewLocalLogger(filename, true); err == nil {
+    l.Close()
+    t.Error("NewLocalLogger succeeded in a missing directory")
+  }
+}
+
+// heapInUse returns the bytes of live heap after a collection.
+func heapInUse() uint64 {
+  runtime.GC()
+  var stats runtime.MemStats
+  runtime.ReadMemStats(&stats)
+  return stats.HeapAlloc
+}
+
+func TestDiscardHistoryStaysFlat(t *testing.T) {
+  hooked := 0
+  sink := NewInMemoryLogger(WithDiscardHistory(), WithHook(func(level Level, msg string) {
+    hooked++
+  }))
+  keep := NewInMemoryLogger()
+  l := NewMultiLogger(sink, keep)
+  line := strings.Repeat("x", 100)
+
+  before := heapInUse()
+  for i := 0; i < 100000; i++ {
+    sink.Log(line)
+  }
+  after := heapInUse()
+  if grown := int64(after) - int64(before); grown > 1<<20 {
+    t.Errorf("the heap grew by %d bytes over 100000 discarded messages", grown)
+  }
+  if messages, _ := sink.Messages(); messages != nil || sink.Bytes() != 0 || sink.HighWaterBytes() != 0 {
+    t.Errorf("discarding logger holds %d messages in %d bytes", len(messages), sink.Bytes())
+  }
+  if hooked != 100000 || sink.Stats().Total != 100000 {
+    t.Errorf("hook ran %d times and Stats counted %d, want 100000", hooked, sink.Stats().Total)
+  }
+
+  l.Log("forwarded")
+  AssertLoggedCount(t, keep, 1)
+  AssertLoggedCount(t, sink, 0)
+}
@@ -0,0 +1,16 @@
+package main
+
+// otelSeverity maps a Level to its OpenTelemetry severity number, the
+// first of the four the data model gives each severity.
+func otelSeverity(level Level) int {
+  switch {
+  case level <= LevelDebug:
+    return 5 // DEBUG
+  case level == LevelInfo:
+    return 9 // INFO
+  case level == LevelWarn:
+    return 13 // WARN
+  default:
+    return 17 // ERROR
+  }
+}
@@ -0,0 +1,50 @@
+//go:build otel
+
+package main
+
+import (
+  "context"
+
+  "go.opentelemetry.io/otel/attribute"
+  otellog "go.opentelemetry.io/otel/log"
+  sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// otlpBatchSize is how many records ExportOTLP hands the exporter at once.
+const otlpBatchSize = 512
+
+// ExportOTLP sends l's messages to exporter as OpenTelemetry log records,
+// in batches. The timestamp and level are recovered from lines the default
+// formatter rendered; lines without them get no timestamp and LevelInfo.
+// It returns the number of records exported.
+func ExportOTLP(ctx context.Context, l LoggerInterface, exporter sdklog.Exporter) (int, error) {
+  exported := 0
+  batch := make([]sdklog.Record, 0, otlpBatchSize)
+  send := func() error {
+    if err := exporter.Export(ctx, batch); err != nil {
+      return err
+    }
+    exported += len(batch)
+    batch = batch[:0]
+    return nil
+  }
+  err := l.ForEach(func(line string) error {
+    t, level, mesg := parseLine(line)
+    var record sdklog.Record
+    if !t.IsZero() {
+      record.SetTimestamp(t)
+    }
+    record.SetSeverity(otellog.Severity(otelSeverity(level)))
+    record.SetSeverityText(level.String())
+    record.SetBody(attribute.StringValue(mesg))
+    batch = append(batch, record)
+    if len(batch) == otlpBatchSize {
+      return send()
+    }
+    return ctx.Err()
+  })
+  if err == nil && len(batch) > 0 {
+    err = send()
+  }
+  return exported, err
+}
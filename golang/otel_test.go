@@ -0,0 +1,26 @@
+//go:build otel
+
+package main
+
+import (
+  "testing"
+)
+
+func TestOtelSeverity(t *testing.T) {
+  tests := []struct {
+    level Level
+    want int
+  }{
+    {LevelDebug, 5},
+    {LevelInfo, 9},
+    {LevelWarn, 13},
+    {LevelError, 17},
+    {LevelDebug - 1, 5},
+    {LevelError + 1, 17},
+  }
+  for _, test := range(tests) {
+    if got := otelSeverity(test.level); got != test.want {
+      t.Errorf("otelSeverity(%v) = %d, want %d", test.level, got, test.want)
+    }
+  }
+}
@@ -0,0 +1,44 @@
+package main
+
+// OverflowPolicy decides what a bounded logger does with a message that
+// does not fit: a bounded InMemoryLogger once it is at maxEntries or
+// maxBytes, or an AsyncLogger once its queue is full.
+type OverflowPolicy int
+
+const (
+  DropOldest OverflowPolicy = iota // make room by dropping the oldest message
+  DropNewest // refuse the incoming message, returning ErrQueueFull
+  Block // wait for room; only an AsyncLogger can, others drop the oldest
+)
+
+// WithOverflow sets a bounded logger's OverflowPolicy. InMemoryLoggers
+// default to DropOldest; AsyncLoggers default to Block, as they always
+// have.
+func WithOverflow(policy OverflowPolicy) Option {
+  return func(o *options) {
+    o.overflow = policy
+    o.overflowSet = true
+  }
+}
+
+// overflowPolicy is the policy set WithOverflow, or def if none was.
+func (this *options) overflowPolicy(def OverflowPolicy) OverflowPolicy {
+  if this.overflowSet {
+    return this.overflow
+  }
+  return def
+}
+
+// refuses reports whether storing n more messages, of size bytes in all,
+// overflows a DropNewest logger. A logger holding nothing takes anything,
+// as one dropping the oldest keeps a message longer than maxBytes. The
+// caller must hold the lock.
+func (this *InMemoryLogger) refuses(n, size int) bool {
+  if this.opts.overflowPolicy(DropOldest) != DropNewest || len(this.messages) == 0 {
+    return false
+  }
+  if this.maxEntries > 0 && len(this.messages)+n > this.maxEntries {
+    return true
+  }
+  return this.maxBytes > 0 && this.bytes+size > this.maxBytes
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+  "errors"
+  "reflect"
+  "testing"
+  "time"
+)
+
+func TestInMemoryOverflow(t *testing.T) {
+  tests := []struct {
+    name string
+    opts []Option
+    want []string
+    refused bool
+  }{
+    {"default", nil, []string{"b", "c"}, false},
+    {"DropOldest", []Option{WithOverflow(DropOldest)}, []string{"b", "c"}, false},
+    {"DropNewest", []Option{WithOverflow(DropNewest)}, []string{"a", "b"}, true},
+    {"Block", []Option{WithOverflow(Block)}, []string{"b", "c"}, false},
+  }
+  for _, test := range(tests) {
+    bounds := map[string]*InMemoryLogger{
+      "entries": NewBoundedInMemoryLogger(2, test.opts...),
+      "bytes": NewByteBoundedInMemoryLogger(2, test.opts...),
+    }
+    for bound, l := range(bounds) {
+      l.Log("a")
+      l.Log("b")
+      err := l.Log("c")
+      if refused := errors.Is(err, ErrQueueFull); refused != test.refused {
+        t.Errorf("%s by %s: Log at capacity = %v", test.name, bound, err)
+      }
+      if got, _ := l.Messages(); !reflect.DeepEqual(got, test.want) {
+        t.Errorf("%s by %s: Messages = %q, want %q", test.name, bound, got, test.want)
+      }
+    }
+  }
+}
+
+// gatedLogger is an InMemoryLogger whose writes wait for a value on gate.
+type gatedLogger struct {
+  *InMemoryLogger
+  gate chan struct{}
+}
+
+func (this *gatedLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *gatedLogger) logEntry(e entry) error {
+  <-this.gate
+  return this.InMemoryLogger.logEntry(e)
+}
+
+// fillAsync returns an AsyncLogger under policy with a queue of one,
+// whose worker is stuck on "a" while "b" waits in the queue.
+func fillAsync(t *testing.T, policy OverflowPolicy) (*AsyncLogger, *gatedLogger) {
+  inner := &gatedLogger{InMemoryLogger: NewInMemoryLogger(), gate: make(chan struct{})}
+  l := NewAsyncLogger(inner, WithQueueSize(1), WithOverflow(policy))
+  l.Log("a")
+  for len(l.queue) > 0 {
+    time.Sleep(time.Millisecond) // until the worker has taken "a"
+  }
+  l.Log("b")
+  return l, inner
+}
+
+// release lets the worker of fillAsync through, closes l and returns what
+// the inner logger got.
+func release(l *AsyncLogger, inner *gatedLogger) []string {
+  close(inner.gate)
+  l.Close()
+  messages, _ := inner.Messages()
+  return messages
+}
+
+func TestAsyncOverflowDropNewest(t *testing.T) {
+  l, inner := fillAsync(t, DropNewest)
+  if err := l.Log("c"); !errors.Is(err, ErrQueueFull) {
+    t.Errorf("Log with the queue full = %v, want ErrQueueFull", err)
+  }
+  if got := release(l, inner); !reflect.DeepEqual(got, []string{"a", "b"}) {
+    t.Errorf("delivered %q, want a and b", got)
+  }
+}
+
+func TestAsyncOverflowDropOldest(t *testing.T) {
+  l, inner := fillAsync(t, DropOldest)
+  if err := l.Log("c"); err != nil {
+    t.Errorf("Log with the queue full = %v", err)
+  }
+  if got := release(l, inner); !reflect.DeepEqual(got, []string{"a", "c"}) {
+    t.Errorf("delivered %q, want a and c", got)
+  }
+}
+
+func TestAsyncOverflowBlock(t *testing.T) {
+  l, inner := fillAsync(t, Block)
+  done := make(chan error)
+  go func() {
+    done <- l.Log("c")
+  }()
+  select {
+  case err := <-done:
+    t.Fatalf("Log with the queue full returned %v without waiting", err)
+  case <-time.After(20 * time.Millisecond):
+  }
+  inner.gate <- struct{}{} // let "a" through, making room for "c"
+  if err := <-done; err != nil {
+    t.Errorf("Log = %v once there was room", err)
+  }
+  if got := release(l, inner); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+    t.Errorf("delivered %q, want all three", got)
+  }
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+)
+
+// checkPage rejects the arguments MessagesPage does not accept.
+func checkPage(offset, limit int) error {
+  if offset < 0 || limit <= 0 {
+    return fmt.Errorf("log: bad page: offset %d, limit %d", offset, limit)
+  }
+  return nil
+}
+
+// pageOf returns a copy of messages[offset:offset+limit], and whether more
+// follow it.
+func pageOf(messages []string, offset, limit int) ([]string, bool, error) {
+  if err := checkPage(offset, limit); err != nil {
+    return nil, false, err
+  }
+  if offset >= len(messages) {
+    return nil, false, nil
+  }
+  end := len(messages)
+  if limit < end-offset {
+    end = offset + limit
+  }
+  return append([]string(nil), messages[offset:end]...), end < len(messages), nil
+}
+
+// errPageFull stops pageFrom's walk once it has seen one line past the page.
+var errPageFull = errors.New("log: page full")
+
+// pageFrom is pageOf for messages walked by forEach, such as a ForEach
+// method, holding only the page in memory.
+func pageFrom(forEach func(fn func(line string) error) error, offset, limit int) ([]string, bool, error) {
+  if err := checkPage(offset, limit); err != nil {
+    return nil, false, err
+  }
+  var page []string
+  seen := 0
+  more := false
+  err := forEach(func(line string) error {
+    seen++
+    switch {
+    case seen <= offset:
+      return nil
+    case len(page) < limit:
+      page = append(page, line)
+      return nil
+    default:
+      more = true
+      return errPageFull
+    }
+  })
+  if err != nil && !errors.Is(err, errPageFull) {
+    return nil, false, err
+  }
+  return page, more, nil
+}
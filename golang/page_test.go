@@ -0,0 +1,49 @@
+package main
+
+import (
+  "reflect"
+  "testing"
+)
+
+func TestMessagesPageReconstructs(t *testing.T) {
+  for name, l := range(bothLoggers(t)) {
+    for i := 0; i < 1000; i++ {
+      l.Logf(LevelInfo, "line %d", i)
+    }
+    var all []string
+    pages := 0
+    for offset := 0; ; offset += 100 {
+      page, hasMore, err := l.MessagesPage(offset, 100)
+      if err != nil {
+        t.Fatalf("%s: MessagesPage(%d, 100): %v", name, offset, err)
+      }
+      if len(page) != 100 {
+        t.Fatalf("%s: page at %d has %d lines, want 100", name, offset, len(page))
+      }
+      all = append(all, page...)
+      pages++
+      if !hasMore {
+        break
+      }
+    }
+    want, _ := l.Messages()
+    if pages != 10 || !reflect.DeepEqual(all, want) {
+      t.Errorf("%s: %d pages of %d lines do not make up Messages", name, pages, len(all))
+    }
+    if page, hasMore, err := l.MessagesPage(1000, 100); page != nil || hasMore || err != nil {
+      t.Errorf("%s: page past the end = %q, %v, %v; want empty", name, page, hasMore, err)
+    }
+  }
+}
+
+func TestMessagesPageBadArguments(t *testing.T) {
+  for name, l := range(bothLoggers(t)) {
+    l.Log("x")
+    if _, _, err := l.MessagesPage(-1, 10); err == nil {
+      t.Errorf("%s: a negative offset succeeded", name)
+    }
+    if _, _, err := l.MessagesPage(0, 0); err == nil {
+      t.Errorf("%s: a zero limit succeeded", name)
+    }
+  }
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+  "strings"
+  "time"
+)
+
+// parseLine undoes the default PlainFormatter: it splits off a leading
+// RFC 3339 timestamp, zero if there is none, and a level name as
+// Level.String spells it, LevelInfo if there is none, from the message.
+func parseLine(line string) (time.Time, Level, string) {
+  var t time.Time
+  rest := line
+  if head, tail, ok := strings.Cut(rest, " "); ok {
+    if parsed, err := time.Parse(time.RFC3339Nano, head); err == nil {
+      t, rest = parsed, tail
+    }
+  }
+  level := LevelInfo
+  if head, tail, ok := strings.Cut(rest, " "); ok {
+    for i, name := range(levelNames) {
+      if head == name {
+        level, rest = Level(i), tail
+        break
+      }
+    }
+  }
+  return t, level, rest
+}
+
+// parseRecord is parseLine into a Record, except that a line with neither
+// a timestamp nor a level name is left whole, with the zero Level.
+func parseRecord(line string) Record {
+  t, level, text := parseLine(line)
+  if t.IsZero() && text == line {
+    return Record{Text: line}
+  }
+  return Record{Time: t, Level: level, Text: text}
+}
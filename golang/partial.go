@@ -0,0 +1,82 @@
+package main
+
+import (
+  "bufio"
+  "bytes"
+  "errors"
+)
+
+// dropPartial wraps split so that a last token lacking its separator is
+// dropped rather than returned, for SkipTrailingPartial.
+func dropPartial(split bufio.SplitFunc, sep byte) bufio.SplitFunc {
+  return func(data []byte, atEOF bool) (int, []byte, error) {
+    if atEOF && len(data) > 0 && bytes.IndexByte(data, sep) < 0 {
+      return len(data), nil, nil
+    }
+    return split(data, atEOF)
+  }
+}
+
+// readEnd returns where the records in the plain file end: its size, or
+// with SkipTrailingPartial, just after its last separator.
+func (this *LocalLogger) readEnd(file File, sep byte) (int64, error) {
+  info, err := file.Stat()
+  if err != nil {
+    return 0, err
+  }
+  if !this.SkipTrailingPartial {
+    return info.Size(), nil
+  }
+  return lastRecordEnd(file, info.Size(), sep)
+}
+
+// lastRecordEnd returns the offset just after the last sep in the first
+// size bytes of file, or 0 if there is none, reading backwards.
+func lastRecordEnd(file File, size int64, sep byte) (int64, error) {
+  pos := size
+  chunk := make([]byte, tailChunk)
+  for pos > 0 {
+    step := int64(len(chunk))
+    if step > pos {
+      step = pos
+    }
+    pos -= step
+    if _, err := file.ReadAt(chunk[:step], pos); err != nil {
+      return 0, err
+    }
+    if i := bytes.LastIndexByte(chunk[:step], sep); i >= 0 {
+      return pos + int64(i) + 1, nil
+    }
+  }
+  return 0, nil
+}
+
+// Recover cuts off a last record that lacks its separator, as a crash in
+// the middle of a write leaves one, so that the next record starts on a
+// clean boundary. It works on plain files only: a compressed or encrypted
+// one cannot be cut at a record. It returns how many bytes it cut.
+func (this *LocalLogger) Recover() (int64, error) {
+  if this.opts.gzip || this.aead != nil {
+    return 0, errors.New("log: Recover needs an uncompressed, unencrypted file")
+  }
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if this.file == nil {
+    return 0, ErrClosed
+  }
+  if err := this.flushLocked(); err != nil {
+    return 0, err
+  }
+  info, err := this.file.Stat()
+  if err != nil {
+    return 0, err
+  }
+  end, err := lastRecordEnd(this.file, info.Size(), this.opts.recordSeparator())
+  if err != nil || end == info.Size() {
+    return 0, err
+  }
+  if err := this.file.Truncate(end); err != nil {
+    return 0, err
+  }
+  return info.Size() - end, nil
+}
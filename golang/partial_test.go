@@ -0,0 +1,66 @@
+package main
+
+import (
+  "os"
+  "path/filepath"
+  "reflect"
+  "testing"
+)
+
+// crashedFile returns a file two whole records long, ending in a record
+// a crash cut short.
+func crashedFile(t *testing.T) string {
+  filename := filepath.Join(t.TempDir(), "crashed.log")
+  if err := os.WriteFile(filename, []byte("one\ntwo\nthr"), 0644); err != nil {
+    t.Fatal(err)
+  }
+  return filename
+}
+
+func TestSkipTrailingPartial(t *testing.T) {
+  l, err := NewLocalLogger(crashedFile(t), false)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"one", "two", "thr"}) {
+    t.Errorf("Messages = %q, want the partial record by default", got)
+  }
+  l.SkipTrailingPartial = true
+  want := []string{"one", "two"}
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages = %q, want %q", got, want)
+  }
+  if n, _ := l.Count(); n != 2 {
+    t.Errorf("Count = %d, want 2", n)
+  }
+  if got, _ := l.Tail(1); !reflect.DeepEqual(got, []string{"two"}) {
+    t.Errorf("Tail(1) = %q, want the last whole record", got)
+  }
+}
+
+func TestRecoverCutsPartial(t *testing.T) {
+  filename := crashedFile(t)
+  l, err := NewLocalLogger(filename, false)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  if n, err := l.Recover(); err != nil || n != 3 {
+    t.Fatalf("Recover = %d, %v; want 3 bytes cut", n, err)
+  }
+  l.Log("three")
+  if data, _ := os.ReadFile(filename); string(data) != "one\ntwo\nthree\n" {
+    t.Errorf("file after Recover and Log = %q", data)
+  }
+  if n, err := l.Recover(); err != nil || n != 0 {
+    t.Errorf("Recover of a clean file = %d, %v; want nothing cut", n, err)
+  }
+}
+
+func TestRecoverRefusesGzip(t *testing.T) {
+  l := newTestLocal(t, WithGzip())
+  if _, err := l.Recover(); err == nil {
+    t.Error("Recover of a gzip file succeeded")
+  }
+}
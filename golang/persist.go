@@ -0,0 +1,140 @@
+package main
+
+import (
+  "bufio"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "os"
+)
+
+// SaveToFile writes the messages to path, one per line, replacing the file.
+// A message containing a newline, as one stored under MultilineKeep, comes
+// back from LoadInMemoryLogger as several messages; the format has no way
+// to escape it.
+func (this *InMemoryLogger) SaveToFile(path string) error {
+  messages, err := this.Messages()
+  if err != nil {
+    return err
+  }
+  file, err := os.Create(path)
+  if err != nil {
+    return err
+  }
+  w := bufio.NewWriter(file)
+  for _, mesg := range(messages) {
+    w.WriteString(mesg)
+    w.WriteByte('\n')
+  }
+  if err := w.Flush(); err != nil {
+    file.Close()
+    return err
+  }
+  return file.Close()
+}
+
+// LoadInMemoryLogger returns an InMemoryLogger holding the messages that
+// SaveToFile wrote to path. They are stored as they are, without being
+// formatted again, but for WithStripANSI.
+func LoadInMemoryLogger(path string, opts ...Option) (*InMemoryLogger, error) {
+  file, err := os.Open(path)
+  if err != nil {
+    return nil, err
+  }
+  defer file.Close()
+
+  this := NewInMemoryLogger(opts...)
+  scanner := bufio.NewScanner(file)
+  for scanner.Scan() {
+    this.store(this.opts.stripEscapes(scanner.Text()), this.opts.clock())
+  }
+  if err := scanner.Err(); err != nil {
+    return nil, err
+  }
+  return this, nil
+}
+
+// DefaultLoadLineLimit is the longest line, in bytes, that LoadFrom
+// accepts.
+const DefaultLoadLineLimit = 1 << 20
+
+// LoadFrom logs each line read from r into dst, as LoadFromLimit does with
+// DefaultLoadLineLimit.
+func LoadFrom(dst LoggerInterface, r io.Reader) (int, error) {
+  return LoadFromLimit(dst, r, DefaultLoadLineLimit)
+}
+
+// LoadFromLimit logs each line read from r into dst with Log, until r runs
+// out, and returns how many it logged. A line longer than limit bytes stops
+// it with an error saying which line that was; so does a failed Log.
+func LoadFromLimit(dst LoggerInterface, r io.Reader, limit int) (int, error) {
+  scanner := bufio.NewScanner(r)
+  scanner.Buffer(make([]byte, 0, min(limit, bufio.MaxScanTokenSize)), limit)
+  n := 0
+  for scanner.Scan() {
+    if err := dst.Log(scanner.Text()); err != nil {
+      return n, err
+    }
+    n++
+  }
+  if err := scanner.Err(); err != nil {
+    if errors.Is(err, bufio.ErrTooLong) {
+      return n, fmt.Errorf("log: line %d is longer than %d bytes", n+1, limit)
+    }
+    return n, err
+  }
+  return n, nil
+}
+
+
+// loggerJSON is the shape MarshalJSON gives a logger's contents.
+type loggerJSON struct {
+  Messages []string `json:"messages"`
+  Count int `json:"count"`
+}
+
+// marshalMessages encodes messages as a loggerJSON, with an empty list
+// rather than null when there are none.
+func marshalMessages(messages []string) ([]byte, error) {
+  if messages == nil {
+    messages = []string{}
+  }
+  return json.Marshal(loggerJSON{Messages: messages, Count: len(messages)})
+}
+
+// MarshalJSON encodes the messages as {"messages":[...],"count":N}.
+func (this *InMemoryLogger) MarshalJSON() ([]byte, error) {
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return marshalMessages(messages)
+}
+
+// UnmarshalJSON replaces the messages with those MarshalJSON encoded. They
+// are stored as they are, subject to the logger's bounds; count is ignored.
+func (this *InMemoryLogger) UnmarshalJSON(data []byte) error {
+  var v loggerJSON
+  if err := json.Unmarshal(data, &v); err != nil {
+    return err
+  }
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.reset()
+  now := this.opts.clock()
+  for _, mesg := range(v.Messages) {
+    this.store(mesg, now)
+  }
+  return nil
+}
+
+// MarshalJSON encodes the file's lines in the same shape as
+// InMemoryLogger.MarshalJSON.
+func (this *LocalLogger) MarshalJSON() ([]byte, error) {
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return marshalMessages(messages)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+  "errors"
+  "reflect"
+  "strings"
+  "testing"
+)
+
+func TestLoadFrom(t *testing.T) {
+  l := NewInMemoryLogger()
+  n, err := LoadFrom(l, strings.NewReader("first\nsecond\r\n\nlast"))
+  if err != nil || n != 4 {
+    t.Fatalf("LoadFrom = %d, %v; want 4, nil", n, err)
+  }
+  want := []string{"first", "second", "", "last"}
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+    t.Fatalf("Messages() = %q, want %q", got, want)
+  }
+}
+
+func TestLoadFromLongLine(t *testing.T) {
+  long := strings.Repeat("x", 200*1024) // past bufio.MaxScanTokenSize
+  l := NewInMemoryLogger()
+  n, err := LoadFrom(l, strings.NewReader("a\n"+long+"\nb\n"))
+  if err != nil || n != 3 {
+    t.Fatalf("LoadFrom = %d, %v; want 3, nil", n, err)
+  }
+  if got, _ := l.Messages(); len(got) != 3 || got[1] != long {
+    t.Fatalf("the long line did not come through whole")
+  }
+}
+
+func TestLoadFromLimit(t *testing.T) {
+  l := NewInMemoryLogger()
+  n, err := LoadFromLimit(l, strings.NewReader("ok\n"+strings.Repeat("x", 100)+"\nnever\n"), 64)
+  if err == nil || !strings.Contains(err.Error(), "line 2") {
+    t.Fatalf("LoadFromLimit error = %v, want one naming line 2", err)
+  }
+  if n != 1 {
+    t.Fatalf("LoadFromLimit logged %d, want 1", n)
+  }
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"ok"}) {
+    t.Fatalf("Messages() = %q, want only the line before the long one", got)
+  }
+}
+
+func TestLoadFromStopsOnLogError(t *testing.T) {
+  dst := &failAfter{InMemoryLogger: NewInMemoryLogger(), n: 2}
+  n, err := LoadFrom(dst, strings.NewReader("a\nb\nc\nd\n"))
+  if !errors.Is(err, errFailAfter) || n != 2 {
+    t.Fatalf("LoadFrom = %d, %v; want 2, errFailAfter", n, err)
+  }
+}
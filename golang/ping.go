@@ -0,0 +1,49 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+)
+
+// Pingable is implemented by the loggers that can check their sink is up
+// before any traffic is routed to them.
+type Pingable interface {
+  Ping(ctx context.Context) error
+}
+
+var (
+  _ Pingable = (*InMemoryLogger)(nil)
+  _ Pingable = (*LocalLogger)(nil)
+  _ Pingable = (*NetworkLogger)(nil)
+  _ Pingable = (*SQLiteLogger)(nil)
+)
+
+// PingAll pings each logger that is Pingable, all of them even after one
+// fails, and returns the failures joined, each naming its logger's index.
+func PingAll(ctx context.Context, loggers ...LoggerInterface) error {
+  var errs []error
+  for i, logger := range(loggers) {
+    p, ok := logger.(Pingable)
+    if !ok {
+      continue
+    }
+    if err := p.Ping(ctx); err != nil {
+      errs = append(errs, fmt.Errorf("log: logger %d: %w", i, err))
+    }
+  }
+  return errors.Join(errs...)
+}
+
+// Ping always succeeds: there is no sink to reach.
+func (this *InMemoryLogger) Ping(ctx context.Context) error {
+  return nil
+}
+
+// Ping fails only once the logger is closed; the file is already open.
+func (this *LocalLogger) Ping(ctx context.Context) error {
+  if this.isClosed() {
+    return ErrClosed
+  }
+  return nil
+}
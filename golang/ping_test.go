@@ -0,0 +1,75 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "net"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestPingClosedPort(t *testing.T) {
+  ln, err := net.Listen("tcp", "127.0.0.1:0")
+  if err != nil {
+    t.Fatal(err)
+  }
+  go func() {
+    for {
+      conn, err := ln.Accept()
+      if err != nil {
+        return
+      }
+      conn.Close()
+    }
+  }()
+  l, err := NewNetworkLogger(ln.Addr().String())
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  if err := l.Ping(context.Background()); err != nil {
+    t.Fatalf("Ping with the port open: %v", err)
+  }
+  ln.Close()
+  if err := l.Ping(context.Background()); err == nil {
+    t.Error("Ping succeeded with the port closed")
+  }
+}
+
+func TestPingRespectsDeadline(t *testing.T) {
+  dialer := &fakeDialer{}
+  hang := false
+  dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+    if hang {
+      <-ctx.Done()
+      return nil, ctx.Err()
+    }
+    return dialer.dial(ctx, network, addr)
+  }
+  l, err := NewNetworkLogger("collector:9000", WithDialer(dial))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  hang = true
+  ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+  defer cancel()
+  start := time.Now()
+  if err := l.Ping(ctx); !errors.Is(err, context.DeadlineExceeded) {
+    t.Errorf("Ping = %v, want %v", err, context.DeadlineExceeded)
+  }
+  if elapsed := time.Since(start); elapsed > time.Second {
+    t.Errorf("Ping took %v past a 20ms deadline", elapsed)
+  }
+}
+
+func TestPingAll(t *testing.T) {
+  memory := NewInMemoryLogger()
+  local := newTestLocal(t)
+  local.Close()
+  err := PingAll(context.Background(), memory, NewDedupLogger(memory), local)
+  if !errors.Is(err, ErrClosed) || !strings.Contains(err.Error(), "logger 2") || strings.Contains(err.Error(), "logger 0") {
+    t.Errorf("PingAll = %v, want only logger 2 to fail", err)
+  }
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+  "context"
+  "fmt"
+)
+
+// prefixLogger is the child logger WithPrefix returns. It writes through
+// its parent, tagging every message with a component name, and reads from
+// it unchanged.
+type prefixLogger struct {
+  parent LoggerInterface
+  tags string // "[a][b]" for WithPrefix("a").WithPrefix("b")
+}
+
+var _ LoggerInterface = (*prefixLogger)(nil)
+
+func withPrefix(parent LoggerInterface, prefix string) LoggerInterface {
+  return &prefixLogger{parent: parent, tags: "[" + prefix + "]"}
+}
+
+func (this *prefixLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *prefixLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *prefixLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *prefixLogger) logEntry(e entry) error {
+  e.mesg = this.tags + " " + e.mesg
+  return logEntry(this.parent, e)
+}
+
+func (this *prefixLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+// WithPrefix returns a sibling whose tag follows this one's.
+func (this *prefixLogger) WithPrefix(prefix string) LoggerInterface {
+  return &prefixLogger{parent: this.parent, tags: this.tags + "[" + prefix + "]"}
+}
+
+func (this *prefixLogger) Messages() ([]string, error) {
+  return this.parent.Messages()
+}
+
+func (this *prefixLogger) Count() (int, error) {
+  return this.parent.Count()
+}
+
+func (this *prefixLogger) Tail(n int) ([]string, error) {
+  return this.parent.Tail(n)
+}
+
+func (this *prefixLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.parent.Search(pattern, useRegexp)
+}
+
+func (this *prefixLogger) ForEach(fn func(line string) error) error {
+  return this.parent.ForEach(fn)
+}
+
+func (this *prefixLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.parent.MessagesPage(offset, limit)
+}
+
+func (this *prefixLogger) Clear() error {
+  return this.parent.Clear()
+}
+
+func (this *prefixLogger) Sync() error {
+  return this.parent.Sync()
+}
+
+// Close is a no-op: the parent owns the underlying resources.
+func (this *prefixLogger) Close() error {
+  return nil
+}
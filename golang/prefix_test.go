@@ -0,0 +1,20 @@
+package main
+
+import (
+  "reflect"
+  "testing"
+)
+
+func TestNestedPrefixes(t *testing.T) {
+  for name, parent := range(bothLoggers(t)) {
+    db := parent.WithPrefix("db")
+    pool := db.WithPrefix("pool")
+    parent.Log("starting")
+    db.Log("connected")
+    pool.Log("5 idle")
+    want := []string{"starting", "[db] connected", "[db][pool] 5 idle"}
+    if got, _ := pool.Messages(); !reflect.DeepEqual(got, want) {
+      t.Errorf("%s: Messages = %q, want %q", name, got, want)
+    }
+  }
+}
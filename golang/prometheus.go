@@ -0,0 +1,53 @@
+//go:build prometheus
+
+package main
+
+import (
+  "github.com/prometheus/client_golang/prometheus"
+)
+
+// LoggerCollector exports a logger's metrics to Prometheus. It reads them
+// from the logger at each scrape instead of keeping counters of its own.
+type LoggerCollector struct {
+  logger LoggerInterface
+  stored *prometheus.Desc
+  logged *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*LoggerCollector)(nil)
+
+// NewLoggerCollector returns a collector for l, with labels attached to
+// every metric. Register it with prometheus.MustRegister.
+func NewLoggerCollector(l LoggerInterface, labels prometheus.Labels) *LoggerCollector {
+  return &LoggerCollector{
+    logger: l,
+    stored: prometheus.NewDesc("log_messages_stored",
+      "Number of messages the logger currently holds.", nil, labels),
+    logged: prometheus.NewDesc("log_messages_logged_total",
+      "Number of messages the logger has recorded, by level.", []string{"level"}, labels),
+  }
+}
+
+func (this *LoggerCollector) Describe(ch chan<- *prometheus.Desc) {
+  ch <- this.stored
+  if _, ok := this.logger.(interface{ Stats() Stats }); ok {
+    ch <- this.logged
+  }
+}
+
+// Collect reports the logger's Count, and its Stats if it keeps them. A
+// failing Count is reported as an invalid metric.
+func (this *LoggerCollector) Collect(ch chan<- prometheus.Metric) {
+  if n, err := this.logger.Count(); err != nil {
+    ch <- prometheus.NewInvalidMetric(this.stored, err)
+  } else {
+    ch <- prometheus.MustNewConstMetric(this.stored, prometheus.GaugeValue, float64(n))
+  }
+  s, ok := this.logger.(interface{ Stats() Stats })
+  if !ok {
+    return
+  }
+  for level, n := range(s.Stats().ByLevel) {
+    ch <- prometheus.MustNewConstMetric(this.logged, prometheus.CounterValue, float64(n), level.String())
+  }
+}
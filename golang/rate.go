@@ -0,0 +1,74 @@
+package main
+
+import (
+  "time"
+)
+
+// rateWindow is the longest window RateStats reports on, in seconds.
+const rateWindow = 15 * 60
+
+// Rates are message rates, in messages per second, averaged over the
+// last one, five and fifteen minutes.
+type Rates struct {
+  OneMinute float64
+  FiveMinutes float64
+  FifteenMinutes float64
+}
+
+// rateCounter counts messages per second of the clock over the last
+// rateWindow seconds. It is not synchronized, like levelCounts.
+type rateCounter struct {
+  counts [rateWindow]uint64
+  seconds [rateWindow]int64 // the second each slot of counts is for
+}
+
+func (this *rateCounter) add(now time.Time, n int) {
+  s := now.Unix()
+  i := s % rateWindow
+  if i < 0 {
+    i += rateWindow
+  }
+  if this.seconds[i] != s {
+    this.seconds[i] = s
+    this.counts[i] = 0
+  }
+  this.counts[i] += uint64(n)
+}
+
+// rate is the average per second over the window seconds up to now's.
+func (this *rateCounter) rate(now time.Time, window int64) float64 {
+  s := now.Unix()
+  var total uint64
+  for i := range(this.counts) {
+    if age := s - this.seconds[i]; age >= 0 && age < window {
+      total += this.counts[i]
+    }
+  }
+  return float64(total) / float64(window)
+}
+
+// RateStats returns how fast messages have been logged lately, by the
+// logger's clock, counting those since evicted or cleared. Messages are
+// counted in whole seconds, so a window takes in up to a second less.
+func (this *InMemoryLogger) RateStats() Rates {
+  now := this.opts.clock()
+  this.mu.RLock()
+  defer this.mu.RUnlock()
+  if this.rates == nil {
+    return Rates{}
+  }
+  return Rates{
+    OneMinute: this.rates.rate(now, 60),
+    FiveMinutes: this.rates.rate(now, 5*60),
+    FifteenMinutes: this.rates.rate(now, rateWindow),
+  }
+}
+
+// countRate adds n messages logged at now to the rates. The caller must
+// hold the write lock.
+func (this *InMemoryLogger) countRate(now time.Time, n int) {
+  if this.rates == nil {
+    this.rates = new(rateCounter)
+  }
+  this.rates.add(now, n)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+  "math"
+  "testing"
+  "time"
+)
+
+func TestRateStats(t *testing.T) {
+  start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+  clock := NewManualClock(start)
+  l := NewInMemoryLogger(WithClock(clock))
+  if rates := l.RateStats(); rates != (Rates{}) {
+    t.Errorf("RateStats before any Log = %+v", rates)
+  }
+  // Two messages a second for ten minutes.
+  for i := 0; i < 600; i++ {
+    clock.Set(start.Add(time.Duration(i) * time.Second))
+    l.Log("tick")
+    l.Log("tock")
+  }
+  rates := l.RateStats()
+  for name, test := range(map[string]struct{ got, want float64 }{
+    "OneMinute": {rates.OneMinute, 2},
+    "FiveMinutes": {rates.FiveMinutes, 2},
+    "FifteenMinutes": {rates.FifteenMinutes, 1200.0 / 900},
+  }) {
+    if math.Abs(test.got-test.want) > 1e-9 {
+      t.Errorf("%s = %v, want %v", name, test.got, test.want)
+    }
+  }
+  clock.Advance(time.Minute)
+  if rates := l.RateStats(); rates.OneMinute != 0 || rates.FiveMinutes == 0 {
+    t.Errorf("a quiet minute later, RateStats = %+v", rates)
+  }
+  l.Clear()
+  if rates := l.RateStats(); rates.FiveMinutes == 0 {
+    t.Error("Clear reset the rates")
+  }
+}
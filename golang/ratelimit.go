@@ -0,0 +1,121 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "sync"
+  "time"
+)
+
+// RateLimitedLogger drops messages that exceed a token-bucket rate. Once
+// messages flow again, it first logs how many were suppressed.
+type RateLimitedLogger struct {
+  mu sync.Mutex
+  inner LoggerInterface
+  opts options
+  rate float64 // tokens added per second
+  burst float64
+  tokens float64
+  last time.Time
+  suppressed uint64 // since the last summary line
+  dropped uint64
+}
+
+var _ LoggerInterface = (*RateLimitedLogger)(nil)
+
+// NewRateLimitedLogger lets through rate messages per second on average and
+// up to burst at once. The bucket starts full.
+func NewRateLimitedLogger(inner LoggerInterface, rate float64, burst int, opts ...Option) *RateLimitedLogger {
+  this := &RateLimitedLogger{inner: inner, opts: newOptions(opts), rate: rate, burst: float64(burst)}
+  this.tokens = this.burst
+  this.last = this.opts.clock()
+  return this
+}
+
+func (this *RateLimitedLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *RateLimitedLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *RateLimitedLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *RateLimitedLogger) logEntry(e entry) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  now := this.opts.clock()
+  this.tokens += now.Sub(this.last).Seconds() * this.rate
+  if this.tokens > this.burst {
+    this.tokens = this.burst
+  }
+  this.last = now
+  if this.tokens < 1 {
+    this.suppressed++
+    this.dropped++
+    return nil
+  }
+  this.tokens--
+  if this.suppressed > 0 {
+    summary := entry{ctx: e.ctx, level: LevelWarn, mesg: fmt.Sprintf("suppressed %d messages", this.suppressed), callers: e.callers}
+    if err := logEntry(this.inner, summary); err != nil {
+      return err
+    }
+    this.suppressed = 0
+  }
+  return logEntry(this.inner, e)
+}
+
+// Dropped returns how many messages have been dropped in total.
+func (this *RateLimitedLogger) Dropped() uint64 {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return this.dropped
+}
+
+func (this *RateLimitedLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *RateLimitedLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *RateLimitedLogger) Messages() ([]string, error) {
+  return this.inner.Messages()
+}
+
+func (this *RateLimitedLogger) Count() (int, error) {
+  return this.inner.Count()
+}
+
+func (this *RateLimitedLogger) Tail(n int) ([]string, error) {
+  return this.inner.Tail(n)
+}
+
+func (this *RateLimitedLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.inner.Search(pattern, useRegexp)
+}
+
+func (this *RateLimitedLogger) ForEach(fn func(line string) error) error {
+  return this.inner.ForEach(fn)
+}
+
+func (this *RateLimitedLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.inner.MessagesPage(offset, limit)
+}
+
+func (this *RateLimitedLogger) Clear() error {
+  return this.inner.Clear()
+}
+
+func (this *RateLimitedLogger) Sync() error {
+  return this.inner.Sync()
+}
+
+func (this *RateLimitedLogger) Close() error {
+  return this.inner.Close()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+  "fmt"
+  "reflect"
+  "testing"
+  "time"
+)
+
+func TestRateLimitedBurst(t *testing.T) {
+  clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+  inner := NewInMemoryLogger()
+  l := NewRateLimitedLogger(inner, 2, 3, WithClock(clock))
+  for i := 0; i < 5; i++ {
+    l.Log(fmt.Sprintf("message %d", i))
+  }
+  want := []string{"message 0", "message 1", "message 2"}
+  if got, _ := inner.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages() = %q, want the burst of %q", got, want)
+  }
+  if got := l.Dropped(); got != 2 {
+    t.Errorf("Dropped() = %d, want 2", got)
+  }
+}
+
+func TestRateLimitedRefill(t *testing.T) {
+  clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+  inner := NewInMemoryLogger()
+  l := NewRateLimitedLogger(inner, 2, 3, WithClock(clock))
+  for i := 0; i < 4; i++ {
+    l.Log("burst")
+  }
+  clock.Advance(400 * time.Millisecond) // 0.8 of a token
+  l.Log("too soon")
+  clock.Advance(100 * time.Millisecond)
+  l.Log("refilled")
+  l.Log("empty again")
+  clock.Advance(time.Hour) // refills to the burst, no further
+  for i := 0; i < 4; i++ {
+    l.Log(fmt.Sprintf("after %d", i))
+  }
+  want := []string{
+    "burst", "burst", "burst",
+    "suppressed 2 messages", "refilled",
+    "suppressed 1 messages", "after 0", "after 1", "after 2",
+  }
+  if got, _ := inner.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages() = %q, want %q", got, want)
+  }
+  if got := l.Dropped(); got != 4 {
+    t.Errorf("Dropped() = %d, want 4 in total", got)
+  }
+}
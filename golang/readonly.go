@@ -0,0 +1,80 @@
+package main
+
+import (
+  "context"
+)
+
+// readOnlyLogger is the logger returned by ReadOnly.
+type readOnlyLogger struct {
+  inner LoggerInterface
+}
+
+var _ LoggerInterface = (*readOnlyLogger)(nil)
+
+// ReadOnly returns a view of inner for code that may only read it: reads
+// pass through, while Log, Logf, LogCtx, Clear and Close fail with
+// ErrReadOnly and leave inner untouched.
+func ReadOnly(inner LoggerInterface) LoggerInterface {
+  return &readOnlyLogger{inner: inner}
+}
+
+func (this *readOnlyLogger) Log(mesg string) error {
+  return ErrReadOnly
+}
+
+func (this *readOnlyLogger) Logf(level Level, format string, args ...interface{}) error {
+  return ErrReadOnly
+}
+
+func (this *readOnlyLogger) LogCtx(ctx context.Context, mesg string) error {
+  return ErrReadOnly
+}
+
+func (this *readOnlyLogger) logEntry(e entry) error {
+  return ErrReadOnly
+}
+
+func (this *readOnlyLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *readOnlyLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *readOnlyLogger) Messages() ([]string, error) {
+  return this.inner.Messages()
+}
+
+func (this *readOnlyLogger) Count() (int, error) {
+  return this.inner.Count()
+}
+
+func (this *readOnlyLogger) Tail(n int) ([]string, error) {
+  return this.inner.Tail(n)
+}
+
+func (this *readOnlyLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.inner.Search(pattern, useRegexp)
+}
+
+func (this *readOnlyLogger) ForEach(fn func(line string) error) error {
+  return this.inner.ForEach(fn)
+}
+
+func (this *readOnlyLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.inner.MessagesPage(offset, limit)
+}
+
+func (this *readOnlyLogger) Clear() error {
+  return ErrReadOnly
+}
+
+// Sync writes nothing new, so it is allowed through.
+func (this *readOnlyLogger) Sync() error {
+  return this.inner.Sync()
+}
+
+func (this *readOnlyLogger) Close() error {
+  return ErrReadOnly
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "reflect"
+  "testing"
+)
+
+func TestReadOnlyView(t *testing.T) {
+  inner := NewInMemoryLogger()
+  inner.Log("alpha")
+  inner.Log("beta")
+  view := ReadOnly(inner)
+
+  writes := map[string]func() error{
+    "Log": func() error { return view.Log("x") },
+    "Logf": func() error { return view.Logf(LevelError, "x %d", 1) },
+    "LogCtx": func() error { return view.LogCtx(context.Background(), "x") },
+    "WithPrefix": func() error { return view.WithPrefix("p").Log("x") },
+    "WithFields": func() error { return view.WithFields(Fields{"k": "v"}).Log("x") },
+    "LogBatch": func() error { return LogBatch(view, []string{"x"}) },
+    "Clear": view.Clear,
+    "Close": view.Close,
+  }
+  for name, write := range(writes) {
+    if err := write(); !errors.Is(err, ErrReadOnly) {
+      t.Errorf("%s returned %v, want ErrReadOnly", name, err)
+    }
+  }
+
+  want := []string{"alpha", "beta"}
+  if got, err := view.Messages(); err != nil || !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages = %q, %v; want %q", got, err, want)
+  }
+  if n, _ := view.Count(); n != 2 {
+    t.Errorf("Count = %d, want 2", n)
+  }
+  if got, _ := view.Tail(1); !reflect.DeepEqual(got, []string{"beta"}) {
+    t.Errorf("Tail(1) = %q", got)
+  }
+  if got, _ := view.Search("lph", false); !reflect.DeepEqual(got, []string{"alpha"}) {
+    t.Errorf("Search = %q", got)
+  }
+  if err := inner.Log("gamma"); err != nil {
+    t.Errorf("the inner logger refused a Log after the view's Close: %v", err)
+  }
+}
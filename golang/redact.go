@@ -0,0 +1,46 @@
+package main
+
+import (
+  "regexp"
+)
+
+// WithRedactor runs fn over each message before it is formatted, so that
+// what is written or stored, and handed to hooks, is the scrubbed text.
+// Redactors given in several options run in the order given, after any
+// WithTransform. Only the message text is passed through them, not
+// fields or timestamps. If one panics, the whole message is replaced with
+// "[redaction failed]", so that nothing it was to scrub gets out.
+func WithRedactor(fn func(string) string) Option {
+  return func(o *options) {
+    o.redactors = append(o.redactors, fn)
+  }
+}
+
+// NewRegexpRedactor returns a redactor for WithRedactor that replaces each
+// match of pattern with replacement, which may refer to submatches as in
+// regexp.Regexp.ReplaceAllString.
+func NewRegexpRedactor(pattern, replacement string) (func(string) string, error) {
+  re, err := regexp.Compile(pattern)
+  if err != nil {
+    return nil, err
+  }
+  return func(mesg string) string {
+    return re.ReplaceAllString(mesg, replacement)
+  }, nil
+}
+
+// redactionFailed stands in for a message a redactor panicked on.
+const redactionFailed = "[redaction failed]"
+
+// redact applies the WithRedactor functions to mesg, in order.
+func (this *options) redact(mesg string) string {
+  for _, fn := range(this.redactors) {
+    ok := this.safeCall("redactor", func() {
+      mesg = fn(mesg)
+    })
+    if !ok {
+      return redactionFailed
+    }
+  }
+  return mesg
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+  "os"
+  "reflect"
+  "strings"
+  "testing"
+)
+
+func TestRegexpRedactorEmail(t *testing.T) {
+  email, err := NewRegexpRedactor(`[\w.+-]+@[\w-]+\.[\w.]+`, "[redacted]")
+  if err != nil {
+    t.Fatal(err)
+  }
+  var hooked []string
+  hook := WithHook(func(level Level, msg string) {
+    hooked = append(hooked, msg)
+  })
+  for name, l := range(bothLoggers(t, WithRedactor(email), hook)) {
+    hooked = nil
+    l.Log("signup from jane.doe+test@example.co.uk ok")
+    want := []string{"signup from [redacted] ok"}
+    if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+      t.Errorf("%s: Messages = %q, want %q", name, got, want)
+    }
+    if !reflect.DeepEqual(hooked, want) {
+      t.Errorf("%s: hook saw %q, want %q", name, hooked, want)
+    }
+    if local, ok := l.(*LocalLogger); ok {
+      data, _ := os.ReadFile(local.filename)
+      if strings.Contains(string(data), "jane.doe") {
+        t.Errorf("%s: the file holds the address: %q", name, data)
+      }
+    }
+  }
+}
+
+func TestRedactorsChainInOrder(t *testing.T) {
+  l := NewInMemoryLogger(
+    WithRedactor(func(s string) string { return strings.ReplaceAll(s, "secret", "token") }),
+    WithRedactor(func(s string) string { return strings.ReplaceAll(s, "token", "***") }),
+  )
+  l.Log("the secret is out")
+  AssertLogged(t, l, "the *** is out")
+}
+
+func TestRedactorPanicHidesMessage(t *testing.T) {
+  l := NewInMemoryLogger(WithErrorHandler(func(error) {}), WithRedactor(func(s string) string {
+    panic("bad redactor")
+  }))
+  l.Log("password=hunter2")
+  AssertNotLogged(t, l, "hunter2")
+  AssertLogged(t, l, "[redaction failed]")
+}
+
+func TestRegexpRedactorBadPattern(t *testing.T) {
+  if _, err := NewRegexpRedactor("(", "x"); err == nil {
+    t.Error("NewRegexpRedactor accepted a bad pattern")
+  }
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "math/rand"
+  "time"
+)
+
+// RetryPolicy says how often and how patiently RetryLogger tries a write.
+// The wait before attempt n+1 is BaseDelay * Multiplier^(n-1), moved up or
+// down at random by up to Jitter of itself.
+type RetryPolicy struct {
+  MaxAttempts int // in all, counting the first; less than 1 means 1
+  BaseDelay time.Duration
+  Multiplier float64 // less than 1 means 1, a constant delay
+  Jitter float64 // a fraction, such as 0.2 for ±20%
+}
+
+// delay is the wait after the given failed attempt, counting from 1.
+func (this RetryPolicy) delay(attempt int) time.Duration {
+  multiplier := this.Multiplier
+  if multiplier < 1 {
+    multiplier = 1
+  }
+  d := float64(this.BaseDelay)
+  for i := 1; i < attempt; i++ {
+    d *= multiplier
+  }
+  if this.Jitter > 0 {
+    d += d * this.Jitter * (2*rand.Float64() - 1)
+  }
+  return time.Duration(d)
+}
+
+// RetryLogger writes through to a logger whose writes can fail for a
+// while, such as a NetworkLogger, trying each one again under a
+// RetryPolicy. Reads go straight to the wrapped logger.
+type RetryLogger struct {
+  inner LoggerInterface
+  policy RetryPolicy
+}
+
+var _ LoggerInterface = (*RetryLogger)(nil)
+
+func NewRetryLogger(inner LoggerInterface, policy RetryPolicy) *RetryLogger {
+  return &RetryLogger{inner: inner, policy: policy}
+}
+
+func (this *RetryLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *RetryLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+// LogCtx gives up, between attempts or during a wait, once ctx is done.
+func (this *RetryLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+// logEntry returns the last attempt's error once they have all failed.
+func (this *RetryLogger) logEntry(e entry) error {
+  ctx := e.ctx
+  if ctx == nil {
+    ctx = context.Background()
+  }
+  for attempt := 1; ; attempt++ {
+    if err := ctx.Err(); err != nil {
+      return err
+    }
+    err := logEntry(this.inner, e)
+    if err == nil || attempt >= this.policy.MaxAttempts {
+      return err
+    }
+    timer := time.NewTimer(this.policy.delay(attempt))
+    select {
+    case <-timer.C:
+    case <-ctx.Done():
+      timer.Stop()
+      return ctx.Err()
+    }
+  }
+}
+
+func (this *RetryLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *RetryLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *RetryLogger) Messages() ([]string, error) {
+  return this.inner.Messages()
+}
+
+func (this *RetryLogger) Count() (int, error) {
+  return this.inner.Count()
+}
+
+func (this *RetryLogger) Tail(n int) ([]string, error) {
+  return this.inner.Tail(n)
+}
+
+func (this *RetryLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.inner.Search(pattern, useRegexp)
+}
+
+func (this *RetryLogger) ForEach(fn func(line string) error) error {
+  return this.inner.ForEach(fn)
+}
+
+func (this *RetryLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.inner.MessagesPage(offset, limit)
+}
+
+func (this *RetryLogger) Clear() error {
+  return this.inner.Clear()
+}
+
+func (this *RetryLogger) Sync() error {
+  return this.inner.Sync()
+}
+
+func (this *RetryLogger) Close() error {
+  return this.inner.Close()
+}
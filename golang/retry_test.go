@@ -0,0 +1,80 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "testing"
+  "time"
+)
+
+// failingFor is an InMemoryLogger whose next failures writes fail,
+// counting every attempt.
+type failingFor struct {
+  *InMemoryLogger
+  failures int
+  attempts int
+}
+
+func (this *failingFor) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *failingFor) logEntry(e entry) error {
+  this.attempts++
+  if this.failures > 0 {
+    this.failures--
+    return fmt.Errorf("attempt %d failed", this.attempts)
+  }
+  return this.InMemoryLogger.logEntry(e)
+}
+
+func TestRetryLandsOnThirdAttempt(t *testing.T) {
+  inner := &failingFor{InMemoryLogger: NewInMemoryLogger(), failures: 2}
+  l := NewRetryLogger(inner, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, Multiplier: 2, Jitter: 0.2})
+  if err := l.Log("eventually"); err != nil {
+    t.Fatalf("Log = %v", err)
+  }
+  if inner.attempts != 3 {
+    t.Errorf("took %d attempts, want 3", inner.attempts)
+  }
+  AssertLoggedCount(t, inner, 1)
+}
+
+func TestRetryReturnsLastError(t *testing.T) {
+  inner := &failingFor{InMemoryLogger: NewInMemoryLogger(), failures: 10}
+  l := NewRetryLogger(inner, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+  if err := l.Log("never"); err == nil || err.Error() != "attempt 3 failed" {
+    t.Errorf("Log = %v, want the third attempt's error", err)
+  }
+  AssertLoggedCount(t, inner, 0)
+}
+
+func TestRetryWaitIsInterruptible(t *testing.T) {
+  inner := &failingFor{InMemoryLogger: NewInMemoryLogger(), failures: 10}
+  l := NewRetryLogger(inner, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour})
+  ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+  defer cancel()
+  start := time.Now()
+  if err := l.LogCtx(ctx, "x"); !errors.Is(err, context.DeadlineExceeded) {
+    t.Errorf("LogCtx = %v, want %v", err, context.DeadlineExceeded)
+  }
+  if elapsed := time.Since(start); elapsed > time.Second || inner.attempts != 1 {
+    t.Errorf("gave up after %v and %d attempts", elapsed, inner.attempts)
+  }
+}
+
+func TestRetryDelay(t *testing.T) {
+  policy := RetryPolicy{BaseDelay: 10 * time.Millisecond, Multiplier: 2}
+  for attempt, want := range(map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 4: 80 * time.Millisecond}) {
+    if got := policy.delay(attempt); got != want {
+      t.Errorf("delay(%d) = %v, want %v", attempt, got, want)
+    }
+  }
+  policy.Jitter = 0.5
+  for i := 0; i < 100; i++ {
+    if d := policy.delay(1); d < 5*time.Millisecond || d > 15*time.Millisecond {
+      t.Fatalf("delay with jitter 0.5 = %v, want within 5ms of 10ms", d)
+    }
+  }
+}
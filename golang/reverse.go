@@ -0,0 +1,105 @@
+package main
+
+import (
+  "bytes"
+)
+
+// forEachReverseIn calls fn for each message, newest first, stopping at
+// the first error.
+func forEachReverseIn(messages []string, fn func(line string) error) error {
+  for i := len(messages) - 1; i >= 0; i-- {
+    if err := fn(messages[i]); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+// ForEachReverse is ForEach visiting the newest message first.
+func (this *InMemoryLogger) ForEachReverse(fn func(line string) error) error {
+  messages, _ := this.Messages()
+  return forEachReverseIn(messages, fn)
+}
+
+// ForEachReverse is ForEach visiting the newest record first. It reads the
+// file backwards a chunk at a time, so stopping early leaves the older
+// part unread. Compressed and encrypted files can only be read from the
+// start, and CSV rows and JSON lines must be parsed from it, so those are
+// read whole first.
+func (this *LocalLogger) ForEachReverse(fn func(line string) error) error {
+  if err := this.beginRead(); err != nil {
+    return err
+  }
+  defer this.mu.RUnlock()
+  return this.forEachReverseLocked(fn)
+}
+
+// forEachReverseLocked is ForEachReverse for a caller that has begun
+// reading.
+func (this *LocalLogger) forEachReverseLocked(fn func(line string) error) error {
+  if this.opts.mirror && !this.ReadFromDisk {
+    return forEachReverseIn(append([]string(nil), this.mirror...), fn)
+  }
+  return this.scanLinesReverse(this.filename, fn)
+}
+
+// scanLinesReverse is scanLines visiting the last line first.
+func (this *LocalLogger) scanLinesReverse(filename string, fn func(line string) error) error {
+  if this.opts.gzip || this.aead != nil || this.parsed() {
+    lines, err := this.readLines(filename)
+    if err != nil {
+      return err
+    }
+    return forEachReverseIn(lines, fn)
+  }
+  file, err := this.opts.fileSystem().Open(filename)
+  if err != nil {
+    return err
+  }
+  defer file.Close()
+  sep := this.opts.recordSeparator()
+  pos, err := this.readEnd(file, sep)
+  if err != nil {
+    return err
+  }
+
+  emit := func(record []byte) error {
+    if sep == '\n' {
+      record = bytes.TrimSuffix(record, []byte{'\r'}) // as bufio.ScanLines does
+    }
+    return fn(string(record))
+  }
+  // data holds what has been read but not visited: the start of a record,
+  // or with the final separator still on it, before the first chunk.
+  var data []byte
+  for pos > 0 {
+    step := int64(tailChunk)
+    if step > pos {
+      step = pos
+    }
+    pos -= step
+    chunk := make([]byte, step, step+int64(len(data)))
+    if _, err := file.ReadAt(chunk, pos); err != nil {
+      return err
+    }
+    first := data == nil
+    data = append(chunk, data...)
+    if first {
+      data = bytes.TrimSuffix(data, []byte{sep})
+    }
+    for {
+      i := bytes.LastIndexByte(data, sep)
+      if i < 0 {
+        break
+      }
+      if err := emit(data[i+1:]); err != nil {
+        return err
+      }
+      data = data[:i]
+    }
+  }
+  if data != nil {
+    return emit(data) // the first record, though it may be empty
+  }
+  return nil
+}
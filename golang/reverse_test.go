@@ -0,0 +1,76 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+  "reflect"
+  "testing"
+)
+
+func TestForEachReverseNewestFirst(t *testing.T) {
+  for name, l := range(bothLoggers(t)) {
+    var want []string
+    for i := 0; i < 50; i++ {
+      mesg := fmt.Sprintf("message %d", i)
+      l.Log(mesg)
+      want = append([]string{mesg}, want...)
+    }
+    var got []string
+    err := l.(interface {
+      ForEachReverse(func(line string) error) error
+    }).ForEachReverse(func(line string) error {
+      got = append(got, line)
+      return nil
+    })
+    if err != nil || !reflect.DeepEqual(got, want) {
+      t.Errorf("%s: ForEachReverse visited %q, %v; want %q", name, got, err, want)
+    }
+    if got[0] != "message 49" {
+      t.Errorf("%s: first visited %q, want the last logged", name, got[0])
+    }
+  }
+}
+
+func TestForEachReverseStopsWithoutReadingAll(t *testing.T) {
+  fsys := &countingFS{MemFS: NewMemFS()}
+  l, err := NewLocalLogger("big.log", true, WithFS(fsys))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  for i := 0; i < 100000; i++ {
+    l.Logf(LevelInfo, "line %06d of a file of several megabytes", i)
+  }
+  data, _ := fsys.ReadFile("big.log")
+  fsys.read = 0
+  stop := errors.New("stop")
+  var first string
+  err = l.ForEachReverse(func(line string) error {
+    first = line
+    return stop
+  })
+  if err != stop || first != "line 099999 of a file of several megabytes" {
+    t.Errorf("ForEachReverse = %v after %q", err, first)
+  }
+  if fsys.read >= int64(len(data))/100 {
+    t.Errorf("stopping at the first line read %d bytes of a %d byte file", fsys.read, len(data))
+  }
+}
+
+func TestForEachReverseEmptyFirstLine(t *testing.T) {
+  for name, l := range(bothLoggers(t)) {
+    for _, mesg := range([]string{"", "second", "third"}) {
+      l.Log(mesg)
+    }
+    var got []string
+    err := l.(interface {
+      ForEachReverse(func(line string) error) error
+    }).ForEachReverse(func(line string) error {
+      got = append(got, line)
+      return nil
+    })
+    if want := []string{"third", "second", ""}; err != nil || !reflect.DeepEqual(got, want) {
+      t.Errorf("%s: ForEachReverse visited %q, %v; want %q", name, got, err, want)
+    }
+  }
+}
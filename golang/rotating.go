@@ -0,0 +1,350 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+  "io/fs"
+  "strconv"
+  "strings"
+  "sync"
+)
+
+// RotatingLocalLogger is a LocalLogger that starts a fresh file once the
+// current one reaches maxBytes. Full files are renamed to filename.1,
+// filename.2, ... numbered in the order they filled, and the latest keep
+// of them are kept. A rotated file is never renamed again, so that an
+// OnRotate call can take its time over it.
+type RotatingLocalLogger struct {
+  *LocalLogger
+  maxBytes int64
+  keep int
+  size int64 // guarded by the LocalLogger's mu
+  newest int // the number of the latest rotated file, guarded by mu
+
+  // IncludeRotated makes Messages read the kept files, oldest first,
+  // before the active one.
+  IncludeRotated bool
+
+  // OnRotate, if set, is called with the name of each file just rotated
+  // out, so that it can be archived; see NewS3Archiver. It runs on a
+  // goroutine of its own, so neither Log nor later rotations wait for it,
+  // and it may log to this logger; Close does wait for it. A file is not
+  // deleted for falling out of the kept ones while its call runs, which
+  // leaves it to the hook. Its error goes to the WithErrorHandler
+  // function. With keep 0 the file is deleted instead and OnRotate is not
+  // called.
+  OnRotate func(path string) error
+  hooks sync.WaitGroup
+  hookMu sync.Mutex
+  hooked map[string]bool // the files whose OnRotate call is running
+}
+
+var _ LoggerInterface = (*RotatingLocalLogger)(nil)
+
+func NewRotatingLocalLogger(filename string, maxBytes int64, keep int, opts ...Option) (*RotatingLocalLogger, error) {
+  local, err := NewLocalLogger(filename, false, opts...)
+  if err != nil {
+    return nil, err
+  }
+  info, err := local.file.Stat()
+  if err != nil {
+    local.Close()
+    return nil, err
+  }
+  newest, err := lastGeneration(local.opts.fileSystem(), filename)
+  if err != nil {
+    local.Close()
+    return nil, err
+  }
+  this := &RotatingLocalLogger{LocalLogger: local, maxBytes: maxBytes, keep: keep, size: info.Size(), newest: newest, hooked: make(map[string]bool)}
+  local.rotation = this
+  return this, nil
+}
+
+// generationName returns the name of the nth file rotated out.
+func (this *RotatingLocalLogger) generationName(n int) string {
+  return fmt.Sprintf("%s.%d", this.filename, n)
+}
+
+// rotatedName returns the name of the ith most recent file rotated out,
+// for i from 1 to kept().
+func (this *RotatingLocalLogger) rotatedName(i int) string {
+  return this.generationName(this.newest - i + 1)
+}
+
+// kept returns how many rotated files there may be.
+func (this *RotatingLocalLogger) kept() int {
+  return min(this.keep, this.newest)
+}
+
+// lastGeneration returns the highest n for which filename.n exists in
+// fsys, or 0 if there is none. An FS that cannot list its files is probed
+// for filename.1, filename.2, ... until one is missing.
+func lastGeneration(fsys FS, filename string) (int, error) {
+  prefix := filename + "."
+  lister, ok := fsys.(nameLister)
+  if !ok {
+    n := 0
+    for {
+      file, err := fsys.Open(prefix + strconv.Itoa(n+1))
+      if errors.Is(err, fs.ErrNotExist) {
+        return n, nil
+      } else if err != nil {
+        return 0, err
+      }
+      file.Close()
+      n++
+    }
+  }
+  names, err := lister.namesWithPrefix(prefix)
+  if err != nil {
+    return 0, err
+  }
+  newest := 0
+  for _, name := range(names) {
+    suffix := strings.TrimPrefix(name, prefix)
+    n, err := strconv.Atoi(suffix)
+    if err == nil && n > newest && strconv.Itoa(n) == suffix {
+      newest = n
+    }
+  }
+  return newest, nil
+}
+
+func (this *RotatingLocalLogger) beforeWrite(n int) error {
+  if this.size > 0 && this.size+int64(n) > this.maxBytes {
+    return this.rotate()
+  }
+  return nil
+}
+
+// afterWrite rotates straight away when a single record was larger than
+// maxBytes; such a record is still written, alone in its own file.
+func (this *RotatingLocalLogger) afterWrite(n int) error {
+  this.size += int64(n)
+  if this.size > this.maxBytes {
+    return this.rotate()
+  }
+  return nil
+}
+
+func (this *RotatingLocalLogger) rotate() error {
+  if err := this.closeLocked(); err != nil {
+    return err
+  }
+  fsys := this.opts.fileSystem()
+  var rotated string
+  if this.keep > 0 {
+    rotated = this.generationName(this.newest + 1)
+    if err := fsys.Rename(this.filename, rotated); err != nil {
+      return err
+    }
+    this.newest++
+    if old := this.newest - this.keep; old >= 1 && !this.isHooked(this.generationName(old)) {
+      err := fsys.Remove(this.generationName(old))
+      if err != nil && !errors.Is(err, fs.ErrNotExist) {
+        return err
+      }
+    }
+  } else if err := fsys.Remove(this.filename); err != nil {
+    return err
+  }
+  this.size = 0
+  this.mirror = nil
+  if err := this.openLocked(false); err != nil {
+    return err
+  }
+  if this.OnRotate != nil && rotated != "" {
+    this.hookMu.Lock()
+    this.hooked[rotated] = true
+    this.hookMu.Unlock()
+    this.hooks.Add(1)
+    go this.runOnRotate(this.OnRotate, rotated)
+  }
+  return nil
+}
+
+// isHooked reports whether an OnRotate call on the named file is running.
+func (this *RotatingLocalLogger) isHooked(name string) bool {
+  this.hookMu.Lock()
+  defer this.hookMu.Unlock()
+  return this.hooked[name]
+}
+
+func (this *RotatingLocalLogger) runOnRotate(hook func(string) error, path string) {
+  defer this.hooks.Done()
+  defer func() {
+    this.hookMu.Lock()
+    delete(this.hooked, path)
+    this.hookMu.Unlock()
+  }()
+  this.opts.safeCall("OnRotate", func() {
+    this.opts.handle(hook(path))
+  })
+}
+
+// Reopen reopens the active file, as LocalLogger.Reopen does, and counts
+// its size afresh.
+func (this *RotatingLocalLogger) Reopen() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if err := this.reopenLocked(); err != nil {
+    return err
+  }
+  info, err := this.file.Stat()
+  if err != nil {
+    return err
+  }
+  this.size = info.Size()
+  return nil
+}
+
+// Close closes the file, as LocalLogger.Close does, and then waits for any
+// OnRotate call still running.
+func (this *RotatingLocalLogger) Close() error {
+  err := this.LocalLogger.Close()
+  this.hooks.Wait()
+  return err
+}
+
+// WithFields returns a child that reads through Messages of this logger.
+func (this *RotatingLocalLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+// WithPrefix returns a child that reads through Messages of this logger.
+func (this *RotatingLocalLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *RotatingLocalLogger) Messages() ([]string, error) {
+  if !this.IncludeRotated {
+    return this.LocalLogger.Messages()
+  }
+  if err := this.beginRead(); err != nil {
+    return nil, err
+  }
+  defer this.mu.RUnlock()
+  var messages []string
+  for i := this.kept(); i >= 1; i-- {
+    lines, err := this.readLines(this.rotatedName(i))
+    if errors.Is(err, fs.ErrNotExist) {
+      continue
+    }
+    if err != nil {
+      return nil, err
+    }
+    messages = append(messages, lines...)
+  }
+  lines, err := this.messagesLocked()
+  if err != nil {
+    return nil, err
+  }
+  return append(messages, lines...), nil
+}
+
+func (this *RotatingLocalLogger) Count() (int, error) {
+  if !this.IncludeRotated {
+    return this.LocalLogger.Count()
+  }
+  if err := this.beginRead(); err != nil {
+    return 0, err
+  }
+  defer this.mu.RUnlock()
+  count, err := this.countLocked()
+  if err != nil {
+    return 0, err
+  }
+  for i := this.kept(); i >= 1; i-- {
+    n, err := this.countLines(this.rotatedName(i))
+    if errors.Is(err, fs.ErrNotExist) {
+      continue
+    }
+    if err != nil {
+      return 0, err
+    }
+    count += n
+  }
+  return count, nil
+}
+
+func (this *RotatingLocalLogger) Tail(n int) ([]string, error) {
+  if !this.IncludeRotated {
+    return this.LocalLogger.Tail(n)
+  }
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return tailOf(messages, n), nil
+}
+
+func (this *RotatingLocalLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  if !this.IncludeRotated {
+    return this.LocalLogger.Search(pattern, useRegexp)
+  }
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return searchIn(messages, pattern, useRegexp)
+}
+
+func (this *RotatingLocalLogger) ForEach(fn func(line string) error) error {
+  if !this.IncludeRotated {
+    return this.LocalLogger.ForEach(fn)
+  }
+  if err := this.beginRead(); err != nil {
+    return err
+  }
+  defer this.mu.RUnlock()
+  for i := this.kept(); i >= 1; i-- {
+    err := this.scanLines(this.rotatedName(i), fn)
+    if errors.Is(err, fs.ErrNotExist) {
+      continue
+    }
+    if err != nil {
+      return err
+    }
+  }
+  return this.forEachLocked(fn)
+}
+
+// ForEachReverse visits the active file newest first and then, with
+// IncludeRotated, the kept files from the most recent back.
+func (this *RotatingLocalLogger) ForEachReverse(fn func(line string) error) error {
+  if !this.IncludeRotated {
+    return this.LocalLogger.ForEachReverse(fn)
+  }
+  if err := this.beginRead(); err != nil {
+    return err
+  }
+  defer this.mu.RUnlock()
+  if err := this.forEachReverseLocked(fn); err != nil {
+    return err
+  }
+  for i := 1; i <= this.kept(); i++ {
+    err := this.scanLinesReverse(this.rotatedName(i), fn)
+    if errors.Is(err, fs.ErrNotExist) {
+      continue
+    }
+    if err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+func (this *RotatingLocalLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return pageFrom(this.ForEach, offset, limit)
+}
+
+func (this *RotatingLocalLogger) Clear() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if err := this.clearLocked(); err != nil {
+    return err
+  }
+  this.size = 0
+  return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+  "errors"
+  "os"
+  "path/filepath"
+  "sync"
+  "testing"
+  "time"
+)
+
+func TestOnRotatePath(t *testing.T) {
+  name := filepath.Join(t.TempDir(), "app.log")
+  l, err := NewRotatingLocalLogger(name, 10, 2)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  paths := make(chan string, 1)
+  contents := make(chan string, 1)
+  l.OnRotate = func(path string) error {
+    data, err := os.ReadFile(path)
+    paths <- path
+    contents <- string(data)
+    return err
+  }
+  l.Log("aaaaaaaa")
+  l.Log("bbbbbbbb")
+  select {
+  case path := <-paths:
+    if want := name + ".1"; path != want {
+      t.Errorf("OnRotate got %q, want %q", path, want)
+    }
+    if data := <-contents; data != "aaaaaaaa\n" {
+      t.Errorf("the rotated file held %q, want the first message", data)
+    }
+  case <-time.After(5 * time.Second):
+    t.Fatal("OnRotate was not called")
+  }
+  if got, _ := l.Messages(); len(got) != 1 || got[0] != "bbbbbbbb" {
+    t.Errorf("Messages() = %q, want only the message after rotation", got)
+  }
+}
+
+func TestOnRotateDoesNotBlockLog(t *testing.T) {
+  l, err := NewRotatingLocalLogger(filepath.Join(t.TempDir(), "app.log"), 10, 2)
+  if err != nil {
+    t.Fatal(err)
+  }
+  gate := make(chan struct{})
+  l.OnRotate = func(path string) error {
+    <-gate
+    return nil
+  }
+  done := make(chan struct{})
+  go func() {
+    l.Log("aaaaaaaa")
+    l.Log("bbbbbbbb") // rotates, starting OnRotate
+    close(done)
+  }()
+  select {
+  case <-done:
+  case <-time.After(5 * time.Second):
+    t.Fatal("Log waited for OnRotate")
+  }
+  close(gate)
+  l.Close()
+}
+
+func TestOnRotateErrorHandled(t *testing.T) {
+  errUpload := errors.New("upload failed")
+  handled := make(chan error, 1)
+  l, err := NewRotatingLocalLogger(filepath.Join(t.TempDir(), "app.log"), 10, 2,
+    WithErrorHandler(func(err error) { handled <- err }))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.OnRotate = func(path string) error {
+    return errUpload
+  }
+  l.Log("aaaaaaaa")
+  if err := l.Log("bbbbbbbb"); err != nil {
+    t.Fatalf("Log = %v, want the OnRotate error kept out of it", err)
+  }
+  select {
+  case err := <-handled:
+    if !errors.Is(err, errUpload) {
+      t.Errorf("error handler got %v, want %v", err, errUpload)
+    }
+  case <-time.After(5 * time.Second):
+    t.Fatal("the OnRotate error did not reach the error handler")
+  }
+}
+
+func TestOnRotateBlockingHookLogs(t *testing.T) {
+  name := filepath.Join(t.TempDir(), "app.log")
+  var mu sync.Mutex
+  var handled []error
+  l, err := NewRotatingLocalLogger(name, 10, 1, WithErrorHandler(func(err error) {
+    mu.Lock()
+    defer mu.Unlock()
+    handled = append(handled, err)
+  }))
+  if err != nil {
+    t.Fatal(err)
+  }
+  gate := make(chan struct{})
+  var hooked sync.WaitGroup
+  hooked.Add(3)
+  l.OnRotate = func(path string) error {
+    <-gate
+    switch filepath.Base(path) {
+    case "app.log.1", "app.log.2", "app.log.3":
+      defer hooked.Done()
+      return l.Log("archived " + filepath.Base(path))
+    }
+    return nil // rotated out by the hooks' own messages
+  }
+  done := make(chan struct{})
+  go func() {
+    defer close(done)
+    for _, mesg := range([]string{"aaaaaaaa", "bbbbbbbb", "cccccccc", "dddddddd"}) {
+      l.Log(mesg) // rotates on each after the first, with every hook blocked
+    }
+  }()
+  select {
+  case <-done:
+  case <-time.After(5 * time.Second):
+    t.Fatal("Log waited for a blocked OnRotate")
+  }
+  for _, path := range([]string{name + ".1", name + ".2", name + ".3"}) {
+    if _, err := os.Stat(path); err != nil {
+      t.Errorf("%s, whose hook is running, is gone: %v", path, err)
+    }
+  }
+  close(gate)
+  finished := make(chan error)
+  go func() {
+    hooked.Wait()
+    finished <- l.Close()
+  }()
+  select {
+  case <-finished:
+  case <-time.After(5 * time.Second):
+    t.Fatal("the hooks deadlocked logging to the logger")
+  }
+  if handled != nil {
+    t.Errorf("the hooks' Logs failed: %v", handled)
+  }
+}
+
+func TestOnRotateFileKeepsItsName(t *testing.T) {
+  name := filepath.Join(t.TempDir(), "app.log")
+  l, err := NewRotatingLocalLogger(name, 10, 3)
+  if err != nil {
+    t.Fatal(err)
+  }
+  gate := make(chan struct{})
+  seen := make(chan string, 2)
+  l.OnRotate = func(path string) error {
+    <-gate
+    data, err := os.ReadFile(path)
+    seen <- string(data)
+    return err
+  }
+  l.Log("aaaaaaaa")
+  l.Log("bbbbbbbb") // rotates a out, its hook waiting
+  l.Log("cccccccc") // rotates b out, which must leave a's file alone
+  close(gate)
+  l.Close()
+  got := []string{<-seen, <-seen}
+  if !(got[0] == "aaaaaaaa\n" && got[1] == "bbbbbbbb\n" || got[0] == "bbbbbbbb\n" && got[1] == "aaaaaaaa\n") {
+    t.Errorf("the hooks read %q, want each its own file", got)
+  }
+}
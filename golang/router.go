@@ -0,0 +1,148 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "sort"
+)
+
+// levelRouter is the logger returned by LevelRouter.
+type levelRouter struct {
+  routes map[Level]LoggerInterface
+  fallback LoggerInterface
+  loggers []LoggerInterface // each distinct destination once, for reads
+}
+
+var _ LoggerInterface = (*levelRouter)(nil)
+
+// LevelRouter returns a logger sending each message to the logger routes
+// has for its level, or to fallback if it has none; a nil fallback drops
+// those messages. Reads see every destination merged as MergeSorted does,
+// in timestamp order when the destinations write WithTimestamp(""), and
+// otherwise grouped by destination, lowest level first, fallback last.
+func LevelRouter(routes map[Level]LoggerInterface, fallback LoggerInterface) LoggerInterface {
+  this := &levelRouter{routes: make(map[Level]LoggerInterface, len(routes)), fallback: fallback}
+  levels := make([]Level, 0, len(routes))
+  for level, logger := range(routes) {
+    this.routes[level] = logger
+    levels = append(levels, level)
+  }
+  sort.Slice(levels, func(i, j int) bool {
+    return levels[i] < levels[j]
+  })
+  seen := make(map[LoggerInterface]bool)
+  for _, level := range(levels) {
+    this.add(this.routes[level], seen)
+  }
+  if fallback != nil {
+    this.add(fallback, seen)
+  }
+  return this
+}
+
+func (this *levelRouter) add(logger LoggerInterface, seen map[LoggerInterface]bool) {
+  if !seen[logger] {
+    seen[logger] = true
+    this.loggers = append(this.loggers, logger)
+  }
+}
+
+func (this *levelRouter) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *levelRouter) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *levelRouter) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *levelRouter) logEntry(e entry) error {
+  logger, ok := this.routes[e.level]
+  if !ok {
+    logger = this.fallback
+  }
+  if logger == nil {
+    return e.canceled()
+  }
+  return logEntry(logger, e)
+}
+
+func (this *levelRouter) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *levelRouter) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *levelRouter) Messages() ([]string, error) {
+  return MergeSorted(this.loggers...)
+}
+
+func (this *levelRouter) Count() (int, error) {
+  total := 0
+  for _, logger := range(this.loggers) {
+    n, err := logger.Count()
+    if err != nil {
+      return 0, err
+    }
+    total += n
+  }
+  return total, nil
+}
+
+func (this *levelRouter) Tail(n int) ([]string, error) {
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return tailOf(messages, n), nil
+}
+
+func (this *levelRouter) Search(pattern string, useRegexp bool) ([]string, error) {
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return searchIn(messages, pattern, useRegexp)
+}
+
+func (this *levelRouter) ForEach(fn func(line string) error) error {
+  messages, err := this.Messages()
+  if err != nil {
+    return err
+  }
+  return forEachIn(messages, fn)
+}
+
+func (this *levelRouter) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return pageFrom(this.ForEach, offset, limit)
+}
+
+func (this *levelRouter) Clear() error {
+  var errs []error
+  for _, logger := range(this.loggers) {
+    errs = append(errs, logger.Clear())
+  }
+  return errors.Join(errs...)
+}
+
+func (this *levelRouter) Sync() error {
+  var errs []error
+  for _, logger := range(this.loggers) {
+    errs = append(errs, logger.Sync())
+  }
+  return errors.Join(errs...)
+}
+
+func (this *levelRouter) Close() error {
+  var errs []error
+  for _, logger := range(this.loggers) {
+    errs = append(errs, logger.Close())
+  }
+  return errors.Join(errs...)
+}
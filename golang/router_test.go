@@ -0,0 +1,65 @@
+package main
+
+import (
+  "fmt"
+  "reflect"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestLevelRouterPlacement(t *testing.T) {
+  errorLog := newTestLocal(t)
+  infoLog := NewInMemoryLogger()
+  fallback := NewInMemoryLogger()
+  l := LevelRouter(map[Level]LoggerInterface{LevelError: errorLog, LevelInfo: infoLog}, fallback)
+  l.Logf(LevelError, "disk full")
+  l.Logf(LevelInfo, "started")
+  l.Log("plain")
+  l.Logf(LevelDebug, "cache miss")
+  for name, test := range(map[string]struct {
+    logger LoggerInterface
+    want []string
+  }{
+    "error route": {errorLog, []string{"disk full"}},
+    "info route": {infoLog, []string{"started", "plain"}},
+    "fallback": {fallback, []string{"cache miss"}},
+  }) {
+    if got, _ := test.logger.Messages(); !reflect.DeepEqual(got, test.want) {
+      t.Errorf("%s holds %q, want %q", name, got, test.want)
+    }
+  }
+  if n, _ := l.Count(); n != 4 {
+    t.Errorf("Count = %d, want 4", n)
+  }
+}
+
+func TestLevelRouterMergesByTime(t *testing.T) {
+  clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+  opts := []Option{WithClock(clock), WithTimestamp("")}
+  errorLog := NewInMemoryLogger(opts...)
+  infoLog := NewInMemoryLogger(opts...)
+  l := LevelRouter(map[Level]LoggerInterface{LevelError: errorLog}, infoLog)
+  for i, level := range([]Level{LevelInfo, LevelError, LevelInfo, LevelError}) {
+    l.Logf(level, "message %d", i)
+    clock.Advance(time.Second)
+  }
+  messages, err := l.Messages()
+  if err != nil || len(messages) != 4 {
+    t.Fatalf("Messages = %q, %v", messages, err)
+  }
+  for i, mesg := range(messages) {
+    if !strings.HasSuffix(mesg, fmt.Sprintf(" message %d", i)) {
+      t.Errorf("message %d = %q, want them in the order logged", i, mesg)
+    }
+  }
+}
+
+func TestLevelRouterNilFallbackDrops(t *testing.T) {
+  errorLog := NewInMemoryLogger()
+  l := LevelRouter(map[Level]LoggerInterface{LevelError: errorLog}, nil)
+  if err := l.Log("nowhere"); err != nil {
+    t.Errorf("Log with no route = %v, want it dropped", err)
+  }
+  AssertLoggedCount(t, l, 0)
+}
@@ -0,0 +1,67 @@
+//go:build s3
+
+package main
+
+import (
+  "context"
+  "os"
+  "path/filepath"
+  "time"
+
+  "github.com/aws/aws-sdk-go-v2/aws"
+  "github.com/aws/aws-sdk-go-v2/config"
+  "github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3Putter is the part of *s3.Client S3Archiver uses, so that a test can
+// stand in for the bucket.
+type s3Putter interface {
+  PutObject(ctx context.Context, in *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3Archiver uploads rotated log files to an S3 bucket and then deletes
+// them locally. Its Archive method is meant for RotatingLocalLogger's
+// OnRotate.
+type S3Archiver struct {
+  client s3Putter
+  bucket string
+  prefix string
+  now func() time.Time
+}
+
+// NewS3Archiver uploads to bucket, naming each object prefix followed by
+// the file's base name and the time of the upload, as in
+// "logs/app.log.1.20260102T150405.000000000Z", since rotation numbers the
+// files from 1 again once the archived ones are gone. Credentials and region come from the environment and
+// shared config files, as the AWS SDK finds them by default.
+func NewS3Archiver(bucket, prefix string) (*S3Archiver, error) {
+  cfg, err := config.LoadDefaultConfig(context.Background())
+  if err != nil {
+    return nil, err
+  }
+  return newS3Archiver(s3.NewFromConfig(cfg), bucket, prefix), nil
+}
+
+func newS3Archiver(client s3Putter, bucket, prefix string) *S3Archiver {
+  return &S3Archiver{client: client, bucket: bucket, prefix: prefix, now: time.Now}
+}
+
+// Archive uploads the file at path and removes it once the upload
+// succeeds. On failure the file is left in place.
+func (this *S3Archiver) Archive(path string) error {
+  file, err := os.Open(path)
+  if err != nil {
+    return err
+  }
+  key := this.prefix + filepath.Base(path) + "." + this.now().UTC().Format("20060102T150405.000000000Z")
+  _, err = this.client.PutObject(context.Background(), &s3.PutObjectInput{
+    Bucket: aws.String(this.bucket),
+    Key: aws.String(key),
+    Body: file,
+  })
+  file.Close()
+  if err != nil {
+    return writeFailed(err)
+  }
+  return os.Remove(path)
+}
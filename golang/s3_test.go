@@ -0,0 +1,77 @@
+//go:build s3
+
+package main
+
+import (
+  "context"
+  "errors"
+  "io"
+  "os"
+  "path/filepath"
+  "testing"
+  "time"
+
+  "github.com/aws/aws-sdk-go-v2/aws"
+  "github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// mockBucket records the objects an S3Archiver puts.
+type mockBucket struct {
+  bucket, key, body string
+  fail error
+}
+
+func (this *mockBucket) PutObject(ctx context.Context, in *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+  if this.fail != nil {
+    return nil, this.fail
+  }
+  body, err := io.ReadAll(in.Body)
+  if err != nil {
+    return nil, err
+  }
+  this.bucket, this.key, this.body = aws.ToString(in.Bucket), aws.ToString(in.Key), string(body)
+  return &s3.PutObjectOutput{}, nil
+}
+
+func newTestArchiver(client s3Putter) *S3Archiver {
+  archiver := newS3Archiver(client, "logs-bucket", "archive/")
+  archiver.now = func() time.Time { return time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC) }
+  return archiver
+}
+
+func TestS3ArchiverOnRotate(t *testing.T) {
+  name := filepath.Join(t.TempDir(), "app.log")
+  bucket := &mockBucket{}
+  l, err := NewRotatingLocalLogger(name, 10, 2)
+  if err != nil {
+    t.Fatal(err)
+  }
+  l.OnRotate = newTestArchiver(bucket).Archive
+  l.Log("aaaaaaaa")
+  l.Log("bbbbbbbb")
+  l.Close() // waits for the upload
+  if bucket.bucket != "logs-bucket" || bucket.key != "archive/app.log.1.20260102T150405.000000000Z" {
+    t.Errorf("uploaded to %s/%s", bucket.bucket, bucket.key)
+  }
+  if bucket.body != "aaaaaaaa\n" {
+    t.Errorf("uploaded %q, want the rotated file", bucket.body)
+  }
+  if _, err := os.Stat(name + ".1"); !errors.Is(err, os.ErrNotExist) {
+    t.Errorf("the rotated file was not removed after upload: %v", err)
+  }
+}
+
+func TestS3ArchiverFailureKeepsFile(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "app.log.1")
+  if err := os.WriteFile(path, []byte("kept\n"), 0644); err != nil {
+    t.Fatal(err)
+  }
+  errPut := errors.New("access denied")
+  err := newTestArchiver(&mockBucket{fail: errPut}).Archive(path)
+  if !errors.Is(err, errPut) || !errors.Is(err, ErrWriteFailed) {
+    t.Errorf("Archive = %v, want a write failure wrapping %v", err, errPut)
+  }
+  if _, err := os.Stat(path); err != nil {
+    t.Errorf("the file was removed after a failed upload: %v", err)
+  }
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "sync/atomic"
+)
+
+// SamplingLogger forwards one message in every N and drops the rest, to
+// bound the volume of a hot path while keeping a representative sample.
+type SamplingLogger struct {
+  inner LoggerInterface
+  every uint64
+  seen atomic.Uint64
+  dropped atomic.Uint64
+}
+
+var _ LoggerInterface = (*SamplingLogger)(nil)
+
+// NewSamplingLogger forwards the 1st, (every+1)th, (2*every+1)th message and
+// so on. An every of 0 or 1 forwards everything.
+func NewSamplingLogger(inner LoggerInterface, every int) *SamplingLogger {
+  if every < 1 {
+    every = 1
+  }
+  return &SamplingLogger{inner: inner, every: uint64(every)}
+}
+
+func (this *SamplingLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *SamplingLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *SamplingLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *SamplingLogger) logEntry(e entry) error {
+  if (this.seen.Add(1) - 1) % this.every != 0 {
+    this.dropped.Add(1)
+    return nil
+  }
+  return logEntry(this.inner, e)
+}
+
+// Dropped returns how many messages have been left out of the sample.
+func (this *SamplingLogger) Dropped() uint64 {
+  return this.dropped.Load()
+}
+
+func (this *SamplingLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *SamplingLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *SamplingLogger) Messages() ([]string, error) {
+  return this.inner.Messages()
+}
+
+func (this *SamplingLogger) Count() (int, error) {
+  return this.inner.Count()
+}
+
+func (this *SamplingLogger) Tail(n int) ([]string, error) {
+  return this.inner.Tail(n)
+}
+
+func (this *SamplingLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.inner.Search(pattern, useRegexp)
+}
+
+func (this *SamplingLogger) ForEach(fn func(line string) error) error {
+  return this.inner.ForEach(fn)
+}
+
+func (this *SamplingLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.inner.MessagesPage(offset, limit)
+}
+
+func (this *SamplingLogger) Clear() error {
+  return this.inner.Clear()
+}
+
+func (this *SamplingLogger) Sync() error {
+  return this.inner.Sync()
+}
+
+func (this *SamplingLogger) Close() error {
+  return this.inner.Close()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+  "reflect"
+  "sync"
+  "testing"
+)
+
+func TestSamplingKeepsOneInTen(t *testing.T) {
+  inner := NewInMemoryLogger()
+  l := NewSamplingLogger(inner, 10)
+  for i := 0; i < 100; i++ {
+    l.Logf(LevelInfo, "message %d", i)
+  }
+  AssertLoggedCount(t, inner, 10)
+  if got, _ := inner.Tail(2); !reflect.DeepEqual(got, []string{"message 80", "message 90"}) {
+    t.Errorf("last two kept = %q, want messages 80 and 90", got)
+  }
+  if n := l.Dropped(); n != 90 {
+    t.Errorf("Dropped = %d, want 90", n)
+  }
+}
+
+func TestSamplingEveryOneOrLess(t *testing.T) {
+  for _, every := range([]int{0, 1}) {
+    inner := NewInMemoryLogger()
+    l := NewSamplingLogger(inner, every)
+    for i := 0; i < 20; i++ {
+      l.Log("x")
+    }
+    AssertLoggedCount(t, inner, 20)
+  }
+}
+
+func TestSamplingConcurrent(t *testing.T) {
+  inner := NewInMemoryLogger()
+  l := NewSamplingLogger(inner, 10)
+  var wg sync.WaitGroup
+  for g := 0; g < 10; g++ {
+    wg.Add(1)
+    go func() {
+      defer wg.Done()
+      for i := 0; i < 100; i++ {
+        l.Log("x")
+      }
+    }()
+  }
+  wg.Wait()
+  AssertLoggedCount(t, inner, 100)
+}
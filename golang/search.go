@@ -0,0 +1,76 @@
+package main
+
+import (
+  "regexp"
+  "strings"
+  "time"
+)
+
+// newMatcher returns a predicate for Search: a regexp match if useRegexp is
+// set, otherwise a plain substring test, which never fails.
+func newMatcher(pattern string, useRegexp bool) (func(string) bool, error) {
+  if !useRegexp {
+    return func(line string) bool {
+      return strings.Contains(line, pattern)
+    }, nil
+  }
+  re, err := regexp.Compile(pattern)
+  if err != nil {
+    return nil, err
+  }
+  return re.MatchString, nil
+}
+
+// SearchLevel returns li's messages at min or above, judging each stored
+// line's level with DetectLevel.
+func SearchLevel(li LoggerInterface, min Level) ([]string, error) {
+  var found []string
+  err := li.ForEach(func(line string) error {
+    if DetectLevel(line) >= min {
+      found = append(found, line)
+    }
+    return nil
+  })
+  return found, err
+}
+
+// MessagesBetween returns li's messages whose RFC 3339 timestamp prefix,
+// as WithTimestamp("") writes it, is from from up to but not including
+// to. Messages without one are left out. It reads through ForEach, so a
+// LocalLogger's file is streamed rather than loaded whole.
+func MessagesBetween(li LoggerInterface, from, to time.Time) ([]string, error) {
+  var found []string
+  err := li.ForEach(func(line string) error {
+    t, _, _ := parseLine(line)
+    if !t.IsZero() && !t.Before(from) && t.Before(to) {
+      found = append(found, line)
+    }
+    return nil
+  })
+  return found, err
+}
+
+// MessagesBetween is the function MessagesBetween for this logger.
+func (this *InMemoryLogger) MessagesBetween(from, to time.Time) ([]string, error) {
+  return MessagesBetween(this, from, to)
+}
+
+// MessagesBetween is the function MessagesBetween for this logger.
+func (this *LocalLogger) MessagesBetween(from, to time.Time) ([]string, error) {
+  return MessagesBetween(this, from, to)
+}
+
+// searchIn returns the messages matching pattern, in order.
+func searchIn(messages []string, pattern string, useRegexp bool) ([]string, error) {
+  match, err := newMatcher(pattern, useRegexp)
+  if err != nil {
+    return nil, err
+  }
+  var found []string
+  for _, mesg := range(messages) {
+    if match(mesg) {
+      found = append(found, mesg)
+    }
+  }
+  return found, nil
+}
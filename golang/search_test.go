@@ -0,0 +1,71 @@
+package main
+
+import (
+  "os"
+  "path/filepath"
+  "reflect"
+  "testing"
+  "time"
+)
+
+func TestSearch(t *testing.T) {
+  for name, l := range(bothLoggers(t)) {
+    l.Log("GET /index.html 200")
+    l.Log("POST /login 401")
+    l.Log("GET /favicon.ico 404")
+    got, err := l.Search("GET", false)
+    if want := []string{"GET /index.html 200", "GET /favicon.ico 404"}; err != nil || !reflect.DeepEqual(got, want) {
+      t.Errorf("%s: substring Search = %q, %v; want %q", name, got, err, want)
+    }
+    got, err = l.Search(`4\d\d$`, true)
+    if want := []string{"POST /login 401", "GET /favicon.ico 404"}; err != nil || !reflect.DeepEqual(got, want) {
+      t.Errorf("%s: regexp Search = %q, %v; want %q", name, got, err, want)
+    }
+    if _, err := l.Search("(", true); err == nil {
+      t.Errorf("%s: Search with a bad regexp succeeded", name)
+    }
+    if _, err := l.Search("(", false); err != nil {
+      t.Errorf("%s: substring Search for %q: %v", name, "(", err)
+    }
+  }
+}
+
+func TestSearchLevel(t *testing.T) {
+  l := NewInMemoryLogger()
+  for _, line := range([]string{"DEBUG: x", "INFO: y", "WARN: z", "ERROR: w", "no level"}) {
+    l.Log(line)
+  }
+  got, err := SearchLevel(l, LevelWarn)
+  if want := []string{"WARN: z", "ERROR: w"}; err != nil || !reflect.DeepEqual(got, want) {
+    t.Errorf("SearchLevel = %q, %v; want %q", got, err, want)
+  }
+}
+
+func TestMessagesBetween(t *testing.T) {
+  filename := filepath.Join(t.TempDir(), "week.log")
+  crafted := "2024-01-01T00:00:00Z monday\n" +
+    "no timestamp here\n" +
+    "2024-01-02T12:00:00Z tuesday noon\n" +
+    "2024-01-03T00:00:00+01:00 tuesday night in Paris\n" +
+    "2024-13-45T99:99:99Z not a real time\n" +
+    "2024-01-03T00:00:00Z wednesday\n" +
+    "2024-01-02T06:00:00Z tuesday morning, out of order\n"
+  if err := os.WriteFile(filename, []byte(crafted), 0644); err != nil {
+    t.Fatal(err)
+  }
+  l, err := NewLocalLogger(filename, false)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+  to := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+  want := []string{
+    "2024-01-02T12:00:00Z tuesday noon",
+    "2024-01-03T00:00:00+01:00 tuesday night in Paris",
+    "2024-01-02T06:00:00Z tuesday morning, out of order",
+  }
+  if got, err := l.MessagesBetween(from, to); err != nil || !reflect.DeepEqual(got, want) {
+    t.Errorf("MessagesBetween = %q, %v; want %q", got, err, want)
+  }
+}
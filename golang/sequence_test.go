@@ -0,0 +1,67 @@
+package main
+
+import (
+  "reflect"
+  "strconv"
+  "strings"
+  "sync"
+  "testing"
+)
+
+func TestSequenceNumbers(t *testing.T) {
+  l := NewInMemoryLogger(WithSequenceNumbers(1))
+  l.Log("a")
+  l.Log("b")
+  want := []string{"0000001 a", "0000002 b"}
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+    t.Fatalf("Messages() = %q, want %q", got, want)
+  }
+}
+
+func TestSequenceWidth(t *testing.T) {
+  l := NewInMemoryLogger(WithSequenceNumbers(99), WithSequenceWidth(2))
+  l.Log("a")
+  l.Log("b")
+  want := []string{"99 a", "100 b"}
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+    t.Fatalf("Messages() = %q, want %q", got, want)
+  }
+}
+
+func TestSequenceNumbersConcurrent(t *testing.T) {
+  const start, goroutines, each = 42, 8, 250
+  for name, l := range(bothLoggers(t, WithSequenceNumbers(start))) {
+    var wg sync.WaitGroup
+    for g := 0; g < goroutines; g++ {
+      wg.Add(1)
+      go func() {
+        defer wg.Done()
+        for i := 0; i < each; i++ {
+          l.Log("mesg")
+        }
+      }()
+    }
+    wg.Wait()
+    messages, _ := l.Messages()
+    seen := make(map[uint64]bool)
+    for _, line := range(messages) {
+      field, _, _ := strings.Cut(line, " ")
+      n, err := strconv.ParseUint(field, 10, 64)
+      if err != nil {
+        t.Fatalf("%s: line %q has no sequence number", name, line)
+      }
+      if seen[n] {
+        t.Fatalf("%s: sequence number %d is repeated", name, n)
+      }
+      seen[n] = true
+    }
+    for n := uint64(start); n < start+goroutines*each; n++ {
+      if !seen[n] {
+        t.Fatalf("%s: sequence number %d is missing", name, n)
+      }
+    }
+    if len(seen) != goroutines*each {
+      t.Fatalf("%s: got %d numbers, want %d", name, len(seen), goroutines*each)
+    }
+  }
+}
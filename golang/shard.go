@@ -0,0 +1,134 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "hash/fnv"
+)
+
+// shardedLogger is the logger returned by ShardedLogger.
+type shardedLogger struct {
+  shards []LoggerInterface
+  key func(mesg string) string
+}
+
+var _ LoggerInterface = (*shardedLogger)(nil)
+
+// ShardedLogger returns a logger spreading messages over n loggers, made
+// by calling factory with 0 to n-1, such as a LocalLogger per shard: each
+// message goes to the one its key hashes to, so messages with the same
+// key, such as a tenant id, always share a shard. keyFn is given the
+// message as logged, before any formatting. Reads see every shard merged
+// as MergeSorted does, in timestamp order when the shards write
+// WithTimestamp(""), and otherwise shard by shard. Close closes every
+// shard. An n < 1 means 1.
+func ShardedLogger(n int, keyFn func(msg string) string, factory func(shard int) LoggerInterface) LoggerInterface {
+  if n < 1 {
+    n = 1
+  }
+  this := &shardedLogger{shards: make([]LoggerInterface, n), key: keyFn}
+  for i := range(this.shards) {
+    this.shards[i] = factory(i)
+  }
+  return this
+}
+
+// shard returns the logger for mesg's key.
+func (this *shardedLogger) shard(mesg string) LoggerInterface {
+  h := fnv.New32a()
+  h.Write([]byte(this.key(mesg)))
+  return this.shards[h.Sum32()%uint32(len(this.shards))]
+}
+
+func (this *shardedLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *shardedLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *shardedLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *shardedLogger) logEntry(e entry) error {
+  return logEntry(this.shard(e.mesg), e)
+}
+
+func (this *shardedLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *shardedLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *shardedLogger) Messages() ([]string, error) {
+  return MergeSorted(this.shards...)
+}
+
+func (this *shardedLogger) Count() (int, error) {
+  total := 0
+  for _, logger := range(this.shards) {
+    n, err := logger.Count()
+    if err != nil {
+      return 0, err
+    }
+    total += n
+  }
+  return total, nil
+}
+
+func (this *shardedLogger) Tail(n int) ([]string, error) {
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return tailOf(messages, n), nil
+}
+
+func (this *shardedLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return searchIn(messages, pattern, useRegexp)
+}
+
+func (this *shardedLogger) ForEach(fn func(line string) error) error {
+  messages, err := this.Messages()
+  if err != nil {
+    return err
+  }
+  return forEachIn(messages, fn)
+}
+
+func (this *shardedLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return pageFrom(this.ForEach, offset, limit)
+}
+
+func (this *shardedLogger) Clear() error {
+  var errs []error
+  for _, logger := range(this.shards) {
+    errs = append(errs, logger.Clear())
+  }
+  return errors.Join(errs...)
+}
+
+func (this *shardedLogger) Sync() error {
+  var errs []error
+  for _, logger := range(this.shards) {
+    errs = append(errs, logger.Sync())
+  }
+  return errors.Join(errs...)
+}
+
+func (this *shardedLogger) Close() error {
+  var errs []error
+  for _, logger := range(this.shards) {
+    errs = append(errs, logger.Close())
+  }
+  return errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+  "path/filepath"
+  "reflect"
+  "strings"
+  "testing"
+  "time"
+)
+
+// tenantOf keys a message on its first word.
+func tenantOf(mesg string) string {
+  tenant, _, _ := strings.Cut(mesg, " ")
+  return tenant
+}
+
+func TestShardedLoggerByTenant(t *testing.T) {
+  var shards []*InMemoryLogger
+  l := ShardedLogger(4, tenantOf, func(shard int) LoggerInterface {
+    shards = append(shards, NewInMemoryLogger())
+    return shards[shard]
+  })
+  sharded := l.(*shardedLogger)
+  if sharded.shard("acme") == sharded.shard("globex") {
+    t.Fatal("the two tenants hash to one shard; pick other names")
+  }
+  l.Log("acme signed up")
+  l.Log("globex signed up")
+  l.Log("acme paid")
+  acme := sharded.shard("acme").(*InMemoryLogger)
+  globex := sharded.shard("globex").(*InMemoryLogger)
+  if got, _ := acme.Messages(); !reflect.DeepEqual(got, []string{"acme signed up", "acme paid"}) {
+    t.Errorf("acme's shard holds %q", got)
+  }
+  if got, _ := globex.Messages(); !reflect.DeepEqual(got, []string{"globex signed up"}) {
+    t.Errorf("globex's shard holds %q", got)
+  }
+  for i, shard := range(shards) {
+    if shard != acme && shard != globex {
+      if n, _ := shard.Count(); n != 0 {
+        t.Errorf("shard %d holds %d messages, want none", i, n)
+      }
+    }
+  }
+  if n, _ := l.Count(); n != 3 {
+    t.Errorf("Count() = %d, want 3", n)
+  }
+}
+
+func TestShardedLoggerMergesByTime(t *testing.T) {
+  dir := t.TempDir()
+  clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+  l := ShardedLogger(2, tenantOf, func(shard int) LoggerInterface {
+    local, err := NewLocalLogger(filepath.Join(dir, fmt.Sprintf("shard-%d.log", shard)), true,
+      WithTimestamp(""), WithClock(clock))
+    if err != nil {
+      t.Fatal(err)
+    }
+    return local
+  })
+  defer l.Close()
+  if sharded := l.(*shardedLogger); sharded.shard("acme") == sharded.shard("globex") {
+    t.Fatal("the two tenants hash to one shard; pick other names")
+  }
+  var want []string
+  for _, mesg := range([]string{"acme 1", "globex 2", "acme 3", "globex 4"}) {
+    l.Log(mesg)
+    want = append(want, clock.Now().Format(time.RFC3339Nano)+" "+mesg)
+    clock.Advance(time.Second)
+  }
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages() = %q, want %q", got, want)
+  }
+}
+
+func TestShardedLoggerCloseClosesEvery(t *testing.T) {
+  dir := t.TempDir()
+  var shards []*LocalLogger
+  l := ShardedLogger(3, tenantOf, func(shard int) LoggerInterface {
+    local, err := NewLocalLogger(filepath.Join(dir, fmt.Sprintf("shard-%d.log", shard)), true)
+    if err != nil {
+      t.Fatal(err)
+    }
+    shards = append(shards, local)
+    return local
+  })
+  if err := l.Close(); err != nil {
+    t.Fatal(err)
+  }
+  for i, shard := range(shards) {
+    if err := shard.Log("x"); !errors.Is(err, ErrClosed) {
+      t.Errorf("shard %d after Close: Log = %v, want ErrClosed", i, err)
+    }
+  }
+}
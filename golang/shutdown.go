@@ -0,0 +1,47 @@
+package main
+
+import (
+  "context"
+  "errors"
+)
+
+// Flushable is implemented by the loggers that hold messages back, so that
+// they can be pushed through before the process exits.
+type Flushable interface {
+  Flush() error
+}
+
+var (
+  _ Flushable = (*AsyncLogger)(nil)
+  _ Flushable = (*LocalLogger)(nil)
+  _ Flushable = (*NetworkLogger)(nil)
+  _ Flushable = (*DedupLogger)(nil)
+)
+
+// ShutdownAll flushes and then closes each logger in turn; those that are
+// not Flushable are just closed. Once ctx is done it stops waiting and
+// leaves the remaining loggers as they are. The errors are joined.
+func ShutdownAll(ctx context.Context, loggers ...LoggerInterface) error {
+  var errs []error
+  for _, logger := range(loggers) {
+    done := make(chan error, 1)
+    go func() {
+      done <- shutdown(logger)
+    }()
+    select {
+    case err := <-done:
+      errs = append(errs, err)
+    case <-ctx.Done():
+      return errors.Join(append(errs, ctx.Err())...)
+    }
+  }
+  return errors.Join(errs...)
+}
+
+func shutdown(logger LoggerInterface) error {
+  var flushErr error
+  if f, ok := logger.(Flushable); ok {
+    flushErr = f.Flush()
+  }
+  return errors.Join(flushErr, logger.Close())
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+  "context"
+  "log/slog"
+)
+
+// slogHandler is the slog.Handler returned by SlogHandler. attrs holds
+// those added with WithAttrs, already qualified by the groups open at the
+// time; group is the prefix for keys added from now on.
+type slogHandler struct {
+  l LoggerInterface
+  attrs Fields
+  group string
+}
+
+var _ slog.Handler = (*slogHandler)(nil)
+
+// SlogHandler lets l serve as the backend of a log/slog Logger, as in
+// slog.New(SlogHandler(l)). The record's attributes become fields on the
+// message, so l's Formatter renders them along with its level and text;
+// attributes inside a group are named "group.key". Filtering by level is
+// left to l.
+func SlogHandler(l LoggerInterface) slog.Handler {
+  return &slogHandler{l: l}
+}
+
+func (this *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+  return true
+}
+
+func (this *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+  e := entry{ctx: ctx, level: fromSlogLevel(r.Level), mesg: r.Message}
+  e.callers[0] = r.PC
+  fields := make(Fields, len(this.attrs)+r.NumAttrs())
+  for k, v := range(this.attrs) {
+    fields[k] = v
+  }
+  r.Attrs(func(a slog.Attr) bool {
+    addSlogAttr(fields, this.group, a)
+    return true
+  })
+  if len(fields) > 0 {
+    e.fields = fields
+  }
+  return logEntry(this.l, e)
+}
+
+func (this *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+  if len(attrs) == 0 {
+    return this
+  }
+  fields := make(Fields, len(this.attrs)+len(attrs))
+  for k, v := range(this.attrs) {
+    fields[k] = v
+  }
+  for _, a := range(attrs) {
+    addSlogAttr(fields, this.group, a)
+  }
+  return &slogHandler{l: this.l, attrs: fields, group: this.group}
+}
+
+func (this *slogHandler) WithGroup(name string) slog.Handler {
+  if name == "" {
+    return this
+  }
+  return &slogHandler{l: this.l, attrs: this.attrs, group: this.group + name + "."}
+}
+
+// addSlogAttr stores a under prefix+key, flattening groups into dotted
+// keys and dropping empty attributes, as slog's own handlers do.
+func addSlogAttr(fields Fields, prefix string, a slog.Attr) {
+  v := a.Value.Resolve()
+  if v.Kind() == slog.KindGroup {
+    sub := v.Group()
+    if len(sub) == 0 {
+      return
+    }
+    if a.Key != "" {
+      prefix += a.Key + "."
+    }
+    for _, ga := range(sub) {
+      addSlogAttr(fields, prefix, ga)
+    }
+    return
+  }
+  if a.Key == "" {
+    return
+  }
+  fields[prefix+a.Key] = v.Any()
+}
+
+// fromSlogLevel maps a slog level onto the nearest Level at or below it.
+func fromSlogLevel(level slog.Level) Level {
+  switch {
+  case level < slog.LevelInfo:
+    return LevelDebug
+  case level < slog.LevelWarn:
+    return LevelInfo
+  case level < slog.LevelError:
+    return LevelWarn
+  default:
+    return LevelError
+  }
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+  "log/slog"
+  "reflect"
+  "testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+  l := NewInMemoryLogger()
+  logger := slog.New(SlogHandler(l))
+  logger.Info("user signed in", "user", "bob", "attempts", 3)
+  logger.With("req", "r1").WithGroup("db").Warn("slow query", "ms", 250, slog.Group("q", "table", "orders"))
+  want := []string{
+    "user signed in attempts=3 user=bob",
+    "slow query db.ms=250 db.q.table=orders req=r1",
+  }
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages = %q, want %q", got, want)
+  }
+}
+
+func TestSlogHandlerLevels(t *testing.T) {
+  l := NewInMemoryLogger()
+  logger := slog.New(SlogHandler(l))
+  logger.Debug("d")
+  logger.Info("i")
+  logger.Warn("w")
+  logger.Error("e")
+  want := map[Level]uint64{LevelDebug: 1, LevelInfo: 1, LevelWarn: 1, LevelError: 1}
+  if got := l.Stats().ByLevel; !reflect.DeepEqual(got, want) {
+    t.Errorf("Stats().ByLevel = %v, want one at each level", got)
+  }
+}
+
+func TestSlogHandlerFormatter(t *testing.T) {
+  l := NewInMemoryLogger(WithFormatter(PlainFormatter{ShowLevel: true}))
+  slog.New(SlogHandler(l)).Error("failed", "code", 7)
+  AssertLogged(t, l, "ERROR")
+  AssertLogged(t, l, "failed")
+  AssertLogged(t, l, "code=7")
+}
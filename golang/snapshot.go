@@ -0,0 +1,21 @@
+package main
+
+// Snapshot returns a copy of the messages as they stand, sharing nothing
+// with the logger, so that another goroutine may keep or change it while
+// others Log. Messages has always returned such a copy as well; Snapshot
+// just says so in its name.
+func (this *InMemoryLogger) Snapshot() []string {
+  messages, _ := this.Messages()
+  return messages
+}
+
+// Snapshot returns the records in the file as it stands, after flushing
+// any buffer, even for a logger WithMirror. Unlike an InMemoryLogger's,
+// it can fail, reading the file.
+func (this *LocalLogger) Snapshot() ([]string, error) {
+  if err := this.beginRead(); err != nil {
+    return nil, err
+  }
+  defer this.mu.RUnlock()
+  return this.readLines(this.filename)
+}
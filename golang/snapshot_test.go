@@ -0,0 +1,47 @@
+package main
+
+import (
+  "reflect"
+  "testing"
+)
+
+func TestReadsDoNotAlias(t *testing.T) {
+  l := NewInMemoryLogger()
+  l.Log("a")
+  l.Log("b")
+  l.Log("c")
+  want := []string{"a", "b", "c"}
+  page, _, _ := l.MessagesPage(0, 3)
+  tail, _ := l.Tail(3)
+  messages, _ := l.Messages()
+  reads := map[string][]string{
+    "Snapshot": l.Snapshot(),
+    "Messages": messages,
+    "Tail": tail,
+    "MessagesPage": page,
+    "SortedMessages": l.SortedMessages(nil),
+  }
+  for name, got := range(reads) {
+    got[0] = "mutated"
+    if now, _ := l.Messages(); !reflect.DeepEqual(now, want) {
+      t.Fatalf("changing what %s returned changed the logger: %q", name, now)
+    }
+  }
+}
+
+func TestSnapshotIsPointInTime(t *testing.T) {
+  for name, l := range(bothLoggers(t)) {
+    l.Log("before")
+    var snapshot []string
+    switch l := l.(type) {
+    case *InMemoryLogger:
+      snapshot = l.Snapshot()
+    case *LocalLogger:
+      snapshot, _ = l.Snapshot()
+    }
+    l.Log("after")
+    if !reflect.DeepEqual(snapshot, []string{"before"}) {
+      t.Errorf("%s: Snapshot = %q, want only what was logged before it", name, snapshot)
+    }
+  }
+}
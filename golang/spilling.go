@@ -0,0 +1,203 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "sync"
+)
+
+// SpillingLogger keeps the newest messages in memory and moves the older
+// ones to a LocalLogger: once it holds more than max, the oldest are
+// written to the file in one batch, leaving the newest max/2 in memory.
+// Reads return the file's messages followed by those in memory, in the
+// order they were logged. Messages are rendered by the file's options,
+// when they are logged, so they read back the same from either place.
+type SpillingLogger struct {
+  mu sync.Mutex
+  file *LocalLogger
+  max int
+  lines []string // rendered, not yet written to file
+}
+
+var _ LoggerInterface = (*SpillingLogger)(nil)
+
+// NewSpillingLogger holds up to max messages in memory before spilling to
+// file, at least one. Closing it closes file.
+func NewSpillingLogger(file *LocalLogger, max int) *SpillingLogger {
+  if max < 1 {
+    max = 1
+  }
+  return &SpillingLogger{file: file, max: max}
+}
+
+func (this *SpillingLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *SpillingLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *SpillingLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *SpillingLogger) logEntry(e entry) error {
+  admitted, err := this.file.admits(e.level)
+  if err != nil {
+    return err
+  }
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  if err := this.file.opts.validateUTF8(&e); err != nil {
+    return err
+  }
+  if !admitted {
+    return nil
+  }
+  entries, err := this.file.opts.splitLines(e)
+  if err != nil {
+    return err
+  }
+  lines := this.file.opts.renderEach(entries, this.file.opts.clock())
+  this.mu.Lock()
+  this.lines = append(this.lines, lines...)
+  if len(this.lines) > this.max {
+    err = this.spill(len(this.lines) - this.max/2)
+  }
+  this.mu.Unlock()
+  if err != nil {
+    return err
+  }
+  for _, line := range(lines) {
+    this.file.opts.notify(e.level, line)
+  }
+  return nil
+}
+
+// spill writes the oldest n lines in memory to the file. The caller must
+// hold mu. Lines the file refuses stay in memory.
+func (this *SpillingLogger) spill(n int) error {
+  if n == 0 {
+    return nil
+  }
+  if err := this.file.write(this.lines[:n]...); err != nil {
+    return this.file.opts.handle(writeFailed(err))
+  }
+  this.lines = append(this.lines[:0], this.lines[n:]...)
+  return nil
+}
+
+// messagesLocked returns the messages in memory as the file would give
+// them back. The caller must hold mu.
+func (this *SpillingLogger) messagesLocked() []string {
+  messages := make([]string, len(this.lines))
+  for i, line := range(this.lines) {
+    messages[i] = this.file.messageOf(line)
+  }
+  return messages
+}
+
+// Flush writes every message still in memory to the file.
+func (this *SpillingLogger) Flush() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return this.spill(len(this.lines))
+}
+
+// Buffered returns how many messages are held in memory.
+func (this *SpillingLogger) Buffered() int {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return len(this.lines)
+}
+
+func (this *SpillingLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *SpillingLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *SpillingLogger) Messages() ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  messages, err := this.file.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return append(messages, this.messagesLocked()...), nil
+}
+
+func (this *SpillingLogger) Count() (int, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  n, err := this.file.Count()
+  return n + len(this.lines), err
+}
+
+// Tail reads the file only for what memory does not hold.
+func (this *SpillingLogger) Tail(n int) ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  held := this.messagesLocked()
+  if n <= len(held) {
+    return tailOf(held, n), nil
+  }
+  older, err := this.file.Tail(n - len(held))
+  if err != nil {
+    return nil, err
+  }
+  return append(older, held...), nil
+}
+
+func (this *SpillingLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  found, err := this.file.Search(pattern, useRegexp)
+  if err != nil {
+    return nil, err
+  }
+  more, err := searchIn(this.messagesLocked(), pattern, useRegexp)
+  return append(found, more...), err
+}
+
+// ForEach holds off logging until it is done, so fn must not log here.
+func (this *SpillingLogger) ForEach(fn func(line string) error) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if err := this.file.ForEach(fn); err != nil {
+    return err
+  }
+  return forEachIn(this.messagesLocked(), fn)
+}
+
+func (this *SpillingLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return pageFrom(this.ForEach, offset, limit)
+}
+
+// Clear empties both the file and memory.
+func (this *SpillingLogger) Clear() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.lines = nil
+  return this.file.Clear()
+}
+
+// Sync writes the messages in memory to the file and commits it to stable
+// storage.
+func (this *SpillingLogger) Sync() error {
+  if err := this.Flush(); err != nil {
+    return err
+  }
+  return this.file.Sync()
+}
+
+// Close writes the messages in memory to the file and closes it, even if
+// the write fails, returning both errors.
+func (this *SpillingLogger) Close() error {
+  return errors.Join(this.Flush(), this.file.Close())
+}
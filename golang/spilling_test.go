@@ -0,0 +1,62 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+  "path/filepath"
+  "reflect"
+  "testing"
+)
+
+func TestSpillingOrder(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "spill.log")
+  file, err := NewLocalLogger(path, true)
+  if err != nil {
+    t.Fatal(err)
+  }
+  l := NewSpillingLogger(file, 10)
+  var want []string
+  for i := 0; i < 25; i++ {
+    mesg := fmt.Sprintf("m%d", i)
+    want = append(want, mesg)
+    l.Log(mesg)
+  }
+  if n := l.Buffered(); n == 0 || n > 10 {
+    t.Errorf("%d messages in memory, want 1 to 10", n)
+  }
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages = %q", got)
+  }
+  if got, _ := l.Tail(3); !reflect.DeepEqual(got, want[22:]) {
+    t.Errorf("Tail(3) = %q", got)
+  }
+  if err := l.Close(); err != nil {
+    t.Fatal(err)
+  }
+  reopened, err := NewLocalLogger(path, false)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer reopened.Close()
+  if got, _ := reopened.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("file after Close holds %q", got)
+  }
+}
+
+// TestSpillingCloseAfterFailedFlush checks that Close still closes the
+// file when writing the memory out fails.
+func TestSpillingCloseAfterFailedFlush(t *testing.T) {
+  file, err := NewLocalLogger("spill.log", true, WithFS(NewMemFS()))
+  if err != nil {
+    t.Fatal(err)
+  }
+  l := NewSpillingLogger(file, 10)
+  l.Log("held")
+  file.file.Close() // the write to the file underneath fails
+  if err := l.Close(); !errors.Is(err, ErrWriteFailed) {
+    t.Errorf("Close returned %v, want the failed write", err)
+  }
+  if err := file.Log("late"); !errors.Is(err, ErrClosed) {
+    t.Errorf("the file was left open: Log returned %v", err)
+  }
+}
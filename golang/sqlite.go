@@ -0,0 +1,228 @@
+package main
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "fmt"
+  "sync"
+  "sync/atomic"
+  "time"
+)
+
+// sqliteDriver is the database/sql driver NewSQLiteLogger opens. Building
+// with -tags sqlite links one in; otherwise the program must register it.
+const sqliteDriver = "sqlite3"
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS logs (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  ts TEXT NOT NULL,
+  level TEXT NOT NULL,
+  text TEXT NOT NULL
+)`
+
+// SQLiteLogger saves the log messages as rows of a "logs" table, which it
+// creates if need be. Values are always bound as query parameters, so no
+// message can break the SQL.
+type SQLiteLogger struct {
+  db *sql.DB
+  insert *sql.Stmt
+  opts options
+  mu sync.RWMutex // guards minLevel
+  minLevel Level
+  closed atomic.Bool
+}
+
+var _ LoggerInterface = (*SQLiteLogger)(nil)
+
+func NewSQLiteLogger(dsn string, opts ...Option) (*SQLiteLogger, error) {
+  db, err := sql.Open(sqliteDriver, dsn)
+  if err != nil {
+    return nil, err
+  }
+  if _, err := db.Exec(sqliteSchema); err != nil {
+    db.Close()
+    return nil, err
+  }
+  insert, err := db.Prepare("INSERT INTO logs (ts, level, text) VALUES (?, ?, ?)")
+  if err != nil {
+    db.Close()
+    return nil, err
+  }
+  return &SQLiteLogger{db: db, insert: insert, opts: newOptions(opts)}, nil
+}
+
+func (this *SQLiteLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *SQLiteLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *SQLiteLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *SQLiteLogger) logEntry(e entry) error {
+  if this.closed.Load() {
+    return ErrClosed
+  }
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  this.mu.RLock()
+  minLevel := this.minLevel
+  this.mu.RUnlock()
+  if e.level < minLevel {
+    return nil
+  }
+  if err := this.opts.validateUTF8(&e); err != nil {
+    return err
+  }
+  ctx := e.ctx
+  if ctx == nil {
+    ctx = context.Background()
+  }
+  ts := this.opts.clock().Format(time.RFC3339Nano)
+  line := this.opts.render(e)
+  if _, err := this.insert.ExecContext(ctx, ts, e.level.String(), line); err != nil {
+    return this.opts.handle(writeFailed(err))
+  }
+  this.opts.notify(e.level, line)
+  return nil
+}
+
+// Ping checks the database answers a trivial query within ctx.
+func (this *SQLiteLogger) Ping(ctx context.Context) error {
+  var one int
+  return this.db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
+// SetMinLevel drops subsequent messages below level.
+func (this *SQLiteLogger) SetMinLevel(level Level) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.minLevel = level
+}
+
+func (this *SQLiteLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *SQLiteLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *SQLiteLogger) query(query string, args ...interface{}) ([]string, error) {
+  rows, err := this.db.Query(query, args...)
+  if err != nil {
+    return nil, err
+  }
+  defer rows.Close()
+  var messages []string
+  for rows.Next() {
+    var text string
+    if err := rows.Scan(&text); err != nil {
+      return nil, err
+    }
+    messages = append(messages, text)
+  }
+  return messages, rows.Err()
+}
+
+func (this *SQLiteLogger) Messages() ([]string, error) {
+  return this.query("SELECT text FROM logs ORDER BY id")
+}
+
+func (this *SQLiteLogger) Count() (int, error) {
+  var count int
+  err := this.db.QueryRow("SELECT COUNT(*) FROM logs").Scan(&count)
+  return count, err
+}
+
+func (this *SQLiteLogger) Tail(n int) ([]string, error) {
+  if n <= 0 {
+    return nil, nil
+  }
+  return this.query("SELECT text FROM (SELECT id, text FROM logs ORDER BY id DESC LIMIT ?) ORDER BY id", n)
+}
+
+// Search lets SQLite find substrings; regular expressions are matched here,
+// since SQLite has no built-in REGEXP.
+func (this *SQLiteLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  if !useRegexp {
+    return this.query("SELECT text FROM logs WHERE instr(text, ?) > 0 ORDER BY id", pattern)
+  }
+  if _, err := newMatcher(pattern, useRegexp); err != nil {
+    return nil, err
+  }
+  messages, err := this.Messages()
+  if err != nil {
+    return nil, err
+  }
+  return searchIn(messages, pattern, useRegexp)
+}
+
+// ForEach streams the rows through fn.
+func (this *SQLiteLogger) ForEach(fn func(line string) error) error {
+  rows, err := this.db.Query("SELECT text FROM logs ORDER BY id")
+  if err != nil {
+    return err
+  }
+  defer rows.Close()
+  for rows.Next() {
+    var text string
+    if err := rows.Scan(&text); err != nil {
+      return err
+    }
+    if err := fn(text); err != nil {
+      return err
+    }
+  }
+  return rows.Err()
+}
+
+// MessagesPage lets the database skip the rows before offset.
+func (this *SQLiteLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  if err := checkPage(offset, limit); err != nil {
+    return nil, false, err
+  }
+  rows, err := this.db.Query("SELECT text FROM logs ORDER BY id LIMIT ? OFFSET ?", limit+1, offset)
+  if err != nil {
+    return nil, false, err
+  }
+  defer rows.Close()
+  var page []string
+  for rows.Next() {
+    var text string
+    if err := rows.Scan(&text); err != nil {
+      return nil, false, err
+    }
+    page = append(page, text)
+  }
+  if err := rows.Err(); err != nil {
+    return nil, false, err
+  }
+  if len(page) > limit {
+    return page[:limit], true, nil
+  }
+  return page, false, nil
+}
+
+func (this *SQLiteLogger) Clear() error {
+  _, err := this.db.Exec("DELETE FROM logs")
+  return err
+}
+
+// Sync is a no-op: every insert has been committed when Log returns.
+func (this *SQLiteLogger) Sync() error {
+  return nil
+}
+
+func (this *SQLiteLogger) Close() error {
+  if this.closed.Swap(true) {
+    return nil
+  }
+  return errors.Join(this.insert.Close(), this.db.Close())
+}
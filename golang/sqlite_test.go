@@ -0,0 +1,80 @@
+//go:build sqlite
+
+package main
+
+import (
+  "context"
+  "errors"
+  "path/filepath"
+  "reflect"
+  "sync"
+  "testing"
+)
+
+func TestSQLiteQuotes(t *testing.T) {
+  l, err := NewSQLiteLogger(filepath.Join(t.TempDir(), "logs.db"))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  messages := []string{
+    "it's here",
+    "'); DROP TABLE logs; --",
+    "O'Brien said ''hello''",
+  }
+  for _, mesg := range(messages) {
+    if err := l.Log(mesg); err != nil {
+      t.Fatalf("Log(%q): %v", mesg, err)
+    }
+  }
+  if got, err := l.Messages(); err != nil || !reflect.DeepEqual(got, messages) {
+    t.Errorf("Messages = %q, %v; want %q", got, err, messages)
+  }
+  if got, err := l.Search("O'Brien", false); err != nil || len(got) != 1 {
+    t.Errorf("Search(%q) = %q, %v; want one row", "O'Brien", got, err)
+  }
+}
+
+func TestSQLiteCanceled(t *testing.T) {
+  l, err := NewSQLiteLogger(filepath.Join(t.TempDir(), "logs.db"))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  ctx, cancel := context.WithCancel(context.Background())
+  cancel()
+  if err := l.LogCtx(ctx, "too late"); !errors.Is(err, context.Canceled) {
+    t.Errorf("LogCtx on a canceled context = %v, want %v", err, context.Canceled)
+  }
+  if n, _ := l.Count(); n != 0 {
+    t.Errorf("Count() = %d after a canceled LogCtx, want 0", n)
+  }
+}
+
+func TestSQLiteSetMinLevelConcurrent(t *testing.T) {
+  l, err := NewSQLiteLogger(filepath.Join(t.TempDir(), "logs.db"))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  var wg sync.WaitGroup
+  wg.Add(2)
+  go func() {
+    defer wg.Done()
+    for i := 0; i < 100; i++ {
+      l.SetMinLevel(Level(i % 2))
+    }
+  }()
+  go func() {
+    defer wg.Done()
+    for i := 0; i < 100; i++ {
+      l.Logf(LevelWarn, "warn %d", i)
+    }
+  }()
+  wg.Wait()
+  l.SetMinLevel(LevelError)
+  l.Logf(LevelWarn, "dropped")
+  if found, _ := l.Search("dropped", false); found != nil {
+    t.Errorf("a Warn below the min level was stored: %q", found)
+  }
+}
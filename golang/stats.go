@@ -0,0 +1,54 @@
+package main
+
+// Stats counts the messages a logger has recorded, in total and by level.
+type Stats struct {
+  Total uint64
+  ByLevel map[Level]uint64
+}
+
+// levelCounts is the running tally behind Stats. It is not synchronized:
+// it must be updated under the same lock as the messages it counts.
+type levelCounts struct {
+  total uint64
+  byLevel map[Level]uint64
+}
+
+func (this *levelCounts) add(level Level) {
+  if this.byLevel == nil {
+    this.byLevel = make(map[Level]uint64)
+  }
+  this.byLevel[level]++
+  this.total++
+}
+
+func (this *levelCounts) stats() Stats {
+  byLevel := make(map[Level]uint64, len(this.byLevel))
+  for level, n := range(this.byLevel) {
+    byLevel[level] = n
+  }
+  return Stats{Total: this.total, ByLevel: byLevel}
+}
+
+// OnLog calls fn with the level and stored line of every message a logger
+// records, after recording it, so that external metrics can be fed directly.
+func OnLog(fn func(Level, string)) Option {
+  return func(o *options) {
+    o.onLog = append(o.onLog, fn)
+  }
+}
+
+// WithHook is OnLog for side effects such as alerting: hooks run in the
+// order given, and one that panics is recovered, the panic reported to the
+// WithErrorHandler function or else on stderr, so that it cannot break
+// the logging path.
+func WithHook(fn func(level Level, msg string)) Option {
+  return OnLog(fn)
+}
+
+func (this *options) notify(level Level, line string) {
+  for _, fn := range(this.onLog) {
+    this.safeCall("hook", func() {
+      fn(level, line)
+    })
+  }
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+  "errors"
+  "reflect"
+  "testing"
+)
+
+func TestHooksRunInOrder(t *testing.T) {
+  var calls []string
+  first := func(level Level, msg string) {
+    calls = append(calls, "first "+msg)
+  }
+  second := func(level Level, msg string) {
+    calls = append(calls, "second "+msg)
+  }
+  for name, l := range(bothLoggers(t, WithHook(first), WithHook(second))) {
+    calls = nil
+    l.Log("a")
+    l.Logf(LevelError, "b")
+    want := []string{"first a", "second a", "first b", "second b"}
+    if !reflect.DeepEqual(calls, want) {
+      t.Errorf("%s: hooks ran %q, want %q", name, calls, want)
+    }
+  }
+}
+
+func TestHookPanicIsRecovered(t *testing.T) {
+  var reported error
+  count := 0
+  l := NewInMemoryLogger(
+    WithErrorHandler(func(err error) {
+      reported = err
+    }),
+    WithHook(func(level Level, msg string) {
+      panic("hook broke")
+    }),
+    WithHook(func(level Level, msg string) {
+      count++
+    }),
+  )
+  if err := l.Log("survives"); err != nil {
+    t.Fatalf("Log = %v", err)
+  }
+  AssertLogged(t, l, "survives")
+  if count != 1 {
+    t.Errorf("the hook after the panicking one ran %d times, want 1", count)
+  }
+  if !errors.Is(reported, ErrPanicked) {
+    t.Errorf("reported %v, want %v", reported, ErrPanicked)
+  }
+}
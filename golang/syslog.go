@@ -0,0 +1,130 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "log/syslog"
+  "sync/atomic"
+)
+
+var errSyslogWriteOnly = errors.New("log: SyslogLogger is write-only")
+
+// SyslogLogger sends the log messages to the system logger. It keeps no
+// history, so reads fail with an error saying as much.
+type SyslogLogger struct {
+  w *syslog.Writer
+  opts options
+  closed atomic.Bool
+}
+
+var _ LoggerInterface = (*SyslogLogger)(nil)
+
+// NewSyslogLogger connects to the local syslog daemon. The facility of
+// priority is used for every message; its severity is taken from the
+// message's Level.
+func NewSyslogLogger(priority syslog.Priority, tag string, opts ...Option) (*SyslogLogger, error) {
+  w, err := syslog.New(priority, tag)
+  if err != nil {
+    return nil, err
+  }
+  return &SyslogLogger{w: w, opts: newOptions(opts)}, nil
+}
+
+func (this *SyslogLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *SyslogLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *SyslogLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *SyslogLogger) logEntry(e entry) error {
+  if this.closed.Load() {
+    return ErrClosed
+  }
+  if err := e.canceled(); err != nil {
+    return err
+  }
+  if err := this.opts.validateUTF8(&e); err != nil {
+    return err
+  }
+  line := this.opts.render(e)
+  var err error
+  switch {
+  case e.level >= LevelError:
+    err = this.w.Err(line)
+  case e.level == LevelWarn:
+    err = this.w.Warning(line)
+  case e.level == LevelInfo:
+    err = this.w.Info(line)
+  default:
+    err = this.w.Debug(line)
+  }
+  if err != nil {
+    return this.opts.handle(writeFailed(err))
+  }
+  this.opts.notify(e.level, line)
+  return nil
+}
+
+func (this *SyslogLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *SyslogLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *SyslogLogger) Messages() ([]string, error) {
+  return nil, errSyslogWriteOnly
+}
+
+func (this *SyslogLogger) Count() (int, error) {
+  return 0, errSyslogWriteOnly
+}
+
+func (this *SyslogLogger) Tail(n int) ([]string, error) {
+  return nil, errSyslogWriteOnly
+}
+
+func (this *SyslogLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return nil, errSyslogWriteOnly
+}
+
+func (this *SyslogLogger) ForEach(fn func(line string) error) error {
+  return errSyslogWriteOnly
+}
+
+func (this *SyslogLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return nil, false, errSyslogWriteOnly
+}
+
+func (this *SyslogLogger) Clear() error {
+  return errSyslogWriteOnly
+}
+
+// Truncated returns how many messages WithMaxMessageLength has cut short.
+func (this *SyslogLogger) Truncated() uint64 {
+  return this.opts.truncatedCount()
+}
+
+// Sync is a no-op: syslog has no notion of flushing.
+func (this *SyslogLogger) Sync() error {
+  return nil
+}
+
+// Close closes the connection; later Logs fail with ErrClosed rather than
+// open another.
+func (this *SyslogLogger) Close() error {
+  if this.closed.Swap(true) {
+    return nil
+  }
+  return this.w.Close()
+}
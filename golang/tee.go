@@ -0,0 +1,96 @@
+package main
+
+import (
+  "context"
+  "fmt"
+)
+
+// teeLogger is the logger returned by Tee.
+type teeLogger struct {
+  primary LoggerInterface
+  mirror LoggerInterface
+}
+
+var _ LoggerInterface = (*teeLogger)(nil)
+
+// Tee returns a logger writing to primary and, as a best effort, to mirror.
+// Reads see only primary. A failure of the mirror never fails a call: it
+// is swallowed, after a line describing it is logged to primary. This
+// suits a lossy mirror, such as a console, next to a durable primary.
+func Tee(primary, mirror LoggerInterface) LoggerInterface {
+  return &teeLogger{primary: primary, mirror: mirror}
+}
+
+func (this *teeLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *teeLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *teeLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *teeLogger) logEntry(e entry) error {
+  if err := logEntry(this.primary, e); err != nil {
+    return err
+  }
+  this.mirrorFailed("write", logEntry(this.mirror, e))
+  return nil
+}
+
+// mirrorFailed reports a mirror error to the primary, ignoring the outcome.
+func (this *teeLogger) mirrorFailed(op string, err error) {
+  if err != nil {
+    this.primary.Logf(LevelWarn, "tee: mirror %s failed: %v", op, err)
+  }
+}
+
+func (this *teeLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *teeLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *teeLogger) Messages() ([]string, error) {
+  return this.primary.Messages()
+}
+
+func (this *teeLogger) Count() (int, error) {
+  return this.primary.Count()
+}
+
+func (this *teeLogger) Tail(n int) ([]string, error) {
+  return this.primary.Tail(n)
+}
+
+func (this *teeLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.primary.Search(pattern, useRegexp)
+}
+
+func (this *teeLogger) ForEach(fn func(line string) error) error {
+  return this.primary.ForEach(fn)
+}
+
+func (this *teeLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.primary.MessagesPage(offset, limit)
+}
+
+func (this *teeLogger) Clear() error {
+  this.mirrorFailed("clear", this.mirror.Clear())
+  return this.primary.Clear()
+}
+
+func (this *teeLogger) Sync() error {
+  this.mirrorFailed("sync", this.mirror.Sync())
+  return this.primary.Sync()
+}
+
+func (this *teeLogger) Close() error {
+  this.mirrorFailed("close", this.mirror.Close())
+  return this.primary.Close()
+}
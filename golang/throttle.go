@@ -0,0 +1,141 @@
+package main
+
+import (
+  "container/list"
+  "context"
+  "fmt"
+  "sync"
+  "time"
+)
+
+// throttleMaxKeys is how many distinct messages a ThrottleLogger remembers.
+const throttleMaxKeys = 4096
+
+// ThrottleLogger logs each distinct message at most once per interval,
+// dropping its repeats until the interval has passed since it was last
+// logged. It remembers the throttleMaxKeys messages logged most recently,
+// so one forgotten since is logged again straight away.
+type ThrottleLogger struct {
+  mu sync.Mutex
+  inner LoggerInterface
+  opts options
+  interval time.Duration
+  seen map[string]*list.Element
+  order *list.List // of *throttleKey, most recently logged first
+  suppressed uint64
+}
+
+// throttleKey is what ThrottleLogger remembers of a message.
+type throttleKey struct {
+  mesg string
+  last time.Time
+}
+
+var _ LoggerInterface = (*ThrottleLogger)(nil)
+
+// ThrottleByKey keys on the message text, before any fields are added.
+// The interval is measured on the clock given WithClock, if any.
+func ThrottleByKey(inner LoggerInterface, interval time.Duration, opts ...Option) *ThrottleLogger {
+  return &ThrottleLogger{
+    inner: inner,
+    opts: newOptions(opts),
+    interval: interval,
+    seen: make(map[string]*list.Element),
+    order: list.New(),
+  }
+}
+
+func (this *ThrottleLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *ThrottleLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *ThrottleLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *ThrottleLogger) logEntry(e entry) error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  now := this.opts.clock()
+  elem, ok := this.seen[e.mesg]
+  if ok && now.Sub(elem.Value.(*throttleKey).last) < this.interval {
+    this.suppressed++
+    return nil
+  }
+  if err := logEntry(this.inner, e); err != nil {
+    return err
+  }
+  if ok {
+    elem.Value.(*throttleKey).last = now
+    this.order.MoveToFront(elem)
+    return nil
+  }
+  this.seen[e.mesg] = this.order.PushFront(&throttleKey{mesg: e.mesg, last: now})
+  if this.order.Len() > throttleMaxKeys {
+    oldest := this.order.Back()
+    this.order.Remove(oldest)
+    delete(this.seen, oldest.Value.(*throttleKey).mesg)
+  }
+  return nil
+}
+
+// Suppressed returns how many repeats have been dropped in total.
+func (this *ThrottleLogger) Suppressed() uint64 {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  return this.suppressed
+}
+
+func (this *ThrottleLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *ThrottleLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *ThrottleLogger) Messages() ([]string, error) {
+  return this.inner.Messages()
+}
+
+func (this *ThrottleLogger) Count() (int, error) {
+  return this.inner.Count()
+}
+
+func (this *ThrottleLogger) Tail(n int) ([]string, error) {
+  return this.inner.Tail(n)
+}
+
+func (this *ThrottleLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.inner.Search(pattern, useRegexp)
+}
+
+func (this *ThrottleLogger) ForEach(fn func(line string) error) error {
+  return this.inner.ForEach(fn)
+}
+
+func (this *ThrottleLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.inner.MessagesPage(offset, limit)
+}
+
+// Clear forgets when each message was last logged along with the wrapped
+// logger's messages.
+func (this *ThrottleLogger) Clear() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.seen = make(map[string]*list.Element)
+  this.order.Init()
+  return this.inner.Clear()
+}
+
+func (this *ThrottleLogger) Sync() error {
+  return this.inner.Sync()
+}
+
+func (this *ThrottleLogger) Close() error {
+  return this.inner.Close()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+  "fmt"
+  "reflect"
+  "testing"
+  "time"
+)
+
+func TestThrottleByKeyInterval(t *testing.T) {
+  clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+  inner := NewInMemoryLogger()
+  l := ThrottleByKey(inner, time.Minute, WithClock(clock))
+  l.Log("disk full")
+  l.Log("disk full")
+  l.Log("other")
+  clock.Advance(59 * time.Second)
+  l.Log("disk full")
+  clock.Advance(time.Second)
+  l.Log("disk full")
+  l.Log("disk full")
+  want := []string{"disk full", "other", "disk full"}
+  if got, _ := inner.Messages(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Messages() = %q, want %q", got, want)
+  }
+  if got := l.Suppressed(); got != 3 {
+    t.Errorf("Suppressed() = %d, want 3", got)
+  }
+}
+
+func TestThrottleByKeyBounded(t *testing.T) {
+  clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+  l := ThrottleByKey(NewInMemoryLogger(), time.Hour, WithClock(clock))
+  l.Log("first")
+  for i := 0; i < throttleMaxKeys; i++ {
+    l.Log(fmt.Sprintf("key %d", i))
+  }
+  if len(l.seen) != throttleMaxKeys || l.order.Len() != throttleMaxKeys {
+    t.Fatalf("remembers %d keys, want at most %d", len(l.seen), throttleMaxKeys)
+  }
+  l.Log("key 0") // recently used, so still throttled
+  l.Log("first") // the least recently used, forgotten
+  if got := l.Suppressed(); got != 1 {
+    t.Errorf("Suppressed() = %d, want only the remembered repeat", got)
+  }
+}
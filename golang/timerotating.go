@@ -0,0 +1,113 @@
+package main
+
+import (
+  "errors"
+  "io/fs"
+  "strings"
+  "time"
+)
+
+const dayLayout = "2006-01-02"
+
+// TimeRotatingLocalLogger is a LocalLogger writing to one file per day,
+// named base-YYYY-MM-DD.log. The day is checked on every Log, so the first
+// message after midnight opens the new file even if the logger sat idle
+// across the boundary.
+type TimeRotatingLocalLogger struct {
+  *LocalLogger
+  base string
+  day string
+}
+
+var _ LoggerInterface = (*TimeRotatingLocalLogger)(nil)
+
+// NewTimeRotatingLocalLogger opens today's file for base. A ".log" suffix
+// on base is dropped before the date is added.
+func NewTimeRotatingLocalLogger(base string, opts ...Option) (*TimeRotatingLocalLogger, error) {
+  this := &TimeRotatingLocalLogger{base: strings.TrimSuffix(base, ".log")}
+  o := newOptions(opts)
+  this.day = o.clock().Format(dayLayout)
+  local, err := NewLocalLogger(this.dayName(this.day), false, opts...)
+  if err != nil {
+    return nil, err
+  }
+  this.LocalLogger = local
+  local.rotation = this
+  return this, nil
+}
+
+func (this *TimeRotatingLocalLogger) dayName(day string) string {
+  return this.base + "-" + day + ".log"
+}
+
+func (this *TimeRotatingLocalLogger) today() string {
+  return this.opts.clock().Format(dayLayout)
+}
+
+func (this *TimeRotatingLocalLogger) beforeWrite(n int) error {
+  today := this.today()
+  if today == this.day {
+    return nil
+  }
+  if err := this.closeLocked(); err != nil {
+    return err
+  }
+  this.day = today
+  this.filename = this.dayName(today)
+  this.mirror = nil
+  return this.openLocked(false)
+}
+
+func (this *TimeRotatingLocalLogger) afterWrite(n int) error {
+  return nil
+}
+
+// Messages returns today's messages, which are none if nothing has been
+// logged yet today.
+func (this *TimeRotatingLocalLogger) Messages() ([]string, error) {
+  if err := this.beginRead(); err != nil {
+    return nil, err
+  }
+  defer this.mu.RUnlock()
+  today := this.today()
+  if today == this.day {
+    return this.messagesLocked()
+  }
+  lines, err := this.readLines(this.dayName(today))
+  if errors.Is(err, fs.ErrNotExist) {
+    return nil, nil
+  }
+  return lines, err
+}
+
+// MessagesRange concatenates the files of the days from the one holding
+// from to the one holding to, both included, skipping missing days.
+func (this *TimeRotatingLocalLogger) MessagesRange(from, to time.Time) ([]string, error) {
+  if err := this.beginRead(); err != nil {
+    return nil, err
+  }
+  defer this.mu.RUnlock()
+  var messages []string
+  last := to.Format(dayLayout)
+  for day := from; day.Format(dayLayout) <= last; day = day.AddDate(0, 0, 1) {
+    lines, err := this.readLines(this.dayName(day.Format(dayLayout)))
+    if errors.Is(err, fs.ErrNotExist) {
+      continue
+    }
+    if err != nil {
+      return nil, err
+    }
+    messages = append(messages, lines...)
+  }
+  return messages, nil
+}
+
+// WithFields returns a child that reads through Messages of this logger.
+func (this *TimeRotatingLocalLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+// WithPrefix returns a child that reads through Messages of this logger.
+func (this *TimeRotatingLocalLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
@@ -0,0 +1,23 @@
+package main
+
+// WithTransform runs fn over each message when it is logged, to reshape
+// it, such as to add a trace id. Transforms given in several options run
+// in the order given, and all of them before any WithRedactor, so that a
+// redactor sees what a transform added; WithMaxMessageLength applies
+// last. Only the message text is passed through them. One that panics is
+// skipped, the message going on as it was.
+func WithTransform(fn func(string) string) Option {
+  return func(o *options) {
+    o.transforms = append(o.transforms, fn)
+  }
+}
+
+// transform applies the WithTransform functions to mesg, in order.
+func (this *options) transform(mesg string) string {
+  for _, fn := range(this.transforms) {
+    this.safeCall("transform", func() {
+      mesg = fn(mesg)
+    })
+  }
+  return mesg
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+  "strings"
+  "testing"
+)
+
+func TestTransformsChainInOrder(t *testing.T) {
+  upper := WithTransform(strings.ToUpper)
+  trace := WithTransform(func(s string) string {
+    return s + " trace=ab12"
+  })
+  for _, l := range(bothLoggers(t, upper, trace)) {
+    l.Log("request done")
+    AssertLogged(t, l, "REQUEST DONE trace=ab12")
+    AssertNotLogged(t, l, "TRACE=AB12")
+  }
+}
+
+func TestTransformsRunBeforeRedactors(t *testing.T) {
+  redact, _ := NewRegexpRedactor(`user=\w+`, "user=[redacted]")
+  l := NewInMemoryLogger(WithRedactor(redact), WithTransform(func(s string) string {
+    return s + " user=alice"
+  }))
+  l.Log("login")
+  AssertLogged(t, l, "login user=[redacted]")
+  AssertNotLogged(t, l, "alice")
+}
+
+func TestTransformPanicIsSkipped(t *testing.T) {
+  l := NewInMemoryLogger(WithErrorHandler(func(error) {}),
+    WithTransform(func(s string) string { panic("broken") }),
+    WithTransform(strings.ToUpper))
+  l.Log("kept")
+  AssertLogged(t, l, "KEPT")
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+  "sync/atomic"
+  "unicode/utf8"
+)
+
+// truncatedMarker ends a message that WithMaxMessageLength cut short.
+const truncatedMarker = "…(truncated)"
+
+// WithMaxMessageLength cuts any message longer than n bytes down to at
+// most n, at a rune boundary, and ends it with "…(truncated)", before it
+// is formatted and stored. The limit is on the message alone: the caller
+// and sequence number that WithCaller and WithSequenceNumbers put before
+// it, fields and timestamps come on top. An n <= 0 means no limit.
+func WithMaxMessageLength(n int) Option {
+  return func(o *options) {
+    o.maxMessageLength = n
+    if o.truncated == nil {
+      o.truncated = new(atomic.Uint64)
+    }
+  }
+}
+
+// truncate applies the WithMaxMessageLength limit to mesg.
+func (this *options) truncate(mesg string) string {
+  n := this.maxMessageLength
+  if n <= 0 || len(mesg) <= n {
+    return mesg
+  }
+  for n > 0 && !utf8.RuneStart(mesg[n]) {
+    n--
+  }
+  this.truncated.Add(1)
+  return mesg[:n] + truncatedMarker
+}
+
+// truncatedCount is how many messages truncate has cut short.
+func (this *options) truncatedCount() uint64 {
+  if this.truncated == nil {
+    return 0
+  }
+  return this.truncated.Load()
+}
+
+// Truncated returns how many messages WithMaxMessageLength has cut short.
+func (this *InMemoryLogger) Truncated() uint64 {
+  return this.opts.truncatedCount()
+}
+
+// Truncated returns how many messages WithMaxMessageLength has cut short.
+func (this *LocalLogger) Truncated() uint64 {
+  return this.opts.truncatedCount()
+}
+
+// Truncated returns how many messages WithMaxMessageLength has cut short.
+func (this *ConsoleLogger) Truncated() uint64 {
+  return this.opts.truncatedCount()
+}
+
+// Truncated returns how many messages WithMaxMessageLength has cut short.
+func (this *NetworkLogger) Truncated() uint64 {
+  return this.opts.truncatedCount()
+}
+
+// Truncated returns how many messages WithMaxMessageLength has cut short.
+func (this *SQLiteLogger) Truncated() uint64 {
+  return this.opts.truncatedCount()
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+  "strings"
+  "testing"
+  "unicode/utf8"
+)
+
+func TestMaxMessageLengthMultibyte(t *testing.T) {
+  const limit = 100
+  for name, l := range(bothLoggers(t, WithMaxMessageLength(limit))) {
+    long := strings.Repeat("日本語", 1000) // 3 bytes a rune, so no rune ends at byte 100
+    l.Log(long)
+    l.Log("short")
+    messages, _ := l.Messages()
+    if len(messages) != 2 {
+      t.Fatalf("%s: Messages = %q", name, messages)
+    }
+    got := messages[0]
+    body, ok := strings.CutSuffix(got, truncatedMarker)
+    if !ok || !utf8.ValidString(got) || len(body) > limit || !strings.HasPrefix(long, body) {
+      t.Errorf("%s: stored %q (%d bytes), want at most %d valid bytes of the message and the marker", name, got, len(got), limit)
+    }
+    if len(body) != 99 {
+      t.Errorf("%s: kept %d bytes, want the 33 whole runes that fit", name, len(body))
+    }
+    if messages[1] != "short" {
+      t.Errorf("%s: a short message became %q", name, messages[1])
+    }
+    if n := l.(interface{ Truncated() uint64 }).Truncated(); n != 1 {
+      t.Errorf("%s: Truncated = %d, want 1", name, n)
+    }
+  }
+}
+
+func TestMaxMessageLengthExcludesPrefixes(t *testing.T) {
+  for name, l := range(bothLoggers(t, WithMaxMessageLength(10), WithSequenceNumbers(1))) {
+    l.Log(strings.Repeat("x", 50))
+    messages, _ := l.Messages()
+    if want := "0000001 xxxxxxxxxx" + truncatedMarker; len(messages) != 1 || messages[0] != want {
+      t.Errorf("%s: Messages = %q, want [%q]", name, messages, want)
+    }
+  }
+}
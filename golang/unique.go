@@ -0,0 +1,143 @@
+package main
+
+import (
+  "container/list"
+  "context"
+  "fmt"
+  "hash/fnv"
+  "sync"
+)
+
+// UniqueLogger logs each distinct message only the first time it is seen,
+// counting the repeats. It remembers the most recently seen maxKeys
+// messages, so one forgotten since is logged again.
+type UniqueLogger struct {
+  mu sync.Mutex
+  inner LoggerInterface
+  maxKeys int
+  seen map[uint64]*list.Element // by FNV-1a hash of the message
+  order *list.List // of *uniqueKey, most recently seen first
+}
+
+// uniqueKey is what UniqueLogger remembers of a message.
+type uniqueKey struct {
+  hash uint64
+  mesg string
+  count int
+}
+
+var _ LoggerInterface = (*UniqueLogger)(nil)
+
+// NewUniqueLogger remembers up to maxKeys messages. A maxKeys <= 0 means
+// unbounded.
+func NewUniqueLogger(inner LoggerInterface, maxKeys int) *UniqueLogger {
+  return &UniqueLogger{
+    inner: inner,
+    maxKeys: maxKeys,
+    seen: make(map[uint64]*list.Element),
+    order: list.New(),
+  }
+}
+
+func (this *UniqueLogger) Log(mesg string) error {
+  return this.logEntry(newEntry(nil, LevelInfo, mesg))
+}
+
+func (this *UniqueLogger) Logf(level Level, format string, args ...interface{}) error {
+  return this.logEntry(newEntry(nil, level, fmt.Sprintf(format, args...)))
+}
+
+func (this *UniqueLogger) LogCtx(ctx context.Context, mesg string) error {
+  return this.logEntry(newEntry(ctx, LevelInfo, mesg))
+}
+
+func (this *UniqueLogger) logEntry(e entry) error {
+  h := fnv.New64a()
+  h.Write([]byte(e.mesg))
+  hash := h.Sum64()
+
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if elem, ok := this.seen[hash]; ok {
+    key := elem.Value.(*uniqueKey)
+    if key.mesg == e.mesg {
+      key.count++
+      this.order.MoveToFront(elem)
+      return nil
+    }
+    // A hash collision: the newer message takes the slot.
+    this.order.Remove(elem)
+    delete(this.seen, hash)
+  }
+  if err := logEntry(this.inner, e); err != nil {
+    return err
+  }
+  this.seen[hash] = this.order.PushFront(&uniqueKey{hash: hash, mesg: e.mesg, count: 1})
+  if this.maxKeys > 0 && this.order.Len() > this.maxKeys {
+    oldest := this.order.Back()
+    this.order.Remove(oldest)
+    delete(this.seen, oldest.Value.(*uniqueKey).hash)
+  }
+  return nil
+}
+
+// Occurrences returns how many times each remembered message was logged.
+func (this *UniqueLogger) Occurrences() map[string]int {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  counts := make(map[string]int, this.order.Len())
+  for elem := this.order.Front(); elem != nil; elem = elem.Next() {
+    key := elem.Value.(*uniqueKey)
+    counts[key.mesg] = key.count
+  }
+  return counts
+}
+
+func (this *UniqueLogger) WithFields(fields Fields) LoggerInterface {
+  return withFields(this, fields)
+}
+
+func (this *UniqueLogger) WithPrefix(prefix string) LoggerInterface {
+  return withPrefix(this, prefix)
+}
+
+func (this *UniqueLogger) Messages() ([]string, error) {
+  return this.inner.Messages()
+}
+
+func (this *UniqueLogger) Count() (int, error) {
+  return this.inner.Count()
+}
+
+func (this *UniqueLogger) Tail(n int) ([]string, error) {
+  return this.inner.Tail(n)
+}
+
+func (this *UniqueLogger) Search(pattern string, useRegexp bool) ([]string, error) {
+  return this.inner.Search(pattern, useRegexp)
+}
+
+func (this *UniqueLogger) ForEach(fn func(line string) error) error {
+  return this.inner.ForEach(fn)
+}
+
+func (this *UniqueLogger) MessagesPage(offset, limit int) ([]string, bool, error) {
+  return this.inner.MessagesPage(offset, limit)
+}
+
+// Clear forgets the messages seen along with the wrapped logger's messages.
+func (this *UniqueLogger) Clear() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.seen = make(map[uint64]*list.Element)
+  this.order.Init()
+  return this.inner.Clear()
+}
+
+func (this *UniqueLogger) Sync() error {
+  return this.inner.Sync()
+}
+
+func (this *UniqueLogger) Close() error {
+  return this.inner.Close()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+  "reflect"
+  "testing"
+)
+
+func TestUniqueLogsEachOnce(t *testing.T) {
+  l := NewUniqueLogger(NewInMemoryLogger(), 0)
+  for _, mesg := range([]string{"a", "b", "a", "c", "a", "b"}) {
+    l.Log(mesg)
+  }
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+    t.Errorf("Messages = %q, want each distinct message once", got)
+  }
+  want := map[string]int{"a": 3, "b": 2, "c": 1}
+  if got := l.Occurrences(); !reflect.DeepEqual(got, want) {
+    t.Errorf("Occurrences = %v, want %v", got, want)
+  }
+}
+
+func TestUniqueEvictsLeastRecentlySeen(t *testing.T) {
+  l := NewUniqueLogger(NewInMemoryLogger(), 2)
+  for _, mesg := range([]string{"a", "b", "a", "c", "a", "b"}) {
+    l.Log(mesg)
+  }
+  // "b" was forgotten when "c" arrived, so it is logged again.
+  if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"a", "b", "c", "b"}) {
+    t.Errorf("Messages = %q", got)
+  }
+  if got := l.Occurrences(); len(got) != 2 || got["a"] != 3 || got["b"] != 1 {
+    t.Errorf("Occurrences = %v, want a:3 and b:1", got)
+  }
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+  "strings"
+  "unicode/utf8"
+)
+
+// UTF8Mode is what a logger does with a message that is not valid UTF-8.
+type UTF8Mode int
+
+const (
+  UTF8Passthrough UTF8Mode = iota // store the bytes as they are
+  UTF8Reject // refuse the message, returning ErrInvalidUTF8
+  UTF8Replace // replace each run of invalid bytes with U+FFFD
+)
+
+// WithValidateUTF8 checks each message, and the string values of its
+// fields, for invalid UTF-8 before it is transformed, formatted or
+// stored, so that a stray byte cannot corrupt a JSON or CSV log. The
+// default is UTF8Passthrough, as the loggers have always done.
+func WithValidateUTF8(mode UTF8Mode) Option {
+  return func(o *options) {
+    o.utf8Mode = mode
+  }
+}
+
+// validateUTF8 applies the WithValidateUTF8 mode to e, failing under
+// UTF8Reject. Fields are copied before being changed, as the map may be
+// shared with a child logger.
+func (this *options) validateUTF8(e *entry) error {
+  if this.utf8Mode == UTF8Passthrough || validEntry(*e) {
+    return nil
+  }
+  if this.utf8Mode == UTF8Reject {
+    return this.handle(ErrInvalidUTF8)
+  }
+  e.mesg = strings.ToValidUTF8(e.mesg, string(utf8.RuneError))
+  if e.fields != nil {
+    fields := make(Fields, len(e.fields))
+    for k, v := range(e.fields) {
+      if s, ok := v.(string); ok {
+        v = strings.ToValidUTF8(s, string(utf8.RuneError))
+      }
+      fields[strings.ToValidUTF8(k, string(utf8.RuneError))] = v
+    }
+    e.fields = fields
+  }
+  return nil
+}
+
+// validEntry reports whether e's message, field names and string field
+// values are all valid UTF-8.
+func validEntry(e entry) bool {
+  if !utf8.ValidString(e.mesg) {
+    return false
+  }
+  for k, v := range(e.fields) {
+    if !utf8.ValidString(k) {
+      return false
+    }
+    if s, ok := v.(string); ok && !utf8.ValidString(s) {
+      return false
+    }
+  }
+  return true
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+  "encoding/json"
+  "errors"
+  "os"
+  "reflect"
+  "testing"
+)
+
+// badUTF8 has a lead byte followed by one that cannot continue it.
+const badUTF8 = "caf\xc3\x28"
+
+func TestValidateUTF8Modes(t *testing.T) {
+  tests := []struct {
+    mode UTF8Mode
+    err error
+    want []string
+  }{
+    {UTF8Passthrough, nil, []string{badUTF8}},
+    {UTF8Reject, ErrInvalidUTF8, nil},
+    {UTF8Replace, nil, []string{"caf�("}},
+  }
+  for _, test := range(tests) {
+    for name, l := range(bothLoggers(t, WithValidateUTF8(test.mode))) {
+      if err := l.Log(badUTF8); !errors.Is(err, test.err) {
+        t.Errorf("%s, mode %d: Log = %v, want %v", name, test.mode, err, test.err)
+      }
+      if got, _ := l.Messages(); !reflect.DeepEqual(got, test.want) {
+        t.Errorf("%s, mode %d: Messages() = %q, want %q", name, test.mode, got, test.want)
+      }
+    }
+  }
+}
+
+func TestValidateUTF8RejectHandled(t *testing.T) {
+  var handled error
+  l := NewInMemoryLogger(WithValidateUTF8(UTF8Reject), WithErrorHandler(func(err error) { handled = err }))
+  l.Log(badUTF8)
+  if !errors.Is(handled, ErrInvalidUTF8) {
+    t.Errorf("error handler got %v, want ErrInvalidUTF8", handled)
+  }
+  if err := l.Log("fine"); err != nil {
+    t.Errorf("Log of valid UTF-8 = %v", err)
+  }
+}
+
+func TestValidateUTF8ReplaceKeepsJSON(t *testing.T) {
+  fields := Fields{"user": badUTF8}
+  l := newTestLocal(t, WithJSONL(), WithValidateUTF8(UTF8Replace))
+  l.WithFields(fields).Log(badUTF8)
+  records, err := l.MessagesStructured()
+  if err != nil || len(records) != 1 || records[0].Text != "caf�(" {
+    t.Fatalf("MessagesStructured = %+v, %v", records, err)
+  }
+  if l.ParseErrors() != 0 {
+    t.Error("the line written does not decode as JSON")
+  }
+  if fields["user"] != badUTF8 {
+    t.Error("the caller's fields were changed")
+  }
+  data, _ := os.ReadFile(l.filename)
+  var decoded map[string]interface{}
+  if err := json.Unmarshal(data, &decoded); err != nil || decoded["user"] != "caf�(" {
+    t.Errorf("file holds %q, want valid JSON with the field replaced", data)
+  }
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+  "bufio"
+  "context"
+  "crypto/sha1"
+  "encoding/base64"
+  "encoding/binary"
+  "errors"
+  "fmt"
+  "io"
+  "net"
+  "net/http"
+  "strings"
+  "sync"
+  "time"
+)
+
+// WebSocketConfig tunes the handler WebSocketHandlerWith returns.
+type WebSocketConfig struct {
+  Tail int // lines of history sent on connecting; defaults to 100
+  Buffer int // lines held for a slow client before dropping; defaults to 256
+  WriteTimeout time.Duration // for each frame; defaults to 10 seconds
+}
+
+// websocketGUID is what RFC 6455 appends to the client's key.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes.
+const (
+  wsText = 0x1
+  wsClose = 0x8
+  wsPing = 0x9
+  wsPong = 0xA
+)
+
+// WebSocketHandler is WebSocketHandlerWith the default WebSocketConfig.
+func WebSocketHandler(l LoggerInterface) http.Handler {
+  return WebSocketHandlerWith(l, WebSocketConfig{})
+}
+
+// WebSocketHandlerWith upgrades each request to a WebSocket and streams
+// l's messages over it as text frames: the last cfg.Tail of them, then
+// each one logged after, until the client goes away or the request's
+// context ends. A LocalLogger, or a logger built on one, is followed as
+// Follow does, with nothing lost or repeated between the two; any other
+// logger is polled for growth in its Count, so one at its capacity bound
+// shows nothing new. A client that reads too slowly never holds up the
+// logger: up to cfg.Buffer lines wait for it, and beyond that they are
+// dropped, which it is told of with a "dropped N messages" line.
+func WebSocketHandlerWith(l LoggerInterface, cfg WebSocketConfig) http.Handler {
+  if cfg.Tail <= 0 {
+    cfg.Tail = 100
+  }
+  if cfg.Buffer <= 0 {
+    cfg.Buffer = 256
+  }
+  if cfg.WriteTimeout <= 0 {
+    cfg.WriteTimeout = 10 * time.Second
+  }
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    ctx, cancel := context.WithCancel(r.Context())
+    defer cancel()
+    tail, lines, err := followTail(ctx, l, cfg.Tail)
+    if err != nil {
+      http.Error(w, err.Error(), http.StatusInternalServerError)
+      return
+    }
+    conn, err := upgradeWebSocket(w, r)
+    if err != nil {
+      return // upgradeWebSocket has replied
+    }
+    defer conn.close()
+    go func() {
+      conn.readUntilClose()
+      cancel()
+    }()
+    conn.stream(ctx, tail, lines, cfg)
+  })
+}
+
+// tailFollower is implemented by loggers that can follow their file from
+// its end; see LocalLogger.followTail.
+type tailFollower interface {
+  followTail(ctx context.Context, n int) ([]string, <-chan string, error)
+}
+
+// followTail returns the last n messages of l and a channel of those
+// logged after, which is closed once ctx is done.
+func followTail(ctx context.Context, l LoggerInterface, n int) ([]string, <-chan string, error) {
+  if follower, ok := l.(tailFollower); ok {
+    return follower.followTail(ctx, n)
+  }
+  count, err := l.Count()
+  if err != nil {
+    return nil, nil, err
+  }
+  tail, err := l.Tail(n)
+  if err != nil {
+    return nil, nil, err
+  }
+  ch := make(chan string)
+  go func() {
+    defer close(ch)
+    ticker := time.NewTicker(followPollInterval)
+    defer ticker.Stop()
+    for {
+      select {
+      case <-ticker.C:
+      case <-ctx.Done():
+        return
+      }
+      now, err := l.Count()
+      if err != nil {
+        return
+      }
+      if now <= count {
+        count = now // cleared or at its bound: nothing to tell apart as new
+        continue
+      }
+      page, _, err := l.MessagesPage(count, now-count)
+      if err != nil {
+        return
+      }
+      count = now
+      for _, mesg := range(page) {
+        select {
+        case ch <- mesg:
+        case <-ctx.Done():
+          return
+        }
+      }
+    }
+  }()
+  return tail, ch, nil
+}
+
+
+// wsConn is the server end of a WebSocket connection.
+type wsConn struct {
+  mu sync.Mutex // serializes frames
+  conn net.Conn
+  rw *bufio.ReadWriter
+}
+
+// upgradeWebSocket completes the RFC 6455 opening handshake, replying with
+// an error status itself if the request is not one.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+  key := r.Header.Get("Sec-WebSocket-Key")
+  switch {
+  case r.Method != http.MethodGet:
+    w.Header().Set("Allow", "GET")
+    http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+    return nil, errors.New("log: not a GET")
+  case !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") || key == "":
+    http.Error(w, "expected a WebSocket upgrade", http.StatusBadRequest)
+    return nil, errors.New("log: not a WebSocket upgrade")
+  case r.Header.Get("Sec-WebSocket-Version") != "13":
+    w.Header().Set("Sec-WebSocket-Version", "13")
+    http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+    return nil, errors.New("log: unsupported WebSocket version")
+  }
+  hijacker, ok := w.(http.Hijacker)
+  if !ok {
+    http.Error(w, "connection cannot be upgraded", http.StatusInternalServerError)
+    return nil, errors.New("log: connection cannot be hijacked")
+  }
+  conn, rw, err := hijacker.Hijack()
+  if err != nil {
+    return nil, err
+  }
+  sum := sha1.Sum([]byte(key + websocketGUID))
+  fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+  if err := rw.Flush(); err != nil {
+    conn.Close()
+    return nil, err
+  }
+  return &wsConn{conn: conn, rw: rw}, nil
+}
+
+// headerHasToken reports whether the comma-separated header name lists
+// token, in any case.
+func headerHasToken(h http.Header, name, token string) bool {
+  for _, value := range(h.Values(name)) {
+    for _, t := range(strings.Split(value, ",")) {
+      if strings.EqualFold(strings.TrimSpace(t), token) {
+        return true
+      }
+    }
+  }
+  return false
+}
+
+// stream writes tail and then the lines from ch, buffering up to
+// cfg.Buffer of them so that ch is always drained, until ctx is done or a
+// write fails.
+func (this *wsConn) stream(ctx context.Context, tail []string, ch <-chan string, cfg WebSocketConfig) {
+  for _, line := range(tail) {
+    if this.writeFrame(wsText, []byte(line), cfg.WriteTimeout) != nil {
+      return
+    }
+  }
+  pending := make(chan string, cfg.Buffer)
+  var mu sync.Mutex
+  dropped := 0
+  go func() {
+    defer close(pending)
+    for line := range(ch) {
+      select {
+      case pending <- line:
+      default:
+        mu.Lock()
+        dropped++
+        mu.Unlock()
+      }
+    }
+  }()
+  for {
+    select {
+    case line, ok := <-pending:
+      if !ok {
+        return
+      }
+      mu.Lock()
+      n := dropped
+      dropped = 0
+      mu.Unlock()
+      if n > 0 {
+        if this.writeFrame(wsText, []byte(fmt.Sprintf("dropped %d messages", n)), cfg.WriteTimeout) != nil {
+          return
+        }
+      }
+      if this.writeFrame(wsText, []byte(line), cfg.WriteTimeout) != nil {
+        return
+      }
+    case <-ctx.Done():
+      return
+    }
+  }
+}
+
+// writeFrame sends one unmasked, unfragmented frame. Text that is not
+// valid UTF-8, which a client must reject, has the bad bytes replaced.
+func (this *wsConn) writeFrame(opcode byte, payload []byte, timeout time.Duration) error {
+  if opcode == wsText {
+    payload = []byte(strings.ToValidUTF8(string(payload), "\uFFFD"))
+  }
+  header := []byte{0x80 | opcode, 0}
+  switch n := len(payload); {
+  case n < 126:
+    header[1] = byte(n)
+  case n <= 0xFFFF:
+    header[1] = 126
+    header = binary.BigEndian.AppendUint16(header, uint16(n))
+  default:
+    header[1] = 127
+    header = binary.BigEndian.AppendUint64(header, uint64(n))
+  }
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if timeout > 0 {
+    this.conn.SetWriteDeadline(time.Now().Add(timeout))
+  }
+  this.rw.Write(header)
+  this.rw.Write(payload)
+  return this.rw.Flush()
+}
+
+// readUntilClose reads the client's frames, answering pings and a close,
+// and returns once the client has closed the connection or it fails.
+// What the client sends otherwise is ignored.
+func (this *wsConn) readUntilClose() {
+  for {
+    opcode, payload, err := this.readFrame()
+    if err != nil {
+      return
+    }
+    switch opcode {
+    case wsPing:
+      if this.writeFrame(wsPong, payload, time.Second) != nil {
+        return
+      }
+    case wsClose:
+      if len(payload) >= 2 {
+        payload = payload[:2] // echo the status code only
+      }
+      this.writeFrame(wsClose, payload, time.Second)
+      return
+    }
+  }
+}
+
+// readFrame reads one frame from the client, unmasking it. Control frames
+// longer than the 125 bytes RFC 6455 allows are refused, and so are data
+// frames over a megabyte, which this server has no use for.
+func (this *wsConn) readFrame() (byte, []byte, error) {
+  var head [2]byte
+  if _, err := io.ReadFull(this.rw, head[:]); err != nil {
+    return 0, nil, err
+  }
+  opcode := head[0] & 0x0F
+  masked := head[1]&0x80 != 0
+  n := uint64(head[1] & 0x7F)
+  switch n {
+  case 126:
+    var ext [2]byte
+    if _, err := io.ReadFull(this.rw, ext[:]); err != nil {
+      return 0, nil, err
+    }
+    n = uint64(binary.BigEndian.Uint16(ext[:]))
+  case 127:
+    var ext [8]byte
+    if _, err := io.ReadFull(this.rw, ext[:]); err != nil {
+      return 0, nil, err
+    }
+    n = binary.BigEndian.Uint64(ext[:])
+  }
+  if !masked || opcode >= wsClose && n > 125 || n > 1<<20 {
+    return 0, nil, errors.New("log: bad WebSocket frame")
+  }
+  var mask [4]byte
+  if _, err := io.ReadFull(this.rw, mask[:]); err != nil {
+    return 0, nil, err
+  }
+  payload := make([]byte, n)
+  if _, err := io.ReadFull(this.rw, payload); err != nil {
+    return 0, nil, err
+  }
+  for i := range(payload) {
+    payload[i] ^= mask[i%4]
+  }
+  return opcode, payload, nil
+}
+
+func (this *wsConn) close() error {
+  return this.conn.Close()
+}
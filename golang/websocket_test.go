@@ -0,0 +1,116 @@
+package main
+
+import (
+  "bufio"
+  "encoding/binary"
+  "fmt"
+  "io"
+  "net"
+  "net/http"
+  "net/http/httptest"
+  "path/filepath"
+  "strings"
+  "testing"
+  "time"
+)
+
+// wsClient is just enough of a WebSocket client to read text frames.
+type wsClient struct {
+  conn net.Conn
+  r *bufio.Reader
+}
+
+func dialWebSocket(t *testing.T, url string) *wsClient {
+  t.Helper()
+  conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+  if err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() {
+    conn.Close()
+  })
+  fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+    "Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+  r := bufio.NewReader(conn)
+  resp, err := http.ReadResponse(r, nil)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if resp.StatusCode != http.StatusSwitchingProtocols {
+    t.Fatalf("handshake status %d", resp.StatusCode)
+  }
+  if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+    t.Fatalf("Sec-WebSocket-Accept %q", got)
+  }
+  return &wsClient{conn: conn, r: r}
+}
+
+// next returns the payload of the next text frame, which a server sends
+// unmasked.
+func (this *wsClient) next(t *testing.T) string {
+  t.Helper()
+  this.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+  var head [2]byte
+  if _, err := io.ReadFull(this.r, head[:]); err != nil {
+    t.Fatal(err)
+  }
+  if op := head[0] & 0x0f; op != wsText {
+    t.Fatalf("opcode %#x, want text", op)
+  }
+  n := uint64(head[1] & 0x7f)
+  switch n {
+  case 126:
+    var ext [2]byte
+    io.ReadFull(this.r, ext[:])
+    n = uint64(binary.BigEndian.Uint16(ext[:]))
+  case 127:
+    var ext [8]byte
+    io.ReadFull(this.r, ext[:])
+    n = binary.BigEndian.Uint64(ext[:])
+  }
+  payload := make([]byte, n)
+  if _, err := io.ReadFull(this.r, payload); err != nil {
+    t.Fatal(err)
+  }
+  return string(payload)
+}
+
+// TestWebSocketJSONL checks that the backlog frames and the live frames
+// of a JSONL LocalLogger are both the bare messages.
+func TestWebSocketJSONL(t *testing.T) {
+  l, err := NewLocalLogger(filepath.Join(t.TempDir(), "ws.jsonl"), true, WithJSONL())
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer l.Close()
+  l.Log("old")
+  server := httptest.NewServer(WebSocketHandler(l))
+  defer server.Close()
+  client := dialWebSocket(t, server.URL)
+  if got := client.next(t); got != "old" {
+    t.Errorf("tail frame %q, want %q", got, "old")
+  }
+  l.Log("new")
+  if got := client.next(t); got != "new" {
+    t.Errorf("live frame %q, want %q", got, "new")
+  }
+}
+
+func TestWebSocketPollsInMemory(t *testing.T) {
+  l := NewInMemoryLogger()
+  for i := 0; i < 5; i++ {
+    l.Logf(LevelInfo, "old %d", i)
+  }
+  server := httptest.NewServer(WebSocketHandlerWith(l, WebSocketConfig{Tail: 2}))
+  defer server.Close()
+  client := dialWebSocket(t, server.URL)
+  for _, want := range([]string{"old 3", "old 4"}) {
+    if got := client.next(t); got != want {
+      t.Errorf("tail frame %q, want %q", got, want)
+    }
+  }
+  l.Log("new")
+  if got := client.next(t); got != "new" {
+    t.Errorf("live frame %q, want %q", got, "new")
+  }
+}
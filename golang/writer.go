@@ -0,0 +1,85 @@
+package main
+
+import (
+  "bytes"
+  "io"
+  "strings"
+  "sync"
+)
+
+// LogWriter adapts a LoggerInterface to io.Writer, logging one message per
+// line, so that code written against the log package can target our loggers:
+//
+//   log.SetOutput(AsWriter(logger))
+type LogWriter struct {
+  mu sync.Mutex
+  logger LoggerInterface
+  partial []byte
+}
+
+var (
+  _ io.WriteCloser = (*LogWriter)(nil)
+  _ io.StringWriter = (*LogWriter)(nil)
+)
+
+func AsWriter(li LoggerInterface) *LogWriter {
+  return &LogWriter{logger: li}
+}
+
+// Write logs every complete line in p. A trailing partial line is held
+// until a later Write completes it or Close flushes it.
+func (this *LogWriter) Write(p []byte) (int, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  this.partial = append(this.partial, p...)
+  for {
+    i := bytes.IndexByte(this.partial, '\n')
+    if i < 0 {
+      break
+    }
+    line := string(this.partial[:i])
+    this.partial = this.partial[i+1:]
+    if err := this.logger.Log(line); err != nil {
+      return len(p), err
+    }
+  }
+  return len(p), nil
+}
+
+// WriteString is Write for a string, logging complete lines straight from
+// s rather than copying them. A trailing partial line is held the same way,
+// until the next newline or Close.
+func (this *LogWriter) WriteString(s string) (int, error) {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  rest := s
+  for {
+    i := strings.IndexByte(rest, '\n')
+    if i < 0 {
+      break
+    }
+    line := rest[:i]
+    if len(this.partial) > 0 {
+      line = string(this.partial) + line
+      this.partial = this.partial[:0]
+    }
+    rest = rest[i+1:]
+    if err := this.logger.Log(line); err != nil {
+      return len(s), err
+    }
+  }
+  this.partial = append(this.partial, rest...)
+  return len(s), nil
+}
+
+// Close logs any pending partial line. It does not close the logger.
+func (this *LogWriter) Close() error {
+  this.mu.Lock()
+  defer this.mu.Unlock()
+  if len(this.partial) == 0 {
+    return nil
+  }
+  line := string(this.partial)
+  this.partial = nil
+  return this.logger.Log(line)
+}
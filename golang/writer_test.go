@@ -0,0 +1,64 @@
+package main
+
+import (
+  "io"
+  "reflect"
+  "testing"
+)
+
+func TestWriterSplitsLines(t *testing.T) {
+  for _, name := range([]string{"Write", "WriteString"}) {
+    l := NewInMemoryLogger()
+    w := AsWriter(l)
+    write := func(s string) {
+      if name == "Write" {
+        w.Write([]byte(s))
+      } else {
+        w.WriteString(s)
+      }
+    }
+    write("one\ntw")
+    write("o\nthr")
+    if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"one", "two"}) {
+      t.Errorf("%s: Messages = %q before Close, want the complete lines", name, got)
+    }
+    w.Close()
+    if got, _ := l.Messages(); !reflect.DeepEqual(got, []string{"one", "two", "thr"}) {
+      t.Errorf("%s: Messages = %q after Close, want the partial line too", name, got)
+    }
+  }
+}
+
+func TestIOWriteString(t *testing.T) {
+  l := NewInMemoryLogger()
+  io.WriteString(AsWriter(l), "via io\n")
+  AssertLogged(t, l, "via io")
+}
+
+// discardLogger is an InMemoryLogger that keeps nothing, so a benchmark
+// measures only the writer.
+type discardLogger struct {
+  *InMemoryLogger
+}
+
+func (this discardLogger) Log(mesg string) error {
+  return nil
+}
+
+const benchLine = "GET /index.html 200 1532 bytes in 3ms\n"
+
+func BenchmarkLogWriterWrite(b *testing.B) {
+  w := AsWriter(discardLogger{NewInMemoryLogger()})
+  b.ReportAllocs()
+  for i := 0; i < b.N; i++ {
+    w.Write([]byte(benchLine))
+  }
+}
+
+func BenchmarkLogWriterWriteString(b *testing.B) {
+  w := AsWriter(discardLogger{NewInMemoryLogger()})
+  b.ReportAllocs()
+  for i := 0; i < b.N; i++ {
+    w.WriteString(benchLine)
+  }
+}